@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -14,14 +15,51 @@ const (
 	configKeyLoggerLogLevel   = configKey("LoggerLogLevel")
 	configKeyLoggerEnableJson = configKey("LoggerEnableJson")
 
+	configKeyStorageBackend = configKey("StorageBackend")
+
+	configKeyDatabaseDriver     = configKey("DatabaseDriver")
+	configKeyDatabaseSQLitePath = configKey("DatabaseSQLitePath")
+
+	configKeyDatabaseURL      = configKey("DatabaseURL")
 	configKeyDatabaseUser     = configKey("DatabaseUser")
 	configKeyDatabasePassword = configKey("DatabasePassword")
 	configKeyDatabaseHost     = configKey("DatabaseHost")
 	configKeyDatabasePort     = configKey("DatabasePort")
 	configKeyDatabaseDatabase = configKey("DatabaseDatabase")
 
+	configKeyDatabaseSSLMode          = configKey("DatabaseSSLMode")
+	configKeyDatabaseSSLRootCert      = configKey("DatabaseSSLRootCert")
+	configKeyDatabaseApplicationName  = configKey("DatabaseApplicationName")
+	configKeyDatabaseSearchPath       = configKey("DatabaseSearchPath")
+	configKeyDatabaseStatementTimeout = configKey("DatabaseStatementTimeout")
+
+	configKeyDatabaseMigrateOnStartup = configKey("DatabaseMigrateOnStartup")
+
+	configKeyDatabaseMaxOpenConnections    = configKey("DatabaseMaxOpenConnections")
+	configKeyDatabaseMaxIdleConnections    = configKey("DatabaseMaxIdleConnections")
+	configKeyDatabaseConnectionMaxLifetime = configKey("DatabaseConnectionMaxLifetime")
+	configKeyDatabaseConnectionMaxIdleTime = configKey("DatabaseConnectionMaxIdleTime")
+
+	configKeyDatabaseConnectTimeout              = configKey("DatabaseConnectTimeout")
+	configKeyDatabaseConnectRetryInitialInterval = configKey("DatabaseConnectRetryInitialInterval")
+	configKeyDatabaseConnectRetryMaxInterval     = configKey("DatabaseConnectRetryMaxInterval")
+
+	configKeyDatabaseTxIsolationLevel = configKey("DatabaseTxIsolationLevel")
+	configKeyDatabaseTxMaxAttempts    = configKey("DatabaseTxMaxAttempts")
+
+	configKeyCacheCapacity    = configKey("CacheCapacity")
+	configKeyCacheTTL         = configKey("CacheTTL")
+	configKeyCacheLoadTimeout = configKey("CacheLoadTimeout")
+
+	configKeyTrashRetention     = configKey("TrashRetention")
+	configKeyTrashPurgeInterval = configKey("TrashPurgeInterval")
+
 	configKeyServerPort = configKey("ServerPort")
 	configKeyServerHost = configKey("ServerHost")
+
+	configKeyServerDrainDelay      = configKey("ServerDrainDelay")
+	configKeyServerShutdownTimeout = configKey("ServerShutdownTimeout")
+	configKeyHealthCheckTimeout    = configKey("HealthCheckTimeout")
 )
 
 type configKey string
@@ -34,14 +72,67 @@ type AppConfig struct {
 	LoggerLogLevel   logrus.Level
 	LoggerEnableJson bool
 
+	// StorageBackend is "database" or "memory", the database options only apply to the database.
+	StorageBackend string
+
+	// DatabaseDriver is "postgres" or "sqlite", the connection options other than DatabaseSQLitePath
+	// only apply to PostgreSQL.
+	DatabaseDriver string
+	// DatabaseSQLitePath is the path of the SQLite database file or ":memory:".
+	DatabaseSQLitePath string
+
+	// DatabaseURL overrides the other connection options if it is set.
+	DatabaseURL      string
 	DatabaseUser     string
 	DatabasePassword string
 	DatabaseHost     string
 	DatabasePort     string
 	DatabaseDatabase string
 
+	DatabaseSSLMode          string
+	DatabaseSSLRootCert      string
+	DatabaseApplicationName  string
+	DatabaseSearchPath       string
+	DatabaseStatementTimeout time.Duration
+
+	DatabaseMigrateOnStartup bool
+
+	DatabaseMaxOpenConnections    int
+	DatabaseMaxIdleConnections    int
+	DatabaseConnectionMaxLifetime time.Duration
+	DatabaseConnectionMaxIdleTime time.Duration
+
+	DatabaseConnectTimeout              time.Duration
+	DatabaseConnectRetryInitialInterval time.Duration
+	DatabaseConnectRetryMaxInterval     time.Duration
+
+	// DatabaseTxIsolationLevel is the isolation level of the transactions such as "Serializable".
+	DatabaseTxIsolationLevel string
+	// DatabaseTxMaxAttempts limits the number of times the transaction is run if it fails to serialize.
+	DatabaseTxMaxAttempts int
+
+	// CacheCapacity limits the number of the cached values of each kind, the cache is disabled if it is zero.
+	// The cache doesn't see the writes of the other instances, so it is disabled by default.
+	CacheCapacity int
+	// CacheTTL is the time after which the cached value is read again, the values never expire if it is zero.
+	CacheTTL time.Duration
+	// CacheLoadTimeout limits the database query loading the missing value, which is not canceled with the request.
+	CacheLoadTimeout time.Duration
+
+	// TrashRetention is the time the deleted books and authors can be restored before they are purged.
+	TrashRetention time.Duration
+	// TrashPurgeInterval is the time between the purges of the trash, the trash is never purged if it is zero.
+	TrashPurgeInterval time.Duration
+
 	ServerPort string
 	ServerHost string
+
+	// ServerDrainDelay is the time between the readiness probe failing and the server shutdown,
+	// the load balancer stops sending new requests in the meantime.
+	ServerDrainDelay time.Duration
+	// ServerShutdownTimeout limits the whole shutdown including the drain delay.
+	ServerShutdownTimeout time.Duration
+	HealthCheckTimeout    time.Duration
 }
 
 func NewAppConfig() AppConfig {
@@ -49,13 +140,50 @@ func NewAppConfig() AppConfig {
 		LoggerLogLevel:   logrus.Level(env.GetInt(configKeyLoggerLogLevel.String(), int(logrus.InfoLevel))),
 		LoggerEnableJson: env.GetBool(configKeyLoggerEnableJson.String(), false),
 
+		StorageBackend: env.GetString(configKeyStorageBackend.String(), "database"),
+
+		DatabaseDriver:     env.GetString(configKeyDatabaseDriver.String(), "postgres"),
+		DatabaseSQLitePath: env.GetString(configKeyDatabaseSQLitePath.String(), "books.db"),
+
+		DatabaseURL:      env.GetString(configKeyDatabaseURL.String(), ""),
 		DatabaseUser:     env.GetString(configKeyDatabaseUser.String(), "postgres"),
 		DatabasePassword: env.GetString(configKeyDatabasePassword.String(), "postgres"),
 		DatabaseHost:     env.GetString(configKeyDatabaseHost.String(), "localhost"),
 		DatabasePort:     env.GetString(configKeyDatabasePort.String(), "5432"),
 		DatabaseDatabase: env.GetString(configKeyDatabaseDatabase.String(), "postgres"),
 
+		DatabaseSSLMode:          env.GetString(configKeyDatabaseSSLMode.String(), ""),
+		DatabaseSSLRootCert:      env.GetString(configKeyDatabaseSSLRootCert.String(), ""),
+		DatabaseApplicationName:  env.GetString(configKeyDatabaseApplicationName.String(), "books"),
+		DatabaseSearchPath:       env.GetString(configKeyDatabaseSearchPath.String(), ""),
+		DatabaseStatementTimeout: env.GetDuration(configKeyDatabaseStatementTimeout.String(), 0),
+
+		DatabaseMigrateOnStartup: env.GetBool(configKeyDatabaseMigrateOnStartup.String(), false),
+
+		DatabaseMaxOpenConnections:    env.GetInt(configKeyDatabaseMaxOpenConnections.String(), 10),
+		DatabaseMaxIdleConnections:    env.GetInt(configKeyDatabaseMaxIdleConnections.String(), 5),
+		DatabaseConnectionMaxLifetime: env.GetDuration(configKeyDatabaseConnectionMaxLifetime.String(), 30*time.Minute),
+		DatabaseConnectionMaxIdleTime: env.GetDuration(configKeyDatabaseConnectionMaxIdleTime.String(), 5*time.Minute),
+
+		DatabaseConnectTimeout:              env.GetDuration(configKeyDatabaseConnectTimeout.String(), 30*time.Second),
+		DatabaseConnectRetryInitialInterval: env.GetDuration(configKeyDatabaseConnectRetryInitialInterval.String(), 500*time.Millisecond),
+		DatabaseConnectRetryMaxInterval:     env.GetDuration(configKeyDatabaseConnectRetryMaxInterval.String(), 5*time.Second),
+
+		DatabaseTxIsolationLevel: env.GetString(configKeyDatabaseTxIsolationLevel.String(), "Read Committed"),
+		DatabaseTxMaxAttempts:    env.GetInt(configKeyDatabaseTxMaxAttempts.String(), 3),
+
+		CacheCapacity:    env.GetInt(configKeyCacheCapacity.String(), 0),
+		CacheTTL:         env.GetDuration(configKeyCacheTTL.String(), time.Minute),
+		CacheLoadTimeout: env.GetDuration(configKeyCacheLoadTimeout.String(), 10*time.Second),
+
+		TrashRetention:     env.GetDuration(configKeyTrashRetention.String(), 30*24*time.Hour),
+		TrashPurgeInterval: env.GetDuration(configKeyTrashPurgeInterval.String(), time.Hour),
+
 		ServerPort: env.GetString(configKeyServerPort.String(), "8080"),
 		ServerHost: env.GetString(configKeyServerHost.String(), "localhost"),
+
+		ServerDrainDelay:      env.GetDuration(configKeyServerDrainDelay.String(), 0),
+		ServerShutdownTimeout: env.GetDuration(configKeyServerShutdownTimeout.String(), 30*time.Second),
+		HealthCheckTimeout:    env.GetDuration(configKeyHealthCheckTimeout.String(), 2*time.Second),
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
@@ -21,35 +22,125 @@ func TestAppConfig(t *testing.T) {
 func (self *AppConfigTests) TestNewAppConfig() {
 	loggerLogLevel := logrus.DebugLevel
 	loggerEnableJson := true
+	storageBackend := "memory"
+	databaseDriver := "sqlite"
+	databaseSQLitePath := "/tmp/test.db"
+	databaseURL := "postgresql://test_user@test_host/test_database"
 	databaseUser := "test_user"
 	databasePassword := "test_password"
 	databaseHost := "test_host"
 	databasePort := "test_port"
 	databaseDatabase := "test_database"
+	databaseSSLMode := "verify-full"
+	databaseSSLRootCert := "/test/root.crt"
+	databaseApplicationName := "test_application"
+	databaseSearchPath := "test_schema"
+	databaseStatementTimeout := 10 * time.Second
+	databaseMigrateOnStartup := true
+	databaseMaxOpenConnections := 20
+	databaseMaxIdleConnections := 15
+	databaseConnectionMaxLifetime := time.Hour
+	databaseConnectionMaxIdleTime := time.Minute
+	databaseConnectTimeout := time.Minute
+	databaseConnectRetryInitialInterval := time.Second
+	databaseConnectRetryMaxInterval := 10 * time.Second
+	databaseTxIsolationLevel := "Serializable"
+	databaseTxMaxAttempts := 5
+	cacheCapacity := 100
+	cacheTTL := 10 * time.Minute
+	cacheLoadTimeout := 5 * time.Second
+	trashRetention := 24 * time.Hour
+	trashPurgeInterval := 10 * time.Minute
 	serverPort := "test_port"
 	serverHost := "test_host"
+	serverDrainDelay := 5 * time.Second
+	serverShutdownTimeout := 20 * time.Second
+	healthCheckTimeout := time.Second
 	self.NoError(os.Setenv(configKeyLoggerLogLevel.String(), strconv.Itoa(int(loggerLogLevel))))
 	self.NoError(os.Setenv(configKeyLoggerEnableJson.String(), strconv.FormatBool(loggerEnableJson)))
+	self.NoError(os.Setenv(configKeyStorageBackend.String(), storageBackend))
+	self.NoError(os.Setenv(configKeyDatabaseDriver.String(), databaseDriver))
+	self.NoError(os.Setenv(configKeyDatabaseSQLitePath.String(), databaseSQLitePath))
+	self.NoError(os.Setenv(configKeyDatabaseURL.String(), databaseURL))
 	self.NoError(os.Setenv(configKeyDatabaseUser.String(), databaseUser))
 	self.NoError(os.Setenv(configKeyDatabasePassword.String(), databasePassword))
 	self.NoError(os.Setenv(configKeyDatabaseHost.String(), databaseHost))
 	self.NoError(os.Setenv(configKeyDatabasePort.String(), databasePort))
 	self.NoError(os.Setenv(configKeyDatabaseDatabase.String(), databaseDatabase))
+	self.NoError(os.Setenv(configKeyDatabaseSSLMode.String(), databaseSSLMode))
+	self.NoError(os.Setenv(configKeyDatabaseSSLRootCert.String(), databaseSSLRootCert))
+	self.NoError(os.Setenv(configKeyDatabaseApplicationName.String(), databaseApplicationName))
+	self.NoError(os.Setenv(configKeyDatabaseSearchPath.String(), databaseSearchPath))
+	self.NoError(os.Setenv(configKeyDatabaseStatementTimeout.String(), databaseStatementTimeout.String()))
+	self.NoError(os.Setenv(configKeyDatabaseMigrateOnStartup.String(), strconv.FormatBool(databaseMigrateOnStartup)))
+	self.NoError(os.Setenv(configKeyDatabaseMaxOpenConnections.String(), strconv.Itoa(databaseMaxOpenConnections)))
+	self.NoError(os.Setenv(configKeyDatabaseMaxIdleConnections.String(), strconv.Itoa(databaseMaxIdleConnections)))
+	self.NoError(os.Setenv(configKeyDatabaseConnectionMaxLifetime.String(), databaseConnectionMaxLifetime.String()))
+	self.NoError(os.Setenv(configKeyDatabaseConnectionMaxIdleTime.String(), databaseConnectionMaxIdleTime.String()))
+	self.NoError(os.Setenv(configKeyDatabaseConnectTimeout.String(), databaseConnectTimeout.String()))
+	self.NoError(os.Setenv(configKeyDatabaseConnectRetryInitialInterval.String(), databaseConnectRetryInitialInterval.String()))
+	self.NoError(os.Setenv(configKeyDatabaseConnectRetryMaxInterval.String(), databaseConnectRetryMaxInterval.String()))
+	self.NoError(os.Setenv(configKeyDatabaseTxIsolationLevel.String(), databaseTxIsolationLevel))
+	self.NoError(os.Setenv(configKeyDatabaseTxMaxAttempts.String(), strconv.Itoa(databaseTxMaxAttempts)))
+	self.NoError(os.Setenv(configKeyCacheCapacity.String(), strconv.Itoa(cacheCapacity)))
+	self.NoError(os.Setenv(configKeyCacheTTL.String(), cacheTTL.String()))
+	self.NoError(os.Setenv(configKeyCacheLoadTimeout.String(), cacheLoadTimeout.String()))
+	self.NoError(os.Setenv(configKeyTrashRetention.String(), trashRetention.String()))
+	self.NoError(os.Setenv(configKeyTrashPurgeInterval.String(), trashPurgeInterval.String()))
 	self.NoError(os.Setenv(configKeyServerPort.String(), serverPort))
 	self.NoError(os.Setenv(configKeyServerHost.String(), serverHost))
+	self.NoError(os.Setenv(configKeyServerDrainDelay.String(), serverDrainDelay.String()))
+	self.NoError(os.Setenv(configKeyServerShutdownTimeout.String(), serverShutdownTimeout.String()))
+	self.NoError(os.Setenv(configKeyHealthCheckTimeout.String(), healthCheckTimeout.String()))
 
 	result := NewAppConfig()
 
 	self.Equal(AppConfig{
-		LoggerLogLevel:   loggerLogLevel,
-		LoggerEnableJson: loggerEnableJson,
-		DatabaseUser:     databaseUser,
-		DatabasePassword: databasePassword,
-		DatabaseHost:     databaseHost,
-		DatabasePort:     databasePort,
-		DatabaseDatabase: databaseDatabase,
-		ServerPort:       serverPort,
-		ServerHost:       serverHost,
+		LoggerLogLevel:     loggerLogLevel,
+		LoggerEnableJson:   loggerEnableJson,
+		StorageBackend:     storageBackend,
+		DatabaseDriver:     databaseDriver,
+		DatabaseSQLitePath: databaseSQLitePath,
+		DatabaseURL:        databaseURL,
+		DatabaseUser:       databaseUser,
+		DatabasePassword:   databasePassword,
+		DatabaseHost:       databaseHost,
+		DatabasePort:       databasePort,
+		DatabaseDatabase:   databaseDatabase,
+
+		DatabaseSSLMode:          databaseSSLMode,
+		DatabaseSSLRootCert:      databaseSSLRootCert,
+		DatabaseApplicationName:  databaseApplicationName,
+		DatabaseSearchPath:       databaseSearchPath,
+		DatabaseStatementTimeout: databaseStatementTimeout,
+
+		DatabaseMigrateOnStartup: databaseMigrateOnStartup,
+
+		DatabaseMaxOpenConnections:    databaseMaxOpenConnections,
+		DatabaseMaxIdleConnections:    databaseMaxIdleConnections,
+		DatabaseConnectionMaxLifetime: databaseConnectionMaxLifetime,
+		DatabaseConnectionMaxIdleTime: databaseConnectionMaxIdleTime,
+
+		DatabaseConnectTimeout:              databaseConnectTimeout,
+		DatabaseConnectRetryInitialInterval: databaseConnectRetryInitialInterval,
+		DatabaseConnectRetryMaxInterval:     databaseConnectRetryMaxInterval,
+
+		DatabaseTxIsolationLevel: databaseTxIsolationLevel,
+		DatabaseTxMaxAttempts:    databaseTxMaxAttempts,
+
+		CacheCapacity:    cacheCapacity,
+		CacheTTL:         cacheTTL,
+		CacheLoadTimeout: cacheLoadTimeout,
+
+		TrashRetention:     trashRetention,
+		TrashPurgeInterval: trashPurgeInterval,
+
+		ServerPort: serverPort,
+		ServerHost: serverHost,
+
+		ServerDrainDelay:      serverDrainDelay,
+		ServerShutdownTimeout: serverShutdownTimeout,
+		HealthCheckTimeout:    healthCheckTimeout,
 	}, result)
 }
 
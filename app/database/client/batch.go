@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// maxQueryParameters is the maximum number of the parameters of the statement supported by PostgreSQL.
+const maxQueryParameters = 65535
+
+// Numbers of the parameters of the rows inserted by createBooksQuery.
+const (
+	bookParameters        = 7
+	contributorParameters = 4
+)
+
+// Query template to create the batch of books, the VALUES list is filled by the rows of the books.
+var createBooksQuery = `INSERT INTO books (id, title, isbn, publication_date, description, language, page_count) VALUES %s
+RETURNING id, created_at, updated_at, version`
+
+// Query template to create the batch of books by createBooksQuery with the VALUES list
+// of their contributors, the authors in the trash are not inserted and the inserted contributors are counted.
+var createBooksWithContributorsQuery = `WITH book AS (
+%s
+), contributors AS (
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT contributor.book_id, contributor.author_id, contributor.role, contributor.position
+FROM (VALUES %s) AS contributor(book_id, author_id, role, position)
+WHERE ` + contributorNotInTrashCondition + `
+RETURNING book_id
+)
+SELECT id, created_at, updated_at, version, (SELECT count(*) FROM contributors) AS contributors FROM book`
+
+type createdBookRow struct {
+	ID        uuid.UUID `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	Version   int64     `db:"version"`
+	// Contributors is the number of the contributors of the whole batch, it is zero without contributors.
+	Contributors int `db:"contributors"`
+}
+
+// CreateBooks creates the books in the single transaction and returns them with the timestamps and the versions
+// set by the database. The books are inserted by the multi-row statements split to fit the limit of the parameters.
+func (self *DatabaseClient) CreateBooks(ctx context.Context, books []models.Book) ([]models.Book, error) {
+	created := make([]models.Book, 0, len(books))
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		created = created[:0]
+		for _, chunk := range chunkBooks(books) {
+			chunkCreated, err := tx.createBooks(ctx, chunk)
+			if err != nil {
+				return err
+			}
+			created = append(created, chunkCreated...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (self *DatabaseClient) createBooks(ctx context.Context, books []models.Book) ([]models.Book, error) {
+	query, arguments := buildCreateBooksQuery(books)
+	var rows []createdBookRow
+	if err := selectNamed(ctx, self.db, &rows, query, arguments); err != nil {
+		return nil, err
+	}
+
+	timestamps := make(map[uuid.UUID]createdBookRow, len(rows))
+	for _, row := range rows {
+		timestamps[row.ID] = row
+	}
+	created := make([]models.Book, 0, len(books))
+	contributors := 0
+	for _, book := range books {
+		row, ok := timestamps[book.ID]
+		if !ok {
+			return nil, fmt.Errorf("book %s is not returned by the database", book.ID)
+		}
+		contributors += len(book.Contributors)
+		book.CreatedAt = row.CreatedAt
+		book.UpdatedAt = row.UpdatedAt
+		book.Version = row.Version
+		created = append(created, book)
+	}
+	// Every row has the number of the contributors of the whole batch.
+	if contributors > 0 && rows[0].Contributors < contributors {
+		return nil, errContributorInTrash
+	}
+
+	return created, nil
+}
+
+// buildCreateBooksQuery returns the query creating the books with the rows of the books and their named arguments.
+func buildCreateBooksQuery(books []models.Book) (string, map[string]any) {
+	arguments := map[string]any{}
+	bookRows := make([]string, 0, len(books))
+	var contributorRows []string
+	for bookIndex, book := range books {
+		suffix := fmt.Sprintf("_%d", bookIndex)
+		bookRows = append(bookRows, fmt.Sprintf(
+			"(:id%[1]s, :title%[1]s, NULLIF(:isbn%[1]s, ''), :publication_date%[1]s, :description%[1]s, :language%[1]s, :page_count%[1]s)",
+			suffix,
+		))
+		arguments["id"+suffix] = book.ID
+		arguments["title"+suffix] = book.Title
+		arguments["isbn"+suffix] = book.ISBN
+		arguments["publication_date"+suffix] = book.PublicationDate
+		arguments["description"+suffix] = book.Description
+		arguments["language"+suffix] = book.Language
+		arguments["page_count"+suffix] = book.PageCount
+
+		for contributorIndex, contributor := range book.Contributors {
+			contributorSuffix := fmt.Sprintf("%s_%d", suffix, contributorIndex)
+			contributorRows = append(contributorRows, fmt.Sprintf(
+				"(CAST(:id%s AS uuid), CAST(:author_id%[2]s AS uuid), CAST(:role%[2]s AS text), CAST(:position%[2]s AS integer))",
+				suffix, contributorSuffix,
+			))
+			arguments["author_id"+contributorSuffix] = contributor.Author.ID
+			arguments["role"+contributorSuffix] = contributor.Role
+			arguments["position"+contributorSuffix] = contributorIndex + 1
+		}
+	}
+
+	query := fmt.Sprintf(createBooksQuery, strings.Join(bookRows, ", "))
+	if len(contributorRows) > 0 {
+		query = fmt.Sprintf(createBooksWithContributorsQuery, query, strings.Join(contributorRows, ", "))
+	}
+
+	return query, arguments
+}
+
+// chunkBooks splits the books so that the parameters of every chunk fit maxQueryParameters.
+func chunkBooks(books []models.Book) [][]models.Book {
+	var chunks [][]models.Book
+	start, parameters := 0, 0
+	for index, book := range books {
+		count := bookParameters + contributorParameters*len(book.Contributors)
+		if parameters+count > maxQueryParameters && index > start {
+			chunks = append(chunks, books[start:index])
+			start, parameters = index, 0
+		}
+		parameters += count
+	}
+	if start < len(books) {
+		chunks = append(chunks, books[start:])
+	}
+
+	return chunks
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+var createBooksQueryMatcher = regexp.QuoteMeta(`INSERT INTO books (id, title, isbn, publication_date, description, language, page_count) VALUES `+
+	`(?, ?, NULLIF(?, ''), ?, ?, ?, ?), (?, ?, NULLIF(?, ''), ?, ?, ?, ?)`) + `(?s:.*)` +
+	regexp.QuoteMeta(`INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT contributor.book_id, contributor.author_id, contributor.role, contributor.position
+FROM (VALUES (CAST(? AS uuid), CAST(? AS uuid), CAST(? AS text), CAST(? AS integer)), `+
+		`(CAST(? AS uuid), CAST(? AS uuid), CAST(? AS text), CAST(? AS integer))) AS contributor(book_id, author_id, role, position)
+WHERE `+contributorNotInTrashCondition)
+
+func (self *DatabaseClientTests) otherBook() models.Book {
+	book := self.book
+	book.ID = uuid.New()
+	book.ISBN = ""
+	book.Title = "test_title_2"
+	return book
+}
+
+func (self *DatabaseClientTests) TestCreateBooks() {
+	otherBook := self.otherBook()
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createBooksQueryMatcher).
+		WithArgs(
+			self.book.ID, self.book.Title, self.book.ISBN, self.book.PublicationDate, self.book.Description,
+			self.book.Language, self.book.PageCount,
+			otherBook.ID, otherBook.Title, otherBook.ISBN, otherBook.PublicationDate, otherBook.Description,
+			otherBook.Language, otherBook.PageCount,
+			self.book.ID, self.author.ID, models.ContributorRoleAuthor, 1,
+			otherBook.ID, self.author.ID, models.ContributorRoleAuthor, 1,
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "version", "contributors"}).
+			AddRow(otherBook.ID, self.book.CreatedAt, self.book.UpdatedAt, models.FirstVersion, 2).
+			AddRow(self.book.ID, self.book.CreatedAt, self.book.UpdatedAt, models.FirstVersion, 2)).
+		RowsWillBeClosed()
+	self.sqlMock.ExpectCommit()
+	otherBook.CreatedAt = self.book.CreatedAt
+	otherBook.UpdatedAt = self.book.UpdatedAt
+
+	result, err := self.client.CreateBooks(self.context, []models.Book{self.book, otherBook})
+
+	self.NoError(err)
+	self.Equal([]models.Book{self.book, otherBook}, result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestCreateBooksRollsBackIfConflict() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createBooksQueryMatcher).
+		WillReturnError(pgx.PgError{Code: uniqueViolationCode, Message: "test_message"})
+	self.sqlMock.ExpectRollback()
+
+	result, err := self.client.CreateBooks(self.context, []models.Book{self.book, self.otherBook()})
+
+	self.ErrorIs(err, models.ErrConflict)
+	self.Nil(result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestCreateBooksErrorIfBookNotReturned() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createBooksQueryMatcher).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "version"}).
+			AddRow(self.book.ID, self.book.CreatedAt, self.book.UpdatedAt, models.FirstVersion))
+	self.sqlMock.ExpectRollback()
+
+	_, err := self.client.CreateBooks(self.context, []models.Book{self.book, self.otherBook()})
+
+	self.ErrorContains(err, "is not returned by the database")
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestBuildCreateBooksQueryWithoutContributors() {
+	book := self.book
+	book.Contributors = nil
+
+	query, arguments := buildCreateBooksQuery([]models.Book{book})
+
+	self.Equal(`INSERT INTO books (id, title, isbn, publication_date, description, language, page_count) VALUES `+
+		`(:id_0, :title_0, NULLIF(:isbn_0, ''), :publication_date_0, :description_0, :language_0, :page_count_0)
+RETURNING id, created_at, updated_at, version`, query)
+	self.Len(arguments, bookParameters)
+}
+
+func (self *DatabaseClientTests) TestChunkBooksFitsQueryParameters() {
+	books := make([]models.Book, maxQueryParameters/(bookParameters+contributorParameters)+1)
+	for index := range books {
+		books[index] = self.book
+	}
+
+	chunks := chunkBooks(books)
+
+	self.Require().Len(chunks, 2)
+	self.Len(chunks[0], len(books)-1)
+	self.Len(chunks[1], 1)
+	self.Nil(chunkBooks(nil))
+}
+
+func (self *DatabaseClientTests) TestCreateBooksErrorIfContributorInTrash() {
+	otherBook := self.otherBook()
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createBooksQueryMatcher).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "version", "contributors"}).
+			AddRow(self.book.ID, self.book.CreatedAt, self.book.UpdatedAt, models.FirstVersion, 1).
+			AddRow(otherBook.ID, self.book.CreatedAt, self.book.UpdatedAt, models.FirstVersion, 1))
+	self.sqlMock.ExpectRollback()
+
+	result, err := self.client.CreateBooks(self.context, []models.Book{self.book, otherBook})
+
+	self.ErrorIs(err, models.ErrValidation)
+	self.Nil(result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
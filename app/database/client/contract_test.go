@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/stdlib"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/database/contract"
+	"github.com/egormizerov/books/app/services"
+	"github.com/egormizerov/books/database/migrations"
+	"github.com/egormizerov/books/pkg/migrate"
+)
+
+// testDatabaseUrlVariable is the environment variable holding the URL of the PostgreSQL database
+// the contract tests run against, its tables are truncated before every test.
+const testDatabaseUrlVariable = "books_TestDatabaseURL"
+
+func TestDatabaseClientContract(t *testing.T) {
+	url := os.Getenv(testDatabaseUrlVariable)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseUrlVariable)
+	}
+	db, err := sqlx.Connect("pgx", url)
+	require.NoError(t, err)
+	defer db.Close()
+	migrator, err := migrate.NewMigrator(db, migrations.Postgres())
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+
+	suite.Run(t, &contract.DatabaseClientTests{
+		NewClient: func() services.DatabaseClient {
+			db.MustExec("TRUNCATE books, authors CASCADE")
+			return NewDatabaseClient(db, TxOptions{Isolation: sql.LevelReadCommitted, MaxAttempts: 1})
+		},
+	})
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// Table of the contributors passed as contributorsArguments, positions start with 1.
+var contributorsTable = `unnest(CAST(CAST(:author_ids AS text) AS uuid[]), CAST(CAST(:roles AS text) AS text[]))
+WITH ORDINALITY AS contributor(author_id, role, position)`
+
+// Condition of the contributor excluding the authors in the trash, the missing authors are kept
+// so that the foreign key rejects them.
+const contributorNotInTrashCondition = `NOT EXISTS (
+SELECT 1 FROM authors WHERE authors.id=contributor.author_id AND authors.deleted_at IS NOT NULL
+)`
+
+// errContributorInTrash is returned if the author of the contributor of the written book is in the trash.
+var errContributorInTrash = fmt.Errorf("%w: author of the contributor is in the trash", models.ErrValidation)
+
+// contributorsArguments are the contributors of the book as array literals.
+type contributorsArguments struct {
+	AuthorIds string `db:"author_ids"`
+	Roles     string `db:"roles"`
+}
+
+func newContributorsArguments(contributors []models.Contributor) contributorsArguments {
+	authorIds := make([]string, 0, len(contributors))
+	roles := make([]string, 0, len(contributors))
+	for _, contributor := range contributors {
+		authorIds = append(authorIds, contributor.Author.ID.String())
+		roles = append(roles, contributor.Role)
+	}
+
+	return contributorsArguments{
+		AuthorIds: "{" + strings.Join(authorIds, ",") + "}",
+		Roles:     "{" + strings.Join(roles, ",") + "}",
+	}
+}
+
+type contributorRow struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Role string    `json:"role"`
+}
+
+// contributorsColumn scans the JSON array of the book contributors.
+type contributorsColumn []models.Contributor
+
+func (self *contributorsColumn) Scan(src any) error {
+	var data []byte
+	switch value := src.(type) {
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("unsupported type of contributors: %T", src)
+	}
+
+	var rows []contributorRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+	*self = nil
+	for _, row := range rows {
+		*self = append(*self, models.Contributor{
+			Author: models.Author{ID: row.ID, Name: row.Name},
+			Role:   row.Role,
+		})
+	}
+
+	return nil
+}
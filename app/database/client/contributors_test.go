@@ -0,0 +1,60 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+func TestNewContributorsArguments(t *testing.T) {
+	author := uuid.New()
+	translator := uuid.New()
+
+	result := newContributorsArguments([]models.Contributor{
+		{Author: models.Author{ID: author}, Role: models.ContributorRoleAuthor},
+		{Author: models.Author{ID: translator}, Role: models.ContributorRoleTranslator},
+	})
+
+	assert.Equal(t, contributorsArguments{
+		AuthorIds: "{" + author.String() + "," + translator.String() + "}",
+		Roles:     "{author,translator}",
+	}, result)
+}
+
+func TestNewContributorsArgumentsEmpty(t *testing.T) {
+	result := newContributorsArguments(nil)
+
+	assert.Equal(t, contributorsArguments{AuthorIds: "{}", Roles: "{}"}, result)
+}
+
+func TestContributorsColumnScan(t *testing.T) {
+	authorId := uuid.New()
+	var result contributorsColumn
+
+	err := result.Scan([]byte(`[{"id":"` + authorId.String() + `","name":"test_name","role":"editor"}]`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, contributorsColumn{
+		{Author: models.Author{ID: authorId, Name: "test_name"}, Role: models.ContributorRoleEditor},
+	}, result)
+}
+
+func TestContributorsColumnScanEmpty(t *testing.T) {
+	result := contributorsColumn{{Role: models.ContributorRoleAuthor}}
+
+	err := result.Scan("[]")
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestContributorsColumnScanErrorIfUnsupportedType(t *testing.T) {
+	var result contributorsColumn
+
+	err := result.Scan(nil)
+
+	assert.EqualError(t, err, "unsupported type of contributors: <nil>")
+}
@@ -3,7 +3,7 @@ package client
 import (
 	"context"
 	"errors"
-	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -11,27 +11,181 @@ import (
 	"github.com/egormizerov/books/app/models"
 )
 
+// Condition of the conditional write matching the row of the version argument unless it is models.AnyVersion.
+const versionCondition = `(CAST(:version AS bigint)=0 OR version=:version)`
+
 // Query template to create author.
-var createAuthorQuery = `INSERT INTO authors (id, name) VALUES (:id, :name)`
+var createAuthorQuery = `INSERT INTO authors (id, name) VALUES (:id, :name) RETURNING version`
+
+// Columns selected to scan the book into bookRow, the missing ISBN is selected as the empty string
+// and the contributors as the JSON array ordered by position, the deleted authors are not listed.
+const bookColumns = `id, title, COALESCE(isbn, '') AS isbn, publication_date, description, language, page_count, created_at, updated_at, version,
+COALESCE((SELECT json_agg(json_build_object('id', authors.id, 'name', authors.name, 'role', book_authors.role) ORDER BY book_authors.position)
+FROM book_authors JOIN authors ON authors.id=book_authors.author_id AND authors.deleted_at IS NULL
+WHERE book_authors.book_id=books.id), '[]') AS contributors`
 
-// Query template to create book.
-var createBookQuery = `INSERT INTO books (id, title, author_id) VALUES (:id, :title, :author_id)`
+// Query template to create book with its contributors, the authors in the trash are not inserted
+// and the inserted contributors are counted.
+var createBookQuery = `WITH book AS (
+INSERT INTO books (id, title, isbn, publication_date, description, language, page_count)
+VALUES (:id, :title, NULLIF(:isbn, ''), :publication_date, :description, :language, :page_count)
+RETURNING id, created_at, updated_at, version
+), contributors AS (
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT book.id, contributor.author_id, contributor.role, contributor.position FROM book, ` + contributorsTable + `
+WHERE ` + contributorNotInTrashCondition + `
+RETURNING author_id
+)
+SELECT created_at, updated_at, version, (SELECT count(*) FROM contributors) AS contributors FROM book`
 
 // Query template to get book by id.
-var getBookByIdQuery = `SELECT id, title, author_id FROM books WHERE id=:book_id`
+var getBookByIdQuery = `SELECT ` + bookColumns + ` FROM books WHERE id=:book_id AND deleted_at IS NULL`
+
+// Query template to get book by ISBN.
+var getBookByISBNQuery = `SELECT ` + bookColumns + ` FROM books WHERE isbn=:isbn AND deleted_at IS NULL`
 
 // Query template to get author by id.
-var getAuthorByIdQuery = `SELECT id, name FROM authors WHERE id=:author_id`
+var getAuthorByIdQuery = `SELECT id, name, version FROM authors WHERE id=:author_id AND deleted_at IS NULL`
+
+// Query to get the page of author's books.
+var getBooksByAuthorIdQuery = pageQuery{
+	table:        "books",
+	columns:      bookColumns,
+	prefixColumn: "title",
+	conditions: []string{
+		"deleted_at IS NULL",
+		`id IN (SELECT book_id FROM book_authors JOIN authors ON authors.id=book_authors.author_id
+WHERE book_authors.author_id=:author_id AND authors.deleted_at IS NULL)`,
+	},
+	sortOrders: bookSortOrders,
+}
+
+// Query to get the page of authors.
+var getAuthorsQuery = pageQuery{
+	table:        "authors",
+	columns:      "id, name, version",
+	prefixColumn: "name",
+	conditions:   []string{"deleted_at IS NULL"},
+	sortOrders:   authorSortOrders,
+}
 
-// Query template to get book's by author id.
-var getBooksByAuthorIdQuery = `SELECT id, title, author_id FROM books WHERE author_id=:author_id`
+// Query template to update author and increment the versions of the books it contributes to.
+var updateAuthorQuery = `WITH author AS (
+UPDATE authors SET name=:name, version=version+1 WHERE id=:id AND deleted_at IS NULL AND ` + versionCondition + `
+RETURNING id, version
+), books AS (
+UPDATE books SET version=version+1, updated_at=now()
+WHERE id IN (SELECT book_id FROM book_authors WHERE author_id IN (SELECT id FROM author))
+)
+SELECT version FROM author`
+
+// Query template to move author to the trash and increment the versions of the books it contributed to.
+// The author is kept in book_authors, so the restored author contributes to the books again. The books
+// left without other contributors not in the trash are counted.
+var deleteAuthorQuery = `WITH deleted_author AS (
+UPDATE authors SET deleted_at=now() WHERE id=:author_id AND deleted_at IS NULL AND ` + versionCondition + ` RETURNING id
+), books AS (
+UPDATE books SET version=version+1, updated_at=now()
+WHERE id IN (SELECT book_id FROM book_authors WHERE author_id IN (SELECT id FROM deleted_author))
+)
+SELECT count(DISTINCT books.id) AS orphaned_books FROM deleted_author
+LEFT JOIN book_authors ON book_authors.author_id=deleted_author.id
+LEFT JOIN books ON books.id=book_authors.book_id AND books.deleted_at IS NULL AND NOT EXISTS (
+SELECT 1 FROM book_authors AS others JOIN authors ON authors.id=others.author_id
+WHERE others.book_id=book_authors.book_id AND others.author_id<>deleted_author.id AND authors.deleted_at IS NULL
+) GROUP BY deleted_author.id`
+
+// Query to get the page of books.
+var getBooksQuery = pageQuery{
+	table:        "books",
+	columns:      bookColumns,
+	prefixColumn: "title",
+	conditions:   []string{"deleted_at IS NULL"},
+	sortOrders:   bookSortOrders,
+}
+
+// Query to search books by title and authors by name.
+var searchQuery = pageQuery{
+	table: `(SELECT 'book' AS type, id, title, ts_headline('simple', title, query) AS snippet, ts_rank(search_vector, query) AS rank
+FROM books, websearch_to_tsquery('simple', :query) AS query WHERE search_vector @@ query AND deleted_at IS NULL
+UNION ALL
+SELECT 'author' AS type, id, name, ts_headline('simple', name, query), ts_rank(search_vector, query)
+FROM authors, websearch_to_tsquery('simple', :query) AS query WHERE search_vector @@ query AND deleted_at IS NULL) AS results`,
+	columns:      "type, id, title, snippet, rank",
+	prefixColumn: "title",
+	sortOrders:   searchSortOrders,
+}
+
+// Query template to update book and replace its contributors. Removed contributors are deleted
+// and the others are upserted, so the statement never touches the same row twice. The authors in the trash
+// are not upserted and the upserted contributors are counted.
+var updateBookQuery = `WITH book AS (
+UPDATE books SET title=:title, isbn=NULLIF(:isbn, ''), publication_date=:publication_date,
+description=:description, language=:language, page_count=:page_count, updated_at=now(), version=version+1
+WHERE id=:id AND deleted_at IS NULL AND ` + versionCondition + ` RETURNING id, created_at, updated_at, version
+), contributors AS (
+SELECT book.id AS book_id, contributor.author_id, contributor.role, contributor.position FROM book, ` + contributorsTable + `
+WHERE ` + contributorNotInTrashCondition + `
+), removed_contributors AS (
+DELETE FROM book_authors WHERE book_id IN (SELECT id FROM book)
+AND (author_id, role) NOT IN (SELECT author_id, role FROM contributors)
+), upserted_contributors AS (
+INSERT INTO book_authors (book_id, author_id, role, position) SELECT book_id, author_id, role, position FROM contributors
+ON CONFLICT (book_id, author_id, role) DO UPDATE SET position=EXCLUDED.position
+)
+SELECT created_at, updated_at, version, (SELECT count(*) FROM contributors) AS contributors FROM book`
+
+// Query template to move book to the trash.
+var deleteBookQuery = `UPDATE books SET deleted_at=now() WHERE id=:book_id AND deleted_at IS NULL AND ` + versionCondition
+
+// Query template to get the version of book, it tells the missing book from the version mismatch.
+var getBookVersionQuery = `SELECT version FROM books WHERE id=:id AND deleted_at IS NULL`
+
+// Query template to get the version of author, it tells the missing author from the version mismatch.
+var getAuthorVersionQuery = `SELECT version FROM authors WHERE id=:id AND deleted_at IS NULL`
+
+// Query template to restore book from the trash.
+var restoreBookQuery = `UPDATE books SET deleted_at=NULL, updated_at=now(), version=version+1
+WHERE id=:book_id AND deleted_at IS NOT NULL RETURNING ` + bookColumns
+
+// Query template to restore author from the trash and increment the versions of the books it contributes to.
+var restoreAuthorQuery = `WITH author AS (
+UPDATE authors SET deleted_at=NULL, version=version+1 WHERE id=:author_id AND deleted_at IS NOT NULL RETURNING id, name, version
+), books AS (
+UPDATE books SET version=version+1, updated_at=now()
+WHERE id IN (SELECT book_id FROM book_authors WHERE author_id IN (SELECT id FROM author))
+)
+SELECT id, name, version FROM author`
+
+// Query to get the page of the books and the authors in the trash.
+var getTrashQuery = pageQuery{
+	table: `(SELECT 'book' AS type, id, title, deleted_at FROM books WHERE deleted_at IS NOT NULL
+UNION ALL
+SELECT 'author' AS type, id, name, deleted_at FROM authors WHERE deleted_at IS NOT NULL) AS items`,
+	columns:      "type, id, title, deleted_at",
+	prefixColumn: "title",
+	sortOrders:   trashSortOrders,
+}
+
+// Query template to delete the books and the authors moved to the trash before the time,
+// the foreign keys remove them from book_authors.
+var purgeTrashQuery = `WITH purged_books AS (
+DELETE FROM books WHERE deleted_at<:deleted_before RETURNING id
+), purged_authors AS (
+DELETE FROM authors WHERE deleted_at<:deleted_before RETURNING id
+)
+SELECT (SELECT count(*) FROM purged_books) + (SELECT count(*) FROM purged_authors) AS purged`
 
 type DatabaseClient struct {
-	db *sqlx.DB
+	// db runs the statements, it is the transaction inside WithTx.
+	db sqlx.ExtContext
+	// conn begins the transactions, it is nil inside WithTx.
+	conn      *sqlx.DB
+	txOptions TxOptions
 }
 
-func NewDatabaseClient(db *sqlx.DB) *DatabaseClient {
-	return &DatabaseClient{db: db}
+func NewDatabaseClient(db *sqlx.DB, txOptions TxOptions) *DatabaseClient {
+	return &DatabaseClient{db: db, conn: db, txOptions: txOptions}
 }
 
 type createAuthorArguments struct {
@@ -39,27 +193,42 @@ type createAuthorArguments struct {
 	Name string    `db:"name"`
 }
 
-func (self *DatabaseClient) CreateAuthor(ctx context.Context, author models.Author) error {
-	_, err := self.db.NamedExecContext(ctx, createAuthorQuery, createAuthorArguments{
+// CreateAuthor creates the author and returns it with the version set by the database.
+func (self *DatabaseClient) CreateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	err := getNamed(ctx, self.db, authorEntity, &author.Version, createAuthorQuery, createAuthorArguments{
 		ID:   author.ID,
 		Name: author.Name,
 	})
-	return err
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return author, nil
 }
 
 type createBookArguments struct {
-	ID       uuid.UUID `db:"id"`
-	Title    string    `db:"title"`
-	AuthorId uuid.UUID `db:"author_id"`
+	contributorsArguments
+	ID              uuid.UUID  `db:"id"`
+	Title           string     `db:"title"`
+	ISBN            string     `db:"isbn"`
+	PublicationDate *time.Time `db:"publication_date"`
+	Description     string     `db:"description"`
+	Language        string     `db:"language"`
+	PageCount       int        `db:"page_count"`
 }
 
-func (self *DatabaseClient) CreateBook(ctx context.Context, book models.Book) error {
-	_, err := self.db.NamedExecContext(ctx, createBookQuery, createBookArguments{
-		ID:       book.ID,
-		Title:    book.Title,
-		AuthorId: book.Author.ID,
-	})
-	return err
+// CreateBook creates the book and returns it with the timestamps and the version set by the database.
+func (self *DatabaseClient) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	return self.writeBook(ctx, createBookQuery, createBookArguments{
+		contributorsArguments: newContributorsArguments(book.Contributors),
+		ID:                    book.ID,
+		Title:                 book.Title,
+		ISBN:                  book.ISBN,
+		PublicationDate:       book.PublicationDate,
+		Description:           book.Description,
+		Language:              book.Language,
+		PageCount:             book.PageCount,
+	}, book)
 }
 
 type getBookArguments struct {
@@ -67,22 +236,29 @@ type getBookArguments struct {
 }
 
 func (self *DatabaseClient) GetBookById(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
-	rows, err := self.db.NamedQueryContext(ctx, getBookByIdQuery, getBookArguments{
+	return self.getBook(ctx, getBookByIdQuery, getBookArguments{
 		BookId: bookId,
 	})
-	if err != nil {
+}
+
+type getBookByISBNArguments struct {
+	ISBN string `db:"isbn"`
+}
+
+// GetBookByISBN returns the book by the normalized ISBN-13.
+func (self *DatabaseClient) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	return self.getBook(ctx, getBookByISBNQuery, getBookByISBNArguments{
+		ISBN: isbn,
+	})
+}
+
+func (self *DatabaseClient) getBook(ctx context.Context, query string, arguments any) (models.Book, error) {
+	var row bookRow
+	if err := getNamed(ctx, self.db, bookEntity, &row, query, arguments); err != nil {
 		return models.Book{}, err
 	}
-	if !rows.Next() {
-		return models.Book{}, errors.New("no rows in result set")
-	}
-	book := models.Book{Author: models.Author{}}
-	err = rows.Scan(&book.ID, &book.Title, &book.Author.ID)
-	if err != nil {
-		return models.Book{}, fmt.Errorf("failed to scan row: %s", err)
-	}
 
-	return book, nil
+	return row.item(), nil
 }
 
 type getAuthorArguments struct {
@@ -90,45 +266,234 @@ type getAuthorArguments struct {
 }
 
 func (self *DatabaseClient) GetAuthorById(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
-	rows, err := self.db.NamedQueryContext(ctx, getAuthorByIdQuery, getAuthorArguments{
+	var row authorRow
+	err := getNamed(ctx, self.db, authorEntity, &row, getAuthorByIdQuery, getAuthorArguments{
 		AuthorId: authorId,
 	})
 	if err != nil {
 		return models.Author{}, err
 	}
-	if !rows.Next() {
-		return models.Author{}, errors.New("no rows in result set")
-	}
-	var author models.Author
-	err = rows.Scan(&author.ID, &author.Name)
+
+	return row.item(), nil
+}
+
+func (self *DatabaseClient) GetBooksByAuthorId(
+	ctx context.Context,
+	authorId uuid.UUID,
+	options models.ListOptions,
+) (models.Page[models.Book], error) {
+	return queryPage[models.Book, bookRow](ctx, self.db, getBooksByAuthorIdQuery, options, pageArguments{AuthorId: authorId})
+}
+
+func (self *DatabaseClient) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	return queryPage[models.Author, authorRow](ctx, self.db, getAuthorsQuery, options, pageArguments{})
+}
+
+type updateAuthorArguments struct {
+	ID      uuid.UUID `db:"id"`
+	Name    string    `db:"name"`
+	Version int64     `db:"version"`
+}
+
+// UpdateAuthor updates the author of the expected version and returns it with the incremented version.
+func (self *DatabaseClient) UpdateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	err := getNamed(ctx, self.db, authorEntity, &author.Version, updateAuthorQuery, updateAuthorArguments{
+		ID:      author.ID,
+		Name:    author.Name,
+		Version: author.Version,
+	})
 	if err != nil {
-		return models.Author{}, fmt.Errorf("failed to scan row: %s", err)
+		return models.Author{}, self.conditionalWriteError(ctx, err, authorEntity, getAuthorVersionQuery, author.ID, author.Version)
 	}
 
 	return author, nil
 }
 
-type getBooksByAuthorIdArguments struct {
+type deleteAuthorArguments struct {
 	AuthorId uuid.UUID `db:"author_id"`
+	Version  int64     `db:"version"`
 }
 
-func (self *DatabaseClient) GetBooksByAuthorId(ctx context.Context, authorId uuid.UUID) ([]models.Book, error) {
-	rows, err := self.db.NamedQueryContext(ctx, getBooksByAuthorIdQuery, getBooksByAuthorIdArguments{
+// DeleteAuthor moves the author of the expected version to the trash and returns the number of books
+// left without an author.
+func (self *DatabaseClient) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	var orphanedBooks int64
+	err := getNamed(ctx, self.db, authorEntity, &orphanedBooks, deleteAuthorQuery, deleteAuthorArguments{
 		AuthorId: authorId,
+		Version:  version,
 	})
 	if err != nil {
-		return nil, err
+		return 0, self.conditionalWriteError(ctx, err, authorEntity, getAuthorVersionQuery, authorId, version)
 	}
 
-	var books []models.Book
-	for rows.Next() {
-		book := models.Book{Author: models.Author{}}
-		err = rows.Scan(&book.ID, &book.Title, &book.Author.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %s", err)
+	return orphanedBooks, nil
+}
+
+func (self *DatabaseClient) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	return queryPage[models.Book, bookRow](ctx, self.db, getBooksQuery, options, pageArguments{})
+}
+
+type updateBookArguments struct {
+	contributorsArguments
+	ID              uuid.UUID  `db:"id"`
+	Title           string     `db:"title"`
+	ISBN            string     `db:"isbn"`
+	PublicationDate *time.Time `db:"publication_date"`
+	Description     string     `db:"description"`
+	Language        string     `db:"language"`
+	PageCount       int        `db:"page_count"`
+	Version         int64      `db:"version"`
+}
+
+// UpdateBook updates the book of the expected version and returns it with the timestamps and the version
+// set by the database.
+func (self *DatabaseClient) UpdateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	updated, err := self.writeBook(ctx, updateBookQuery, updateBookArguments{
+		contributorsArguments: newContributorsArguments(book.Contributors),
+		ID:                    book.ID,
+		Title:                 book.Title,
+		ISBN:                  book.ISBN,
+		PublicationDate:       book.PublicationDate,
+		Description:           book.Description,
+		Language:              book.Language,
+		PageCount:             book.PageCount,
+		Version:               book.Version,
+	}, book)
+	if err != nil {
+		return models.Book{}, self.conditionalWriteError(ctx, err, bookEntity, getBookVersionQuery, book.ID, book.Version)
+	}
+
+	return updated, nil
+}
+
+// writeBook runs the query returning the timestamps and the version of the written book in the transaction,
+// it is rolled back if any contributor was not written because its author is in the trash.
+func (self *DatabaseClient) writeBook(ctx context.Context, query string, arguments any, book models.Book) (models.Book, error) {
+	var row writtenBookRow
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		if err := getNamed(ctx, tx.db, bookEntity, &row, query, arguments); err != nil {
+			return err
+		}
+		if row.Contributors < len(book.Contributors) {
+			return errContributorInTrash
 		}
-		books = append(books, book)
+		return nil
+	})
+	if err != nil {
+		return models.Book{}, err
+	}
+	book.CreatedAt = row.CreatedAt
+	book.UpdatedAt = row.UpdatedAt
+	book.Version = row.Version
+
+	return book, nil
+}
+
+type deleteBookArguments struct {
+	BookId  uuid.UUID `db:"book_id"`
+	Version int64     `db:"version"`
+}
+
+// DeleteBook moves the book of the expected version to the trash.
+func (self *DatabaseClient) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	err := execNamed(ctx, self.db, bookEntity, deleteBookQuery, deleteBookArguments{
+		BookId:  bookId,
+		Version: version,
+	})
+	if err != nil {
+		return self.conditionalWriteError(ctx, err, bookEntity, getBookVersionQuery, bookId, version)
+	}
+
+	return nil
+}
+
+type getVersionArguments struct {
+	ID uuid.UUID `db:"id"`
+}
+
+// conditionalWriteError returns the error of the conditional write. The write of the expected version
+// matching no rows fails with models.VersionMismatchError if the entity exists and with models.NotFoundError otherwise.
+func (self *DatabaseClient) conditionalWriteError(
+	ctx context.Context,
+	err error,
+	entity string,
+	versionQuery string,
+	id uuid.UUID,
+	version int64,
+) error {
+	if version == models.AnyVersion || !errors.Is(err, models.ErrNotFound) {
+		return err
+	}
+	var currentVersion int64
+	if err = getNamed(ctx, self.db, entity, &currentVersion, versionQuery, getVersionArguments{ID: id}); err != nil {
+		return err
+	}
+
+	return models.VersionMismatchError{Entity: entity}
+}
+
+// Search returns the books and the authors matching the query, ordered by rank.
+func (self *DatabaseClient) Search(
+	ctx context.Context,
+	search models.SearchQuery,
+	options models.ListOptions,
+) (models.Page[models.SearchResult], error) {
+	query := searchQuery
+	if search.Type != "" {
+		query.conditions = []string{"type=:type"}
+	}
+	return queryPage[models.SearchResult, searchResultRow](
+		ctx, self.db, query, options, pageArguments{Query: search.Text, Type: search.Type},
+	)
+}
+
+// RestoreBook restores the book from the trash and returns it with the incremented version.
+func (self *DatabaseClient) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	return self.getBook(ctx, restoreBookQuery, getBookArguments{
+		BookId: bookId,
+	})
+}
+
+// RestoreAuthor restores the author from the trash, increments the versions of the books it contributes to
+// and returns the author with the incremented version.
+func (self *DatabaseClient) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	var row authorRow
+	err := getNamed(ctx, self.db, authorEntity, &row, restoreAuthorQuery, getAuthorArguments{
+		AuthorId: authorId,
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return row.item(), nil
+}
+
+// GetTrash returns the books and the authors in the trash, the last deleted first.
+func (self *DatabaseClient) GetTrash(
+	ctx context.Context,
+	trash models.TrashQuery,
+	options models.ListOptions,
+) (models.Page[models.TrashItem], error) {
+	query := getTrashQuery
+	if trash.Type != "" {
+		query.conditions = []string{"type=:type"}
+	}
+	return queryPage[models.TrashItem, trashItemRow](ctx, self.db, query, options, pageArguments{Type: trash.Type})
+}
+
+type purgeTrashArguments struct {
+	DeletedBefore time.Time `db:"deleted_before"`
+}
+
+// PurgeTrash deletes the books and the authors moved to the trash before the time and returns their number.
+func (self *DatabaseClient) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := getNamed(ctx, self.db, trashItemEntity, &purged, purgeTrashQuery, purgeTrashArguments{
+		DeletedBefore: deletedBefore,
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return books, nil
+	return purged, nil
 }
@@ -2,9 +2,12 @@ package client
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -16,13 +19,57 @@ import (
 )
 
 var (
-	createAuthorQueryMatcher       = regexp.QuoteMeta(`INSERT INTO authors (id, name) VALUES (?, ?)`)
-	createBookQueryMatcher         = regexp.QuoteMeta(`INSERT INTO books (id, title, author_id) VALUES (?, ?, ?)`)
-	getBookByIdQueryMatcher        = `SELECT id, title, author_id FROM books WHERE id=?`
-	getAuthorByIdQueryMatcher      = `SELECT id, name FROM authors WHERE id=?`
-	getBooksByAuthorIdQueryMatcher = `SELECT id, title, author_id FROM books WHERE author_id=?`
+	createAuthorQueryMatcher = regexp.QuoteMeta(`INSERT INTO authors (id, name) VALUES (?, ?) RETURNING version`)
+	createBookQueryMatcher   = regexp.QuoteMeta(`INSERT INTO books (id, title, isbn, publication_date, description, language, page_count)
+VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?)`) + `(?s:.*)` + regexp.QuoteMeta(`INSERT INTO book_authors (book_id, author_id, role, position)`)
+	getBookByIdQueryMatcher        = regexp.QuoteMeta(`SELECT ` + bookColumns + ` FROM books WHERE id=? AND deleted_at IS NULL`)
+	getBookByISBNQueryMatcher      = regexp.QuoteMeta(`SELECT ` + bookColumns + ` FROM books WHERE isbn=? AND deleted_at IS NULL`)
+	getAuthorByIdQueryMatcher      = regexp.QuoteMeta(`SELECT id, name, version FROM authors WHERE id=? AND deleted_at IS NULL`)
+	getBooksByAuthorIdQueryMatcher = regexp.QuoteMeta(`SELECT ` + bookColumns + `, CAST(title AS text) AS sort_value FROM books WHERE deleted_at IS NULL AND id IN (SELECT book_id FROM book_authors JOIN authors ON authors.id=book_authors.author_id
+WHERE book_authors.author_id=? AND authors.deleted_at IS NULL) ORDER BY title ASC, id ASC LIMIT ?`)
+	getAuthorsQueryMatcher   = regexp.QuoteMeta(`SELECT id, name, version, CAST(name AS text) AS sort_value FROM authors WHERE deleted_at IS NULL ORDER BY name ASC, id ASC LIMIT ?`)
+	updateAuthorQueryMatcher = regexp.QuoteMeta(`UPDATE authors SET name=?, version=version+1 WHERE id=? AND deleted_at IS NULL AND (CAST(? AS bigint)=0 OR version=?)`)
+	deleteAuthorQueryMatcher = regexp.QuoteMeta(`UPDATE authors SET deleted_at=now() WHERE id=? AND deleted_at IS NULL AND (CAST(? AS bigint)=0 OR version=?) RETURNING id`)
+	getBooksQueryMatcher     = regexp.QuoteMeta(`SELECT ` + bookColumns + `, CAST(title AS text) AS sort_value FROM books WHERE deleted_at IS NULL ORDER BY title ASC, id ASC LIMIT ?`)
+	updateBookQueryMatcher   = regexp.QuoteMeta(`UPDATE books SET title=?, isbn=NULLIF(?, ''), publication_date=?,
+description=?, language=?, page_count=?, updated_at=now(), version=version+1
+WHERE id=? AND deleted_at IS NULL AND (CAST(? AS bigint)=0 OR version=?) RETURNING id, created_at, updated_at, version`) + `(?s:.*)` + regexp.QuoteMeta(`ON CONFLICT (book_id, author_id, role) DO UPDATE SET position=EXCLUDED.position`)
+	deleteBookQueryMatcher       = regexp.QuoteMeta(`UPDATE books SET deleted_at=now() WHERE id=? AND deleted_at IS NULL AND (CAST(? AS bigint)=0 OR version=?)`)
+	getBookVersionQueryMatcher   = regexp.QuoteMeta(`SELECT version FROM books WHERE id=? AND deleted_at IS NULL`)
+	getAuthorVersionQueryMatcher = regexp.QuoteMeta(`SELECT version FROM authors WHERE id=? AND deleted_at IS NULL`)
+	restoreBookQueryMatcher      = regexp.QuoteMeta(`UPDATE books SET deleted_at=NULL, updated_at=now(), version=version+1
+WHERE id=? AND deleted_at IS NOT NULL RETURNING ` + bookColumns)
+	restoreAuthorQueryMatcher = regexp.QuoteMeta(`UPDATE authors SET deleted_at=NULL, version=version+1 WHERE id=? AND deleted_at IS NOT NULL`)
+	getTrashQueryMatcher      = regexp.QuoteMeta(`SELECT type, id, title, deleted_at, CAST(deleted_at AS text) AS sort_value FROM (SELECT 'book' AS type`)
+	purgeTrashQueryMatcher    = regexp.QuoteMeta(`DELETE FROM books WHERE deleted_at<?`) + `(?s:.*)` + regexp.QuoteMeta(`DELETE FROM authors WHERE deleted_at<?`)
 )
 
+// bookColumnNames returns the names of bookColumns followed by the extra columns.
+func bookColumnNames(extra ...string) []string {
+	return append([]string{
+		"id", "title", "isbn", "publication_date", "description",
+		"language", "page_count", "created_at", "updated_at", "version", "contributors",
+	}, extra...)
+}
+
+// bookValues returns the values of bookColumns followed by the extra values.
+func bookValues(book models.Book, extra ...driver.Value) []driver.Value {
+	contributors := []contributorRow{}
+	for _, contributor := range book.Contributors {
+		contributors = append(contributors, contributorRow{
+			ID:   contributor.Author.ID,
+			Name: contributor.Author.Name,
+			Role: contributor.Role,
+		})
+	}
+	contributorsJson, _ := json.Marshal(contributors)
+
+	return append([]driver.Value{
+		book.ID, book.Title, book.ISBN, book.PublicationDate, book.Description,
+		book.Language, book.PageCount, book.CreatedAt, book.UpdatedAt, book.Version, contributorsJson,
+	}, extra...)
+}
+
 type DatabaseClientTests struct {
 	suite.Suite
 	client  DatabaseClient
@@ -45,71 +92,126 @@ func (self *DatabaseClientTests) SetupTest() {
 
 	self.sqlxDB = sqlx.NewDb(mockDatabaseConnection, "sqlmock")
 	self.sqlMock = sqlMock
-	self.client = DatabaseClient{db: self.sqlxDB}
+	self.client = *NewDatabaseClient(self.sqlxDB, TxOptions{Isolation: sql.LevelSerializable, MaxAttempts: 2})
 	self.context = context.Background()
 
 	self.author = models.Author{
-		ID:   uuid.New(),
-		Name: "test_author",
+		ID:      uuid.New(),
+		Name:    "test_author",
+		Version: models.FirstVersion,
 	}
+	publicationDate := time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC)
 	self.book = models.Book{
-		ID:     uuid.New(),
-		Title:  "test_title",
-		Author: models.Author{ID: self.author.ID},
+		ID:              uuid.New(),
+		Title:           "test_title",
+		Contributors:    []models.Contributor{{Author: models.Author{ID: self.author.ID, Name: self.author.Name}, Role: models.ContributorRoleAuthor}},
+		ISBN:            "9780306406157",
+		PublicationDate: &publicationDate,
+		Description:     "test_description",
+		Language:        "en",
+		PageCount:       256,
+		CreatedAt:       time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:       time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC),
+		Version:         models.FirstVersion,
 	}
 	self.testError = errors.New("test_error")
 }
 
+func (self *DatabaseClientTests) contributorAuthorIds() string {
+	return "{" + self.author.ID.String() + "}"
+}
+
 func (self *DatabaseClientTests) TestNewDatabaseClient() {
-	result := NewDatabaseClient(self.sqlxDB)
+	txOptions := TxOptions{Isolation: sql.LevelSerializable, MaxAttempts: 2}
+
+	result := NewDatabaseClient(self.sqlxDB, txOptions)
 
 	self.Equal(&DatabaseClient{
-		db: self.sqlxDB,
+		db:        self.sqlxDB,
+		conn:      self.sqlxDB,
+		txOptions: txOptions,
 	}, result)
 }
 
-func (self *DatabaseClientTests) TestCreateAuthorErrorIfSqlExecFailed() {
+func (self *DatabaseClientTests) TestCreateAuthorErrorIfSqlQueryFailed() {
 	self.sqlMock.
-		ExpectExec(createAuthorQueryMatcher).
+		ExpectQuery(createAuthorQueryMatcher).
 		WithArgs(self.author.ID, self.author.Name).
 		WillReturnError(self.testError)
 
-	err := self.client.CreateAuthor(self.context, self.author)
+	result, err := self.client.CreateAuthor(self.context, self.author)
 
 	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Author{}, result)
 }
 
 func (self *DatabaseClientTests) TestCreateAuthor() {
+	author := self.author
+	author.Version = models.AnyVersion
 	self.sqlMock.
-		ExpectExec(createAuthorQueryMatcher).
+		ExpectQuery(createAuthorQueryMatcher).
 		WithArgs(self.author.ID, self.author.Name).
-		WillReturnResult(driver.ResultNoRows)
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(models.FirstVersion))
 
-	err := self.client.CreateAuthor(self.context, self.author)
+	result, err := self.client.CreateAuthor(self.context, author)
 
 	self.NoError(err)
+	self.Equal(self.author, result)
 }
 
-func (self *DatabaseClientTests) TestCreateBookErrorIfSqlExecFailed() {
+func (self *DatabaseClientTests) TestCreateBookErrorIfSqlQueryFailed() {
+	self.sqlMock.ExpectBegin()
 	self.sqlMock.
-		ExpectExec(createBookQueryMatcher).
-		WithArgs(self.book.ID, self.book.Title, self.book.Author.ID).
+		ExpectQuery(createBookQueryMatcher).
+		WithArgs(self.book.ID, self.book.Title, self.book.ISBN, self.book.PublicationDate,
+			self.book.Description, self.book.Language, self.book.PageCount, self.contributorAuthorIds(), "{author}").
 		WillReturnError(self.testError)
+	self.sqlMock.ExpectRollback()
 
-	err := self.client.CreateBook(self.context, self.book)
+	result, err := self.client.CreateBook(self.context, self.book)
 
 	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Book{}, result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestCreateBookErrorIfContributorInTrash() {
+	rows := sqlmock.NewRows([]string{"created_at", "updated_at", "version", "contributors"}).
+		AddRow(self.book.CreatedAt, self.book.UpdatedAt, self.book.Version, 0)
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createBookQueryMatcher).
+		WithArgs(self.book.ID, self.book.Title, self.book.ISBN, self.book.PublicationDate,
+			self.book.Description, self.book.Language, self.book.PageCount, self.contributorAuthorIds(), "{author}").
+		WillReturnRows(rows)
+	self.sqlMock.ExpectRollback()
+
+	result, err := self.client.CreateBook(self.context, self.book)
+
+	self.ErrorIs(err, models.ErrValidation)
+	self.ErrorContains(err, "author of the contributor is in the trash")
+	self.Equal(models.Book{}, result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
 }
 
 func (self *DatabaseClientTests) TestCreateBook() {
+	rows := sqlmock.NewRows([]string{"created_at", "updated_at", "version", "contributors"}).
+		AddRow(self.book.CreatedAt, self.book.UpdatedAt, self.book.Version, len(self.book.Contributors))
+	book := self.book
+	book.CreatedAt, book.UpdatedAt, book.Version = time.Time{}, time.Time{}, models.AnyVersion
+	self.sqlMock.ExpectBegin()
 	self.sqlMock.
-		ExpectExec(createBookQueryMatcher).
-		WithArgs(self.book.ID, self.book.Title, self.book.Author.ID).
-		WillReturnResult(driver.ResultNoRows)
+		ExpectQuery(createBookQueryMatcher).
+		WithArgs(self.book.ID, self.book.Title, self.book.ISBN, self.book.PublicationDate,
+			self.book.Description, self.book.Language, self.book.PageCount, self.contributorAuthorIds(), "{author}").
+		WillReturnRows(rows)
+	self.sqlMock.ExpectCommit()
 
-	err := self.client.CreateBook(self.context, self.book)
+	result, err := self.client.CreateBook(self.context, book)
 
 	self.NoError(err)
+	self.Equal(self.book, result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
 }
 
 func (self *DatabaseClientTests) TestGetBookByIdErrorIfSqlQueryFailed() {
@@ -128,11 +230,11 @@ func (self *DatabaseClientTests) TestGetBookByIdErrorIfNoRows() {
 	self.sqlMock.
 		ExpectQuery(getBookByIdQueryMatcher).
 		WithArgs(self.book.ID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id"}))
+		WillReturnRows(sqlmock.NewRows(bookColumnNames()))
 
 	result, err := self.client.GetBookById(self.context, self.book.ID)
 
-	self.EqualError(err, "no rows in result set")
+	self.ErrorIs(err, models.ErrNotFound)
 	self.Equal(models.Book{}, result)
 }
 
@@ -145,13 +247,13 @@ func (self *DatabaseClientTests) TestGetBookByIdErrorIfScanRowFailed() {
 
 	result, err := self.client.GetBookById(self.context, self.book.ID)
 
-	self.ErrorContains(err, "failed to scan row")
+	self.ErrorContains(err, "missing destination name not_book_field")
 	self.Equal(models.Book{}, result)
 }
 
 func (self *DatabaseClientTests) TestGetBookById() {
-	rows := sqlmock.NewRows([]string{"id", "title", "author_id"}).
-		AddRow(self.book.ID, self.book.Title, self.book.Author.ID)
+	rows := sqlmock.NewRows(bookColumnNames()).
+		AddRow(bookValues(self.book)...)
 	self.sqlMock.
 		ExpectQuery(getBookByIdQueryMatcher).
 		WithArgs(self.book.ID).
@@ -163,6 +265,32 @@ func (self *DatabaseClientTests) TestGetBookById() {
 	self.Equal(self.book, result)
 }
 
+func (self *DatabaseClientTests) TestGetBookByISBNErrorIfNoRows() {
+	self.sqlMock.
+		ExpectQuery(getBookByISBNQueryMatcher).
+		WithArgs(self.book.ISBN).
+		WillReturnRows(sqlmock.NewRows(bookColumnNames()))
+
+	result, err := self.client.GetBookByISBN(self.context, self.book.ISBN)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Equal(models.Book{}, result)
+}
+
+func (self *DatabaseClientTests) TestGetBookByISBN() {
+	rows := sqlmock.NewRows(bookColumnNames()).
+		AddRow(bookValues(self.book)...)
+	self.sqlMock.
+		ExpectQuery(getBookByISBNQueryMatcher).
+		WithArgs(self.book.ISBN).
+		WillReturnRows(rows)
+
+	result, err := self.client.GetBookByISBN(self.context, self.book.ISBN)
+
+	self.NoError(err)
+	self.Equal(self.book, result)
+}
+
 func (self *DatabaseClientTests) TestGetAuthorByIdErrorIfSqlQueryFailed() {
 	self.sqlMock.
 		ExpectQuery(getAuthorByIdQueryMatcher).
@@ -179,11 +307,11 @@ func (self *DatabaseClientTests) TestGetAuthorByIdErrorIfNoRows() {
 	self.sqlMock.
 		ExpectQuery(getAuthorByIdQueryMatcher).
 		WithArgs(self.author.ID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}))
 
 	result, err := self.client.GetAuthorById(self.context, self.author.ID)
 
-	self.EqualError(err, "no rows in result set")
+	self.ErrorIs(err, models.ErrNotFound)
 	self.Equal(models.Author{}, result)
 }
 
@@ -196,13 +324,13 @@ func (self *DatabaseClientTests) TestGetAuthorByIdErrorIfScanRowFailed() {
 
 	result, err := self.client.GetAuthorById(self.context, self.author.ID)
 
-	self.ErrorContains(err, "failed to scan row")
+	self.ErrorContains(err, "missing destination name not_author_field")
 	self.Equal(models.Author{}, result)
 }
 
 func (self *DatabaseClientTests) TestGetAuthorById() {
-	rows := sqlmock.NewRows([]string{"id", "name"}).
-		AddRow(self.author.ID, self.author.Name)
+	rows := sqlmock.NewRows([]string{"id", "name", "version"}).
+		AddRow(self.author.ID, self.author.Name, self.author.Version)
 	self.sqlMock.
 		ExpectQuery(getAuthorByIdQueryMatcher).
 		WithArgs(self.author.ID).
@@ -214,55 +342,690 @@ func (self *DatabaseClientTests) TestGetAuthorById() {
 	self.Equal(self.author, result)
 }
 
+func (self *DatabaseClientTests) TestUpdateAuthorErrorIfSqlQueryFailed() {
+	self.sqlMock.
+		ExpectQuery(updateAuthorQueryMatcher).
+		WithArgs(self.author.Name, self.author.ID, self.author.Version, self.author.Version).
+		WillReturnError(self.testError)
+
+	result, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Author{}, result)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorErrorIfNoRows() {
+	self.sqlMock.
+		ExpectQuery(updateAuthorQueryMatcher).
+		WithArgs(self.author.Name, self.author.ID, self.author.Version, self.author.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	self.sqlMock.
+		ExpectQuery(getAuthorVersionQueryMatcher).
+		WithArgs(self.author.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+	_, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorOfAnyVersionErrorIfNoRows() {
+	author := self.author
+	author.Version = models.AnyVersion
+	self.sqlMock.
+		ExpectQuery(updateAuthorQueryMatcher).
+		WithArgs(author.Name, author.ID, author.Version, author.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+	_, err := self.client.UpdateAuthor(self.context, author)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorErrorIfVersionMismatch() {
+	self.sqlMock.
+		ExpectQuery(updateAuthorQueryMatcher).
+		WithArgs(self.author.Name, self.author.ID, self.author.Version, self.author.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	self.sqlMock.
+		ExpectQuery(getAuthorVersionQueryMatcher).
+		WithArgs(self.author.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	_, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.Equal(models.VersionMismatchError{Entity: "author"}, err)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthor() {
+	self.sqlMock.
+		ExpectQuery(updateAuthorQueryMatcher).
+		WithArgs(self.author.Name, self.author.ID, self.author.Version, self.author.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	result, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.NoError(err)
+	self.Equal(int64(2), result.Version)
+	self.Equal(self.author.Name, result.Name)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorErrorIfSqlQueryFailed() {
+	self.sqlMock.
+		ExpectQuery(deleteAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Version, self.author.Version).
+		WillReturnError(self.testError)
+
+	result, err := self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version)
+
+	self.EqualError(err, self.testError.Error())
+	self.Zero(result)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorErrorIfNoRows() {
+	self.sqlMock.
+		ExpectQuery(deleteAuthorQueryMatcher).
+		WithArgs(self.author.ID, models.AnyVersion, models.AnyVersion).
+		WillReturnRows(sqlmock.NewRows([]string{"orphaned_books"}))
+
+	result, err := self.client.DeleteAuthor(self.context, self.author.ID, models.AnyVersion)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Zero(result)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorErrorIfVersionMismatch() {
+	self.sqlMock.
+		ExpectQuery(deleteAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Version, self.author.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"orphaned_books"}))
+	self.sqlMock.
+		ExpectQuery(getAuthorVersionQueryMatcher).
+		WithArgs(self.author.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	result, err := self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version)
+
+	self.ErrorIs(err, models.ErrVersionMismatch)
+	self.Zero(result)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorErrorIfScanRowFailed() {
+	rows := sqlmock.NewRows([]string{"orphaned_books"}).AddRow("not_a_number")
+	self.sqlMock.
+		ExpectQuery(deleteAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Version, self.author.Version).
+		WillReturnRows(rows)
+
+	result, err := self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version)
+
+	self.ErrorContains(err, `Scan error on column index 0, name "orphaned_books"`)
+	self.Zero(result)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthor() {
+	rows := sqlmock.NewRows([]string{"orphaned_books"}).AddRow(2)
+	self.sqlMock.
+		ExpectQuery(deleteAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Version, self.author.Version).
+		WillReturnRows(rows)
+
+	result, err := self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version)
+
+	self.NoError(err)
+	self.Equal(int64(2), result)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookErrorIfSqlQueryFailed() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(updateBookQueryMatcher).
+		WithArgs(self.book.Title, self.book.ISBN, self.book.PublicationDate, self.book.Description, self.book.Language,
+			self.book.PageCount, self.book.ID, self.book.Version, self.book.Version, self.contributorAuthorIds(), "{author}").
+		WillReturnError(self.testError)
+	self.sqlMock.ExpectRollback()
+
+	result, err := self.client.UpdateBook(self.context, self.book)
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Book{}, result)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookErrorIfNoRows() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(updateBookQueryMatcher).
+		WithArgs(self.book.Title, self.book.ISBN, self.book.PublicationDate, self.book.Description, self.book.Language,
+			self.book.PageCount, self.book.ID, self.book.Version, self.book.Version, self.contributorAuthorIds(), "{author}").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at", "version", "contributors"}))
+	self.sqlMock.ExpectRollback()
+	self.sqlMock.
+		ExpectQuery(getBookVersionQueryMatcher).
+		WithArgs(self.book.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+	result, err := self.client.UpdateBook(self.context, self.book)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Equal(models.Book{}, result)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookErrorIfVersionMismatch() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(updateBookQueryMatcher).
+		WithArgs(self.book.Title, self.book.ISBN, self.book.PublicationDate, self.book.Description, self.book.Language,
+			self.book.PageCount, self.book.ID, self.book.Version, self.book.Version, self.contributorAuthorIds(), "{author}").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at", "version", "contributors"}))
+	self.sqlMock.ExpectRollback()
+	self.sqlMock.
+		ExpectQuery(getBookVersionQueryMatcher).
+		WithArgs(self.book.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	result, err := self.client.UpdateBook(self.context, self.book)
+
+	self.Equal(models.VersionMismatchError{Entity: "book"}, err)
+	self.Equal(models.Book{}, result)
+}
+
+func (self *DatabaseClientTests) TestUpdateBook() {
+	rows := sqlmock.NewRows([]string{"created_at", "updated_at", "version", "contributors"}).
+		AddRow(self.book.CreatedAt, self.book.UpdatedAt, 2, len(self.book.Contributors))
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(updateBookQueryMatcher).
+		WithArgs(self.book.Title, self.book.ISBN, self.book.PublicationDate, self.book.Description, self.book.Language,
+			self.book.PageCount, self.book.ID, self.book.Version, self.book.Version, self.contributorAuthorIds(), "{author}").
+		WillReturnRows(rows)
+	self.sqlMock.ExpectCommit()
+	expected := self.book
+	expected.Version = 2
+
+	result, err := self.client.UpdateBook(self.context, self.book)
+
+	self.NoError(err)
+	self.Equal(expected, result)
+}
+
+func (self *DatabaseClientTests) TestDeleteBookErrorIfSqlExecFailed() {
+	self.sqlMock.
+		ExpectExec(deleteBookQueryMatcher).
+		WithArgs(self.book.ID, self.book.Version, self.book.Version).
+		WillReturnError(self.testError)
+
+	err := self.client.DeleteBook(self.context, self.book.ID, self.book.Version)
+
+	self.EqualError(err, self.testError.Error())
+}
+
+func (self *DatabaseClientTests) TestDeleteBookErrorIfNoRows() {
+	self.sqlMock.
+		ExpectExec(deleteBookQueryMatcher).
+		WithArgs(self.book.ID, models.AnyVersion, models.AnyVersion).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := self.client.DeleteBook(self.context, self.book.ID, models.AnyVersion)
+
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestDeleteBookErrorIfVersionMismatch() {
+	self.sqlMock.
+		ExpectExec(deleteBookQueryMatcher).
+		WithArgs(self.book.ID, self.book.Version, self.book.Version).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	self.sqlMock.
+		ExpectQuery(getBookVersionQueryMatcher).
+		WithArgs(self.book.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	err := self.client.DeleteBook(self.context, self.book.ID, self.book.Version)
+
+	self.ErrorIs(err, models.ErrVersionMismatch)
+}
+
+func (self *DatabaseClientTests) TestDeleteBook() {
+	self.sqlMock.
+		ExpectExec(deleteBookQueryMatcher).
+		WithArgs(self.book.ID, self.book.Version, self.book.Version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := self.client.DeleteBook(self.context, self.book.ID, self.book.Version)
+
+	self.NoError(err)
+}
+
+func (self *DatabaseClientTests) TestGetBooksByAuthorIdErrorIfInvalidOptions() {
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{Sort: "unknown"})
+
+	self.ErrorIs(err, models.ErrValidation)
+	self.Equal(models.Page[models.Book]{}, result)
+}
+
 func (self *DatabaseClientTests) TestGetBooksByAuthorIdErrorIfSqlQueryFailed() {
 	self.sqlMock.
 		ExpectQuery(getBooksByAuthorIdQueryMatcher).
-		WithArgs(self.author.ID).
+		WithArgs(self.author.ID, models.DefaultPageLimit+1).
 		WillReturnError(self.testError)
 
-	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID)
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
 
 	self.EqualError(err, self.testError.Error())
-	self.Nil(result)
+	self.Equal(models.Page[models.Book]{}, result)
 }
 
 func (self *DatabaseClientTests) TestGetBooksByAuthorIdErrorIfScanRowFailed() {
-	rows := sqlmock.NewRows([]string{"id", "title", "author_id"}).
-		AddRow(self.book.ID, self.book.Title, self.book.Author.ID).
-		AddRow(nil, nil, nil)
+	rows := sqlmock.NewRows(bookColumnNames("sort_value")).
+		AddRow(bookValues(self.book, self.book.Title)...).
+		AddRow(make([]driver.Value, 12)...)
 	self.sqlMock.
 		ExpectQuery(getBooksByAuthorIdQueryMatcher).
-		WithArgs(self.author.ID).
+		WithArgs(self.author.ID, models.DefaultPageLimit+1).
 		WillReturnRows(rows)
 
-	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID)
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
 
-	self.ErrorContains(err, "failed to scan row")
-	self.Nil(result)
+	self.ErrorContains(err, `Scan error on column index 1, name "title"`)
+	self.Equal(models.Page[models.Book]{}, result)
 }
 
-func (self *DatabaseClientTests) TestGetBooksByAuthorIdNilIfNoRows() {
+func (self *DatabaseClientTests) TestGetBooksByAuthorIdErrorIfRowsFailed() {
+	rows := sqlmock.NewRows(bookColumnNames("sort_value")).
+		AddRow(bookValues(self.book, self.book.Title)...).
+		RowError(0, self.testError)
 	self.sqlMock.
 		ExpectQuery(getBooksByAuthorIdQueryMatcher).
-		WithArgs(self.author.ID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id"}))
+		WithArgs(self.author.ID, models.DefaultPageLimit+1).
+		WillReturnRows(rows)
 
-	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID)
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Page[models.Book]{}, result)
+}
+
+func (self *DatabaseClientTests) TestGetBooksByAuthorIdEmptyIfNoRows() {
+	self.sqlMock.
+		ExpectQuery(getBooksByAuthorIdQueryMatcher).
+		WithArgs(self.author.ID, models.DefaultPageLimit+1).
+		WillReturnRows(sqlmock.NewRows(bookColumnNames("sort_value")))
+
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
 
 	self.NoError(err)
-	self.Nil(result)
+	self.Equal(models.Page[models.Book]{}, result)
 }
 
 func (self *DatabaseClientTests) TestGetBooksByAuthor() {
-	rows := sqlmock.NewRows([]string{"id", "title", "author_id"}).
-		AddRow(self.book.ID, self.book.Title, self.book.Author.ID)
+	rows := sqlmock.NewRows(bookColumnNames("sort_value")).
+		AddRow(bookValues(self.book, self.book.Title)...)
+	self.sqlMock.
+		ExpectQuery(getBooksByAuthorIdQueryMatcher).
+		WithArgs(self.author.ID, models.DefaultPageLimit+1).
+		WillReturnRows(rows)
+
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal(models.Page[models.Book]{Items: []models.Book{self.book}}, result)
+}
+
+func (self *DatabaseClientTests) TestGetBooksByAuthorWithNextPage() {
+	nextBook := models.Book{ID: uuid.New(), Title: "test_title_2", Contributors: self.book.Contributors}
+	rows := sqlmock.NewRows(bookColumnNames("sort_value")).
+		AddRow(bookValues(self.book, self.book.Title)...).
+		AddRow(bookValues(nextBook, nextBook.Title)...)
 	self.sqlMock.
 		ExpectQuery(getBooksByAuthorIdQueryMatcher).
+		WithArgs(self.author.ID, 2).
+		WillReturnRows(rows)
+
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{Limit: 1})
+
+	self.NoError(err)
+	self.Equal(models.Page[models.Book]{
+		Items:      []models.Book{self.book},
+		NextCursor: encodeCursor(cursor{Value: self.book.Title, ID: self.book.ID}),
+	}, result)
+}
+
+func (self *DatabaseClientTests) TestGetAuthorsErrorIfSqlQueryFailed() {
+	self.sqlMock.
+		ExpectQuery(getAuthorsQueryMatcher).
+		WithArgs(models.DefaultPageLimit + 1).
+		WillReturnError(self.testError)
+
+	result, err := self.client.GetAuthors(self.context, models.ListOptions{})
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Page[models.Author]{}, result)
+}
+
+func (self *DatabaseClientTests) TestGetAuthors() {
+	rows := sqlmock.NewRows([]string{"id", "name", "version", "sort_value"}).
+		AddRow(self.author.ID, self.author.Name, self.author.Version, self.author.Name)
+	self.sqlMock.
+		ExpectQuery(getAuthorsQueryMatcher).
+		WithArgs(models.DefaultPageLimit + 1).
+		WillReturnRows(rows)
+
+	result, err := self.client.GetAuthors(self.context, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal(models.Page[models.Author]{Items: []models.Author{self.author}}, result)
+}
+
+func (self *DatabaseClientTests) TestGetBooksErrorIfSqlQueryFailed() {
+	self.sqlMock.
+		ExpectQuery(getBooksQueryMatcher).
+		WithArgs(models.DefaultPageLimit + 1).
+		WillReturnError(self.testError)
+
+	result, err := self.client.GetBooks(self.context, models.ListOptions{})
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Page[models.Book]{}, result)
+}
+
+func (self *DatabaseClientTests) TestGetBooks() {
+	rows := sqlmock.NewRows(bookColumnNames("sort_value")).
+		AddRow(bookValues(self.book, self.book.Title)...)
+	self.sqlMock.
+		ExpectQuery(getBooksQueryMatcher).
+		WithArgs(models.DefaultPageLimit + 1).
+		WillReturnRows(rows)
+
+	result, err := self.client.GetBooks(self.context, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal(models.Page[models.Book]{Items: []models.Book{self.book}}, result)
+}
+
+func (self *DatabaseClientTests) TestSearchErrorIfSqlQueryFailed() {
+	self.sqlMock.
+		ExpectQuery(regexp.QuoteMeta(`SELECT type, id, title, snippet, rank, CAST(rank AS text) AS sort_value FROM (SELECT 'book' AS type`)).
+		WithArgs("test_query", "test_query", models.DefaultPageLimit+1).
+		WillReturnError(self.testError)
+
+	result, err := self.client.Search(self.context, models.SearchQuery{Text: "test_query"}, models.ListOptions{})
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Page[models.SearchResult]{}, result)
+}
+
+func (self *DatabaseClientTests) TestSearch() {
+	searchResult := models.SearchResult{
+		Type:    models.SearchResultTypeBook,
+		ID:      self.book.ID,
+		Title:   self.book.Title,
+		Snippet: "<b>test_title</b>",
+		Rank:    0.5,
+	}
+	rows := sqlmock.NewRows([]string{"type", "id", "title", "snippet", "rank", "sort_value"}).
+		AddRow(searchResult.Type, searchResult.ID, searchResult.Title, searchResult.Snippet, searchResult.Rank, "0.5")
+	self.sqlMock.
+		ExpectQuery(regexp.QuoteMeta(`AS results WHERE type=? ORDER BY rank DESC, id DESC LIMIT ?`)).
+		WithArgs("test_query", "test_query", models.SearchResultTypeBook, models.DefaultPageLimit+1).
+		WillReturnRows(rows)
+
+	result, err := self.client.Search(self.context, models.SearchQuery{
+		Text: "test_query",
+		Type: models.SearchResultTypeBook,
+	}, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal(models.Page[models.SearchResult]{Items: []models.SearchResult{searchResult}}, result)
+}
+
+func (self *DatabaseClientTests) TestSearchWithNextPage() {
+	searchResult := models.SearchResult{
+		Type:    models.SearchResultTypeBook,
+		ID:      self.book.ID,
+		Title:   self.book.Title,
+		Snippet: "<b>test_title</b>",
+		Rank:    0.5,
+	}
+	rows := sqlmock.NewRows([]string{"type", "id", "title", "snippet", "rank", "sort_value"}).
+		AddRow(searchResult.Type, searchResult.ID, searchResult.Title, searchResult.Snippet, searchResult.Rank, "0.5").
+		AddRow(models.SearchResultTypeAuthor, self.author.ID, self.author.Name, self.author.Name, 0.25, "0.25")
+	self.sqlMock.
+		ExpectQuery(regexp.QuoteMeta(`AS results ORDER BY rank DESC, id DESC LIMIT ?`)).
+		WithArgs("test_query", "test_query", 2).
+		WillReturnRows(rows)
+
+	result, err := self.client.Search(self.context, models.SearchQuery{Text: "test_query"}, models.ListOptions{Limit: 1})
+
+	self.NoError(err)
+	self.Equal([]models.SearchResult{searchResult}, result.Items)
+	position, err := decodeCursor(result.NextCursor)
+	self.NoError(err)
+	self.Equal(cursor{Value: "0.5", ID: self.book.ID}, position)
+}
+
+func (self *DatabaseClientTests) TestRestoreBookErrorIfNotInTrash() {
+	self.sqlMock.
+		ExpectQuery(restoreBookQueryMatcher).
+		WithArgs(self.book.ID).
+		WillReturnRows(sqlmock.NewRows(bookColumnNames()))
+
+	result, err := self.client.RestoreBook(self.context, self.book.ID)
+
+	self.Equal(models.NotFoundError{Entity: "book"}, err)
+	self.Equal(models.Book{}, result)
+}
+
+func (self *DatabaseClientTests) TestRestoreBook() {
+	self.book.Version++
+	self.sqlMock.
+		ExpectQuery(restoreBookQueryMatcher).
+		WithArgs(self.book.ID).
+		WillReturnRows(sqlmock.NewRows(bookColumnNames()).AddRow(bookValues(self.book)...))
+
+	result, err := self.client.RestoreBook(self.context, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(self.book, result)
+}
+
+func (self *DatabaseClientTests) TestRestoreAuthorErrorIfNotInTrash() {
+	self.sqlMock.
+		ExpectQuery(restoreAuthorQueryMatcher).
+		WithArgs(self.author.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}))
+
+	result, err := self.client.RestoreAuthor(self.context, self.author.ID)
+
+	self.Equal(models.NotFoundError{Entity: "author"}, err)
+	self.Equal(models.Author{}, result)
+}
+
+func (self *DatabaseClientTests) TestRestoreAuthor() {
+	self.author.Version++
+	self.sqlMock.
+		ExpectQuery(restoreAuthorQueryMatcher).
+		WithArgs(self.author.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}).
+			AddRow(self.author.ID, self.author.Name, self.author.Version))
+
+	result, err := self.client.RestoreAuthor(self.context, self.author.ID)
+
+	self.NoError(err)
+	self.Equal(self.author, result)
+}
+
+func (self *DatabaseClientTests) TestGetTrashErrorIfSqlQueryFailed() {
+	self.sqlMock.
+		ExpectQuery(getTrashQueryMatcher).
+		WithArgs(models.DefaultPageLimit + 1).
+		WillReturnError(self.testError)
+
+	result, err := self.client.GetTrash(self.context, models.TrashQuery{}, models.ListOptions{})
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(models.Page[models.TrashItem]{}, result)
+}
+
+func (self *DatabaseClientTests) TestGetTrash() {
+	item := models.TrashItem{
+		Type:      models.TrashItemTypeAuthor,
+		ID:        self.author.ID,
+		Title:     self.author.Name,
+		DeletedAt: time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC),
+	}
+	rows := sqlmock.NewRows([]string{"type", "id", "title", "deleted_at", "sort_value"}).
+		AddRow(item.Type, item.ID, item.Title, item.DeletedAt, "2022-10-03 00:00:00+00")
+	self.sqlMock.
+		ExpectQuery(regexp.QuoteMeta(`AS items WHERE type=? ORDER BY deleted_at DESC, id DESC LIMIT ?`)).
+		WithArgs(models.TrashItemTypeAuthor, models.DefaultPageLimit+1).
+		WillReturnRows(rows)
+
+	result, err := self.client.GetTrash(self.context, models.TrashQuery{Type: models.TrashItemTypeAuthor}, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal(models.Page[models.TrashItem]{Items: []models.TrashItem{item}}, result)
+}
+
+func (self *DatabaseClientTests) TestPurgeTrashErrorIfSqlQueryFailed() {
+	deletedBefore := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	self.sqlMock.
+		ExpectQuery(purgeTrashQueryMatcher).
+		WithArgs(deletedBefore, deletedBefore).
+		WillReturnError(self.testError)
+
+	result, err := self.client.PurgeTrash(self.context, deletedBefore)
+
+	self.EqualError(err, self.testError.Error())
+	self.Equal(int64(0), result)
+}
+
+func (self *DatabaseClientTests) TestPurgeTrash() {
+	deletedBefore := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	self.sqlMock.
+		ExpectQuery(purgeTrashQueryMatcher).
+		WithArgs(deletedBefore, deletedBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"purged"}).AddRow(3))
+
+	result, err := self.client.PurgeTrash(self.context, deletedBefore)
+
+	self.NoError(err)
+	self.Equal(int64(3), result)
+}
+
+func (self *DatabaseClientTests) TestGetBookByIdErrorIsNotFoundError() {
+	self.sqlMock.
+		ExpectQuery(getBookByIdQueryMatcher).
+		WithArgs(self.book.ID).
+		WillReturnRows(sqlmock.NewRows(bookColumnNames()))
+
+	_, err := self.client.GetBookById(self.context, self.book.ID)
+
+	var notFoundError models.NotFoundError
+	self.Require().ErrorAs(err, &notFoundError)
+	self.Equal(models.NotFoundError{Entity: "book"}, notFoundError)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorErrorIsNotFoundError() {
+	self.sqlMock.
+		ExpectQuery(deleteAuthorQueryMatcher).
+		WithArgs(self.author.ID, models.AnyVersion, models.AnyVersion).
+		WillReturnRows(sqlmock.NewRows([]string{"orphaned_books"}))
+
+	_, err := self.client.DeleteAuthor(self.context, self.author.ID, models.AnyVersion)
+
+	var notFoundError models.NotFoundError
+	self.Require().ErrorAs(err, &notFoundError)
+	self.Equal(models.NotFoundError{Entity: "author"}, notFoundError)
+}
+
+func (self *DatabaseClientTests) TestGetBookByIdClosesRows() {
+	self.sqlMock.
+		ExpectQuery(getBookByIdQueryMatcher).
+		WithArgs(self.book.ID).
+		WillReturnRows(sqlmock.NewRows(bookColumnNames()).AddRow(bookValues(self.book)...)).
+		RowsWillBeClosed()
+
+	_, err := self.client.GetBookById(self.context, self.book.ID)
+
+	self.NoError(err)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestGetAuthorByIdClosesRows() {
+	self.sqlMock.
+		ExpectQuery(getAuthorByIdQueryMatcher).
+		WithArgs(self.author.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}).AddRow(self.author.ID, self.author.Name, self.author.Version)).
+		RowsWillBeClosed()
+
+	_, err := self.client.GetAuthorById(self.context, self.author.ID)
+
+	self.NoError(err)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestGetAuthorByIdClosesRowsIfScanRowFailed() {
+	self.sqlMock.
+		ExpectQuery(getAuthorByIdQueryMatcher).
+		WithArgs(self.author.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"not_author_field"}).AddRow(true)).
+		RowsWillBeClosed()
+
+	_, err := self.client.GetAuthorById(self.context, self.author.ID)
+
+	self.Error(err)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestGetAuthorByIdErrorIfRowsFailed() {
+	rows := sqlmock.NewRows([]string{"id", "name", "version"}).
+		AddRow(self.author.ID, self.author.Name, self.author.Version).
+		RowError(0, self.testError)
+	self.sqlMock.
+		ExpectQuery(getAuthorByIdQueryMatcher).
 		WithArgs(self.author.ID).
 		WillReturnRows(rows)
 
-	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID)
+	result, err := self.client.GetAuthorById(self.context, self.author.ID)
+
+	self.ErrorIs(err, self.testError)
+	self.False(errors.Is(err, models.ErrNotFound))
+	self.Equal(models.Author{}, result)
+}
+
+func (self *DatabaseClientTests) TestGetBooksByAuthorIdClosesRows() {
+	nextBook := models.Book{ID: uuid.New(), Title: "test_title_2", Contributors: self.book.Contributors}
+	self.sqlMock.
+		ExpectQuery(getBooksByAuthorIdQueryMatcher).
+		WithArgs(self.author.ID, 2).
+		WillReturnRows(sqlmock.NewRows(bookColumnNames("sort_value")).
+			AddRow(bookValues(self.book, self.book.Title)...).
+			AddRow(bookValues(nextBook, nextBook.Title)...)).
+		RowsWillBeClosed()
+
+	_, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{Limit: 1})
 
 	self.NoError(err)
-	self.Equal([]models.Book{self.book}, result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestGetBooksByAuthorIdErrorIfRowsFailedMidStream() {
+	nextBook := models.Book{ID: uuid.New(), Title: "test_title_2", Contributors: self.book.Contributors}
+	rows := sqlmock.NewRows(bookColumnNames("sort_value")).
+		AddRow(bookValues(self.book, self.book.Title)...).
+		AddRow(bookValues(nextBook, nextBook.Title)...).
+		RowError(1, self.testError)
+	self.sqlMock.
+		ExpectQuery(getBooksByAuthorIdQueryMatcher).
+		WithArgs(self.author.ID, models.DefaultPageLimit+1).
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+
+	result, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
+
+	self.ErrorIs(err, self.testError)
+	self.Equal(models.Page[models.Book]{}, result)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
 }
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	foreignKeyViolationCode = "23503"
+	uniqueViolationCode     = "23505"
+)
+
+// translateError converts constraint violations reported by PostgreSQL into the domain errors.
+func translateError(err error) error {
+	var pgError pgx.PgError
+	if !errors.As(err, &pgError) {
+		return err
+	}
+
+	switch pgError.Code {
+	case uniqueViolationCode:
+		return fmt.Errorf("%w: %s", models.ErrConflict, pgError.Message)
+	case foreignKeyViolationCode:
+		return fmt.Errorf("%w: %s", models.ErrValidation, pgError.Message)
+	default:
+		return err
+	}
+}
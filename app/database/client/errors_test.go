@@ -0,0 +1,41 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+func TestTranslateErrorUniqueViolation(t *testing.T) {
+	err := translateError(pgx.PgError{Code: uniqueViolationCode, Message: "test_message"})
+
+	assert.ErrorIs(t, err, models.ErrConflict)
+	assert.ErrorContains(t, err, "test_message")
+}
+
+func TestTranslateErrorForeignKeyViolation(t *testing.T) {
+	err := translateError(pgx.PgError{Code: foreignKeyViolationCode, Message: "test_message"})
+
+	assert.ErrorIs(t, err, models.ErrValidation)
+	assert.ErrorContains(t, err, "test_message")
+}
+
+func TestTranslateErrorOtherPgError(t *testing.T) {
+	pgError := pgx.PgError{Code: "42P01"}
+
+	err := translateError(pgError)
+
+	assert.Equal(t, pgError, err)
+}
+
+func TestTranslateErrorNotPgError(t *testing.T) {
+	testError := errors.New("test_error")
+
+	err := translateError(testError)
+
+	assert.Equal(t, testError, err)
+}
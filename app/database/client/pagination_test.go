@@ -0,0 +1,104 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+type PageQueryTests struct {
+	suite.Suite
+	query pageQuery
+}
+
+func TestPageQuery(t *testing.T) {
+	suite.Run(t, new(PageQueryTests))
+}
+
+func (self *PageQueryTests) SetupTest() {
+	self.query = pageQuery{
+		table:        "books",
+		columns:      "id, title",
+		prefixColumn: "title",
+		conditions:   []string{"author_id=:author_id"},
+		sortOrders:   bookSortOrders,
+	}
+}
+
+func (self *PageQueryTests) TestBuildDefault() {
+	query, arguments, err := self.query.build(models.ListOptions{}, pageArguments{})
+
+	self.NoError(err)
+	self.Equal("SELECT id, title, CAST(title AS text) AS sort_value FROM books WHERE author_id=:author_id ORDER BY title ASC, id ASC LIMIT :limit", query)
+	self.Equal(pageArguments{Limit: models.DefaultPageLimit + 1}, arguments)
+}
+
+func (self *PageQueryTests) TestBuildWithCursorAndPrefix() {
+	position := cursor{Sort: "-title", Value: "test_title", ID: uuid.New()}
+
+	query, arguments, err := self.query.build(models.ListOptions{
+		Limit:  5,
+		Cursor: encodeCursor(position),
+		Sort:   "-title",
+		Prefix: "100%_",
+	}, pageArguments{})
+
+	self.NoError(err)
+	self.Equal("SELECT id, title, CAST(title AS text) AS sort_value FROM books "+
+		"WHERE author_id=:author_id AND title LIKE :prefix AND (title, id) < (:cursor_value, :cursor_id) "+
+		"ORDER BY title DESC, id DESC LIMIT :limit", query)
+	self.Equal(pageArguments{
+		Prefix:      `100\%\_%`,
+		CursorValue: position.Value,
+		CursorId:    position.ID,
+		Limit:       6,
+	}, arguments)
+}
+
+func (self *PageQueryTests) TestBuildWithCreatedAtCursor() {
+	position := cursor{Sort: "created_at", Value: "2022-10-01 10:00:00+00", ID: uuid.New()}
+
+	query, _, err := self.query.build(models.ListOptions{Cursor: encodeCursor(position), Sort: "created_at"}, pageArguments{})
+
+	self.NoError(err)
+	self.Contains(query, "(created_at, id) > (CAST(:cursor_value AS timestamptz), :cursor_id)")
+	self.Contains(query, "ORDER BY created_at ASC, id ASC")
+}
+
+func (self *PageQueryTests) TestBuildErrorIfSortNotSupported() {
+	_, _, err := self.query.build(models.ListOptions{Sort: "name"}, pageArguments{})
+
+	self.ErrorIs(err, models.ErrValidation)
+}
+
+func (self *PageQueryTests) TestBuildErrorIfLimitTooBig() {
+	_, _, err := self.query.build(models.ListOptions{Limit: models.MaxPageLimit + 1}, pageArguments{})
+
+	self.ErrorIs(err, models.ErrValidation)
+}
+
+func (self *PageQueryTests) TestBuildErrorIfCursorInvalid() {
+	_, _, err := self.query.build(models.ListOptions{Cursor: "not_cursor"}, pageArguments{})
+
+	self.ErrorIs(err, models.ErrValidation)
+}
+
+func (self *PageQueryTests) TestBuildErrorIfCursorOfOtherSort() {
+	position := cursor{Sort: "title", Value: "test_title", ID: uuid.New()}
+
+	_, _, err := self.query.build(models.ListOptions{Cursor: encodeCursor(position), Sort: "-title"}, pageArguments{})
+
+	self.ErrorIs(err, models.ErrValidation)
+}
+
+func (self *PageQueryTests) TestEncodeDecodeCursor() {
+	position := cursor{Sort: "title", Value: "test_title", ID: uuid.New()}
+
+	result, err := decodeCursor(encodeCursor(position))
+
+	self.NoError(err)
+	self.Equal(position, result)
+}
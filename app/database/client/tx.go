@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx"
+
+	"github.com/egormizerov/books/app/services"
+)
+
+// PostgreSQL error code of the transaction failed to serialize with the concurrent ones.
+const serializationFailureCode = "40001"
+
+// Isolation levels supported by PostgreSQL.
+var isolationLevels = []sql.IsolationLevel{
+	sql.LevelDefault,
+	sql.LevelReadUncommitted,
+	sql.LevelReadCommitted,
+	sql.LevelRepeatableRead,
+	sql.LevelSerializable,
+}
+
+// TxOptions configure the transactions run by WithTx.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	// MaxAttempts limits the number of times the transaction is run if it fails to serialize.
+	MaxAttempts int
+}
+
+// ParseIsolationLevel returns the isolation level by its name such as "read committed", the case is ignored.
+func ParseIsolationLevel(name string) (sql.IsolationLevel, error) {
+	for _, level := range isolationLevels {
+		if strings.EqualFold(level.String(), name) {
+			return level, nil
+		}
+	}
+	return sql.LevelDefault, fmt.Errorf("unsupported isolation level %q", name)
+}
+
+// WithTx runs the unit of work in the transaction, it is committed if the unit of work succeeds and
+// rolled back if it fails or panics. The transaction failed to serialize is run again up to
+// TxOptions.MaxAttempts times. WithTx called inside the unit of work joins the running transaction.
+func (self *DatabaseClient) WithTx(ctx context.Context, unitOfWork func(tx services.DatabaseClient) error) error {
+	return self.withTx(ctx, func(tx *DatabaseClient) error {
+		return unitOfWork(tx)
+	})
+}
+
+// withTx is WithTx passing the client of the transaction to the statements of this package.
+func (self *DatabaseClient) withTx(ctx context.Context, unitOfWork func(tx *DatabaseClient) error) error {
+	if self.conn == nil {
+		return unitOfWork(self)
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = self.runTx(ctx, unitOfWork)
+		if attempt >= self.txOptions.MaxAttempts || !isSerializationFailure(err) {
+			return err
+		}
+	}
+}
+
+func (self *DatabaseClient) runTx(ctx context.Context, unitOfWork func(tx *DatabaseClient) error) error {
+	tx, err := self.conn.BeginTxx(ctx, &sql.TxOptions{Isolation: self.txOptions.Isolation})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = unitOfWork(&DatabaseClient{db: tx, txOptions: self.txOptions}); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return nil
+}
+
+func isSerializationFailure(err error) bool {
+	var pgError pgx.PgError
+	return errors.As(err, &pgError) && pgError.Code == serializationFailureCode
+}
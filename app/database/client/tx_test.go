@@ -0,0 +1,161 @@
+package client
+
+import (
+	"database/sql"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx"
+
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/app/services"
+)
+
+func (self *DatabaseClientTests) TestWithTxCommitsUnitOfWork() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Name).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(models.FirstVersion))
+	self.sqlMock.ExpectCommit()
+
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		_, err := tx.CreateAuthor(self.context, self.author)
+		return err
+	})
+
+	self.NoError(err)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestWithTxRollsBackIfUnitOfWorkFailed() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.ExpectRollback()
+
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		return self.testError
+	})
+
+	self.ErrorIs(err, self.testError)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestWithTxRollsBackIfUnitOfWorkPanicked() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.ExpectRollback()
+
+	self.PanicsWithValue("test_panic", func() {
+		_ = self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+			panic("test_panic")
+		})
+	})
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestWithTxErrorIfBeginFailed() {
+	self.sqlMock.ExpectBegin().WillReturnError(self.testError)
+
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		self.Fail("unit of work must not run")
+		return nil
+	})
+
+	self.ErrorIs(err, self.testError)
+}
+
+func (self *DatabaseClientTests) TestWithTxErrorIfCommitFailed() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.ExpectCommit().WillReturnError(self.testError)
+
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		return nil
+	})
+
+	self.ErrorIs(err, self.testError)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestWithTxRetriesSerializationFailure() {
+	serializationFailure := pgx.PgError{Code: serializationFailureCode}
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Name).
+		WillReturnError(serializationFailure)
+	self.sqlMock.ExpectRollback()
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Name).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(models.FirstVersion))
+	self.sqlMock.ExpectCommit()
+
+	attempts := 0
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		attempts++
+		_, err := tx.CreateAuthor(self.context, self.author)
+		return err
+	})
+
+	self.NoError(err)
+	self.Equal(2, attempts)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestWithTxErrorIfSerializationFailureExhaustedAttempts() {
+	serializationFailure := pgx.PgError{Code: serializationFailureCode}
+	for attempt := 0; attempt < 2; attempt++ {
+		self.sqlMock.ExpectBegin()
+		self.sqlMock.ExpectRollback()
+	}
+
+	attempts := 0
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		attempts++
+		return serializationFailure
+	})
+
+	self.ErrorIs(err, serializationFailure)
+	self.Equal(2, attempts)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestWithTxJoinsRunningTransaction() {
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.
+		ExpectQuery(createAuthorQueryMatcher).
+		WithArgs(self.author.ID, self.author.Name).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(models.FirstVersion))
+	self.sqlMock.ExpectCommit()
+
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		return tx.WithTx(self.context, func(nested services.DatabaseClient) error {
+			self.Same(tx, nested)
+			_, err := nested.CreateAuthor(self.context, self.author)
+			return err
+		})
+	})
+
+	self.NoError(err)
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *DatabaseClientTests) TestParseIsolationLevel() {
+	for name, expected := range map[string]sql.IsolationLevel{
+		"Default":          sql.LevelDefault,
+		"read committed":   sql.LevelReadCommitted,
+		"Repeatable Read":  sql.LevelRepeatableRead,
+		"SERIALIZABLE":     sql.LevelSerializable,
+		"Read Uncommitted": sql.LevelReadUncommitted,
+	} {
+		result, err := ParseIsolationLevel(name)
+
+		self.NoError(err, name)
+		self.Equal(expected, result, name)
+	}
+}
+
+func (self *DatabaseClientTests) TestParseIsolationLevelErrorIfUnsupported() {
+	_, err := ParseIsolationLevel("Linearizable")
+
+	self.ErrorContains(err, `unsupported isolation level "Linearizable"`)
+}
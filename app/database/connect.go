@@ -2,32 +2,164 @@ package database
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
 	_ "github.com/jackc/pgx/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite"
 
+	"github.com/egormizerov/books/pkg/wait"
 	"github.com/egormizerov/books/pkg/wrappers"
 )
 
+// Fraction of the delay between the connection attempts randomly subtracted from it.
+const connectRetryJitter = 0.5
+
+// Drivers of the supported databases.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Names of the database/sql drivers of the supported databases.
+var sqlDriverNames = map[string]string{
+	DriverPostgres: "pgx",
+	DriverSQLite:   "sqlite",
+}
+
+// Options of the SQLite connections: the foreign keys are enforced, the writers wait for each other,
+// the transactions take the write lock at once and the time is written in the format parsed back.
+var sqliteOptions = url.Values{
+	"_pragma":      {"foreign_keys(1)", "busy_timeout(5000)"},
+	"_txlock":      {"immediate"},
+	"_time_format": {"sqlite"},
+}
+
 type ConnectConfig struct {
+	// Driver is DriverPostgres or DriverSQLite, PostgreSQL is used if it is empty.
+	Driver string
+	// URL is the full connection URL, the connection options below are ignored if it is set.
+	URL string
+	// SQLitePath is the path of the SQLite database file or ":memory:", the options below apply to PostgreSQL only.
+	SQLitePath string
+
 	User     string
 	Password string
 	Host     string
 	Port     string
 	Database string
+
+	// SSLMode is one of disable, allow, prefer, require, verify-ca and verify-full.
+	SSLMode string
+	// SSLRootCert is the path of the certificate of the authority verifying the server certificate.
+	SSLRootCert     string
+	ApplicationName string
+	SearchPath      string
+	// StatementTimeout aborts the statements running longer, it is not limited if it is zero.
+	StatementTimeout time.Duration
+
+	// Settings of the connection pool, zero values have the meaning of the database/sql setters:
+	// unlimited open connections and lifetimes and no idle connections.
+	MaxOpenConnections    int
+	MaxIdleConnections    int
+	ConnectionMaxLifetime time.Duration
+	ConnectionMaxIdleTime time.Duration
+
+	// ConnectTimeout limits the time spent retrying to connect, the connection is attempted once if it is zero.
+	ConnectTimeout time.Duration
+	// Delays between the connection attempts grow exponentially from the initial to the max interval.
+	ConnectRetryInitialInterval time.Duration
+	ConnectRetryMaxInterval     time.Duration
 }
 
-func ConnectToDatabase(sqlxWrapper wrappers.SqlxWrapper, config ConnectConfig) (*sqlx.DB, error) {
+func ConnectToDatabase(logger logrus.FieldLogger, sqlxWrapper wrappers.SqlxWrapper, config ConnectConfig) (*sqlx.DB, error) {
+	if config.Driver == "" {
+		config.Driver = DriverPostgres
+	}
+	driverName, ok := sqlDriverNames[config.Driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver %q", config.Driver)
+	}
+	if config.Driver == DriverSQLite {
+		// SQLite has the single writer and every connection to ":memory:" opens the separate database,
+		// so the pool keeps the only connection open.
+		config.MaxOpenConnections, config.MaxIdleConnections = 1, 1
+		config.ConnectionMaxLifetime, config.ConnectionMaxIdleTime = 0, 0
+	}
+
 	url := getConnectionUrl(config)
-	conn, err := sqlxWrapper.Connect("pgx", url)
+	backoff := wait.ExponentialBackoff{
+		Initial: config.ConnectRetryInitialInterval,
+		Max:     config.ConnectRetryMaxInterval,
+		Jitter:  connectRetryJitter,
+	}
+
+	var conn *sqlx.DB
+	var err error
+	attempt := 0
+	wait.WaitUntilWithBackoff(func() bool {
+		attempt++
+		conn, err = sqlxWrapper.Connect(driverName, url)
+		if err != nil {
+			logger.
+				WithField("attempt", attempt).
+				WithError(err).
+				Warn("failed to connect to database")
+		}
+		return err == nil
+	}, time.Now().Add(config.ConnectTimeout), backoff)
 	if err != nil {
 		return nil, err
 	}
 
+	conn.SetMaxOpenConns(config.MaxOpenConnections)
+	conn.SetMaxIdleConns(config.MaxIdleConnections)
+	conn.SetConnMaxLifetime(config.ConnectionMaxLifetime)
+	conn.SetConnMaxIdleTime(config.ConnectionMaxIdleTime)
+
 	return conn, nil
 }
 
+// getConnectionUrl returns the URL with the escaped credentials and database name and the options
+// as the query parameters, the SQLite one is the path of the database with its options.
 func getConnectionUrl(config ConnectConfig) string {
-	return fmt.Sprintf("postgresql://%s:%s@%s:%s/%s",
-		config.User, config.Password, config.Host, config.Port, config.Database)
+	if config.URL != "" {
+		return config.URL
+	}
+	if config.Driver == DriverSQLite {
+		return config.SQLitePath + "?" + sqliteOptions.Encode()
+	}
+
+	host := config.Host
+	if config.Port != "" {
+		host = net.JoinHostPort(config.Host, config.Port)
+	}
+	query := url.Values{}
+	setQueryValue(query, "sslmode", config.SSLMode)
+	setQueryValue(query, "sslrootcert", config.SSLRootCert)
+	setQueryValue(query, "application_name", config.ApplicationName)
+	setQueryValue(query, "search_path", config.SearchPath)
+	if config.StatementTimeout > 0 {
+		query.Set("statement_timeout", strconv.FormatInt(config.StatementTimeout.Milliseconds(), 10))
+	}
+
+	connectionUrl := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(config.User, config.Password),
+		Host:     host,
+		Path:     "/" + config.Database,
+		RawPath:  "/" + url.PathEscape(config.Database),
+		RawQuery: query.Encode(),
+	}
+	return connectionUrl.String()
+}
+
+func setQueryValue(query url.Values, key string, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
 }
@@ -0,0 +1,867 @@
+// Package contract describes the behavior expected from every implementation of services.DatabaseClient
+// as the test suite run by the tests of the implementations.
+package contract
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/app/services"
+)
+
+// DatabaseClientTests is the suite every database client must pass. The titles and the names of the suite
+// are ordered the same by the byte-wise and the collation-aware comparison.
+type DatabaseClientTests struct {
+	suite.Suite
+	// NewClient returns the client of the empty database, it is called before every test.
+	NewClient func() services.DatabaseClient
+
+	client    services.DatabaseClient
+	context   context.Context
+	testError error
+	author    models.Author
+	coauthor  models.Author
+	book      models.Book
+}
+
+func (self *DatabaseClientTests) SetupTest() {
+	self.client = self.NewClient()
+	self.context = context.Background()
+	self.testError = errors.New("test_error")
+	var err error
+	self.author, err = self.client.CreateAuthor(self.context, models.Author{ID: uuid.New(), Name: "alice walker"})
+	self.Require().NoError(err)
+	self.coauthor, err = self.client.CreateAuthor(self.context, models.Author{ID: uuid.New(), Name: "bob dylan"})
+	self.Require().NoError(err)
+	self.book = self.newBook("color purple", self.author, self.coauthor)
+	self.book.ISBN = "9780151191543"
+}
+
+// newBook returns the book written by the authors in the order.
+func (self *DatabaseClientTests) newBook(title string, authors ...models.Author) models.Book {
+	book := models.Book{ID: uuid.New(), Title: title, Language: "en", PageCount: 288}
+	for _, author := range authors {
+		book.Contributors = append(book.Contributors, contributor(author, models.ContributorRoleAuthor))
+	}
+	return book
+}
+
+// contributor returns the contributor as it is read with the book, without the version of the author.
+func contributor(author models.Author, role string) models.Contributor {
+	return models.Contributor{Author: models.Author{ID: author.ID, Name: author.Name}, Role: role}
+}
+
+// createBooks creates the books with the titles written by the author.
+func (self *DatabaseClientTests) createBooks(titles ...string) []models.Book {
+	var books []models.Book
+	for _, title := range titles {
+		book, err := self.client.CreateBook(self.context, self.newBook(title, self.author))
+		self.Require().NoError(err)
+		books = append(books, book)
+	}
+	return books
+}
+
+// assertBook asserts the books are equal, the timestamps are compared as instants.
+func (self *DatabaseClientTests) assertBook(expected models.Book, actual models.Book) {
+	self.True(expected.CreatedAt.Equal(actual.CreatedAt), "created at %s, expected %s", actual.CreatedAt, expected.CreatedAt)
+	self.True(expected.UpdatedAt.Equal(actual.UpdatedAt), "updated at %s, expected %s", actual.UpdatedAt, expected.UpdatedAt)
+	expected.CreatedAt, expected.UpdatedAt = actual.CreatedAt, actual.UpdatedAt
+	self.Equal(expected, actual)
+}
+
+func titles(books []models.Book) []string {
+	var titles []string
+	for _, book := range books {
+		titles = append(titles, book.Title)
+	}
+	return titles
+}
+
+func (self *DatabaseClientTests) TestGetAuthorById() {
+	author, err := self.client.GetAuthorById(self.context, self.author.ID)
+
+	self.NoError(err)
+	self.Equal(self.author, author)
+}
+
+func (self *DatabaseClientTests) TestGetAuthorByIdNotFound() {
+	_, err := self.client.GetAuthorById(self.context, uuid.New())
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Equal(models.NotFoundError{Entity: "author"}, err)
+}
+
+func (self *DatabaseClientTests) TestCreateAuthor() {
+	self.Equal(models.FirstVersion, self.author.Version)
+}
+
+func (self *DatabaseClientTests) TestCreateAuthorConflictIfNameExists() {
+	_, err := self.client.CreateAuthor(self.context, models.Author{ID: uuid.New(), Name: self.author.Name})
+
+	self.ErrorIs(err, models.ErrConflict)
+}
+
+func (self *DatabaseClientTests) TestCreateAuthorConflictIfIdExists() {
+	_, err := self.client.CreateAuthor(self.context, models.Author{ID: self.author.ID, Name: "carol shields"})
+
+	self.ErrorIs(err, models.ErrConflict)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthor() {
+	self.author.Name = "alice munro"
+
+	updated, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.NoError(err)
+	self.Equal(models.FirstVersion+1, updated.Version)
+	author, err := self.client.GetAuthorById(self.context, self.author.ID)
+	self.NoError(err)
+	self.Equal(updated, author)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorKeepingName() {
+	_, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.NoError(err)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorOfAnyVersion() {
+	self.author.Version = models.AnyVersion
+
+	updated, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.NoError(err)
+	self.Equal(models.FirstVersion+1, updated.Version)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorVersionMismatch() {
+	updated := self.author
+	updated.Name = "alice munro"
+	updated.Version++
+
+	_, err := self.client.UpdateAuthor(self.context, updated)
+
+	self.Equal(models.VersionMismatchError{Entity: "author"}, err)
+	author, err := self.client.GetAuthorById(self.context, self.author.ID)
+	self.NoError(err)
+	self.Equal(self.author, author)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorIncrementsVersionsOfBooks() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.author.Name = "alice munro"
+
+	_, err = self.client.UpdateAuthor(self.context, self.author)
+
+	self.NoError(err)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.Equal(created.Version+1, book.Version)
+	self.False(book.UpdatedAt.Before(created.UpdatedAt))
+	self.Equal(contributor(self.author, models.ContributorRoleAuthor), book.Contributors[0])
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorConflictIfNameExists() {
+	self.author.Name = self.coauthor.Name
+
+	_, err := self.client.UpdateAuthor(self.context, self.author)
+
+	self.ErrorIs(err, models.ErrConflict)
+}
+
+func (self *DatabaseClientTests) TestUpdateAuthorNotFound() {
+	for _, version := range []int64{models.AnyVersion, models.FirstVersion} {
+		_, err := self.client.UpdateAuthor(self.context, models.Author{ID: uuid.New(), Name: "carol shields", Version: version})
+
+		self.Equal(models.NotFoundError{Entity: "author"}, err, "version %d", version)
+	}
+}
+
+func (self *DatabaseClientTests) TestCreateBook() {
+	created, err := self.client.CreateBook(self.context, self.book)
+
+	self.NoError(err)
+	self.False(created.CreatedAt.IsZero())
+	self.True(created.CreatedAt.Equal(created.UpdatedAt))
+	self.Equal(models.FirstVersion, created.Version)
+
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.assertBook(created, book)
+}
+
+func (self *DatabaseClientTests) TestCreateBookWithoutContributors() {
+	self.book.Contributors = nil
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.NoError(err)
+
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.assertBook(created, book)
+}
+
+func (self *DatabaseClientTests) TestCreateBookValidationErrorIfAuthorNotExists() {
+	self.book.Contributors = append(self.book.Contributors, models.Contributor{
+		Author: models.Author{ID: uuid.New()},
+		Role:   models.ContributorRoleEditor,
+	})
+
+	_, err := self.client.CreateBook(self.context, self.book)
+
+	self.ErrorIs(err, models.ErrValidation)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestCreateBookConflictIfISBNExists() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	other := self.newBook("meridian", self.author)
+	other.ISBN = self.book.ISBN
+
+	_, err = self.client.CreateBook(self.context, other)
+
+	self.ErrorIs(err, models.ErrConflict)
+}
+
+func (self *DatabaseClientTests) TestCreateBooksWithoutISBN() {
+	_, err := self.client.CreateBook(self.context, self.newBook("meridian", self.author))
+	self.NoError(err)
+	_, err = self.client.CreateBook(self.context, self.newBook("in love and trouble", self.author))
+	self.NoError(err)
+}
+
+func (self *DatabaseClientTests) TestGetBookByISBN() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	book, err := self.client.GetBookByISBN(self.context, self.book.ISBN)
+
+	self.NoError(err)
+	self.assertBook(created, book)
+}
+
+func (self *DatabaseClientTests) TestGetBookByISBNNotFound() {
+	_, err := self.client.GetBookByISBN(self.context, self.book.ISBN)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Equal(models.NotFoundError{Entity: "book"}, err)
+}
+
+func (self *DatabaseClientTests) TestGetBookByIdNotFound() {
+	_, err := self.client.GetBookById(self.context, uuid.New())
+
+	self.Equal(models.NotFoundError{Entity: "book"}, err)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookReplacesContributors() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.book.Title = "the color purple"
+	self.book.Contributors = []models.Contributor{
+		contributor(self.coauthor, models.ContributorRoleAuthor),
+		contributor(self.author, models.ContributorRoleEditor),
+	}
+
+	updated, err := self.client.UpdateBook(self.context, self.book)
+
+	self.NoError(err)
+	self.True(created.CreatedAt.Equal(updated.CreatedAt))
+	self.False(updated.UpdatedAt.Before(created.UpdatedAt))
+	self.Equal(created.Version+1, updated.Version)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.assertBook(updated, book)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookOfExpectedVersion() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	created.Title = "the color purple"
+
+	updated, err := self.client.UpdateBook(self.context, created)
+
+	self.NoError(err)
+	self.Equal(created.Version+1, updated.Version)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.assertBook(updated, book)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookVersionMismatch() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	updated := created
+	updated.Title = "the color purple"
+	updated.Contributors = nil
+	updated.Version++
+
+	_, err = self.client.UpdateBook(self.context, updated)
+
+	self.Equal(models.VersionMismatchError{Entity: "book"}, err)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.assertBook(created, book)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookNotFound() {
+	for _, version := range []int64{models.AnyVersion, models.FirstVersion} {
+		self.book.Version = version
+
+		_, err := self.client.UpdateBook(self.context, self.book)
+
+		self.Equal(models.NotFoundError{Entity: "book"}, err, "version %d", version)
+	}
+}
+
+func (self *DatabaseClientTests) TestUpdateBookConflictIfISBNExists() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	other, err := self.client.CreateBook(self.context, self.newBook("meridian", self.author))
+	self.Require().NoError(err)
+	other.ISBN = self.book.ISBN
+
+	_, err = self.client.UpdateBook(self.context, other)
+
+	self.ErrorIs(err, models.ErrConflict)
+}
+
+func (self *DatabaseClientTests) TestUpdateBookValidationErrorIfAuthorNotExists() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.book.Contributors = []models.Contributor{{Author: models.Author{ID: uuid.New()}, Role: models.ContributorRoleAuthor}}
+
+	_, err = self.client.UpdateBook(self.context, self.book)
+
+	self.ErrorIs(err, models.ErrValidation)
+}
+
+func (self *DatabaseClientTests) TestDeleteBook() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	self.NoError(self.client.DeleteBook(self.context, self.book.ID, models.AnyVersion))
+
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestDeleteBookOfExpectedVersion() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	self.NoError(self.client.DeleteBook(self.context, self.book.ID, created.Version))
+
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestDeleteBookVersionMismatch() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	err = self.client.DeleteBook(self.context, self.book.ID, created.Version+1)
+
+	self.Equal(models.VersionMismatchError{Entity: "book"}, err)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+}
+
+func (self *DatabaseClientTests) TestDeleteBookNotFound() {
+	for _, version := range []int64{models.AnyVersion, models.FirstVersion} {
+		err := self.client.DeleteBook(self.context, uuid.New(), version)
+
+		self.Equal(models.NotFoundError{Entity: "book"}, err, "version %d", version)
+	}
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorRemovesContributions() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.createBooks("meridian", "in love and trouble")
+
+	orphanedBooks, err := self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version)
+
+	self.NoError(err)
+	self.Equal(int64(2), orphanedBooks)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.Equal([]models.Contributor{contributor(self.coauthor, models.ContributorRoleAuthor)}, book.Contributors)
+	self.Equal(models.FirstVersion+1, book.Version)
+	_, err = self.client.GetAuthorById(self.context, self.author.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorVersionMismatch() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	_, err = self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version+1)
+
+	self.Equal(models.VersionMismatchError{Entity: "author"}, err)
+	_, err = self.client.GetAuthorById(self.context, self.author.ID)
+	self.NoError(err)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.assertBook(created, book)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorNotFound() {
+	for _, version := range []int64{models.AnyVersion, models.FirstVersion} {
+		_, err := self.client.DeleteAuthor(self.context, uuid.New(), version)
+
+		self.Equal(models.NotFoundError{Entity: "author"}, err, "version %d", version)
+	}
+}
+
+func (self *DatabaseClientTests) TestGetBooksPages() {
+	self.createBooks("d", "b", "a", "e", "c")
+
+	first, err := self.client.GetBooks(self.context, models.ListOptions{Limit: 2})
+	self.NoError(err)
+	second, err := self.client.GetBooks(self.context, models.ListOptions{Limit: 2, Cursor: first.NextCursor})
+	self.NoError(err)
+	last, err := self.client.GetBooks(self.context, models.ListOptions{Limit: 2, Cursor: second.NextCursor})
+	self.NoError(err)
+
+	self.Equal([]string{"a", "b"}, titles(first.Items))
+	self.Equal([]string{"c", "d"}, titles(second.Items))
+	self.Equal([]string{"e"}, titles(last.Items))
+	self.Empty(last.NextCursor)
+}
+
+func (self *DatabaseClientTests) TestGetBooksByCreatedAtPagesCoverAllBooks() {
+	books := self.createBooks("d", "b", "a")
+
+	var titlesOfPages []string
+	options := models.ListOptions{Limit: 2, Sort: "created_at"}
+	for {
+		page, err := self.client.GetBooks(self.context, options)
+		self.Require().NoError(err)
+		titlesOfPages = append(titlesOfPages, titles(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		options.Cursor = page.NextCursor
+	}
+
+	self.ElementsMatch(titles(books), titlesOfPages)
+}
+
+func (self *DatabaseClientTests) TestGetBooksDescendingWithPrefix() {
+	self.createBooks("ab", "b", "aa", "ac")
+
+	page, err := self.client.GetBooks(self.context, models.ListOptions{Sort: "-title", Prefix: "a"})
+
+	self.NoError(err)
+	self.Equal([]string{"ac", "ab", "aa"}, titles(page.Items))
+	self.Empty(page.NextCursor)
+}
+
+func (self *DatabaseClientTests) TestGetBooksValidationErrors() {
+	for _, options := range []models.ListOptions{
+		{Sort: "pages"},
+		{Limit: models.MaxPageLimit + 1},
+		{Limit: -1},
+		{Cursor: "invalid"},
+	} {
+		_, err := self.client.GetBooks(self.context, options)
+		self.ErrorIs(err, models.ErrValidation, "options %+v", options)
+	}
+}
+
+func (self *DatabaseClientTests) TestGetBooksCursorOfOtherSortOrder() {
+	self.createBooks("a", "b")
+	page, err := self.client.GetBooks(self.context, models.ListOptions{Limit: 1})
+	self.Require().NoError(err)
+
+	_, err = self.client.GetBooks(self.context, models.ListOptions{Limit: 1, Sort: "-title", Cursor: page.NextCursor})
+
+	self.ErrorIs(err, models.ErrValidation)
+}
+
+func (self *DatabaseClientTests) TestGetBooksByAuthorId() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.createBooks("meridian")
+
+	page, err := self.client.GetBooksByAuthorId(self.context, self.coauthor.ID, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal([]string{self.book.Title}, titles(page.Items))
+}
+
+func (self *DatabaseClientTests) TestGetAuthors() {
+	page, err := self.client.GetAuthors(self.context, models.ListOptions{Sort: "-name"})
+
+	self.NoError(err)
+	self.Equal([]models.Author{self.coauthor, self.author}, page.Items)
+}
+
+func (self *DatabaseClientTests) TestSearch() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.createBooks("purple hibiscus")
+	_, err = self.client.CreateAuthor(self.context, models.Author{ID: uuid.New(), Name: "walker percy"})
+	self.Require().NoError(err)
+
+	page, err := self.client.Search(self.context, models.SearchQuery{Text: "Purple color"}, models.ListOptions{})
+
+	self.NoError(err)
+	self.Require().Len(page.Items, 1)
+	self.Equal(models.SearchResultTypeBook, page.Items[0].Type)
+	self.Equal(self.book.ID, page.Items[0].ID)
+	self.Equal("<b>color</b> <b>purple</b>", page.Items[0].Snippet)
+
+	page, err = self.client.Search(self.context, models.SearchQuery{Text: "walker", Type: models.SearchResultTypeAuthor}, models.ListOptions{})
+
+	self.NoError(err)
+	self.Len(page.Items, 2)
+	for _, result := range page.Items {
+		self.Equal(models.SearchResultTypeAuthor, result.Type)
+	}
+}
+
+func (self *DatabaseClientTests) TestSearchPagesCoverAllResults() {
+	books := self.createBooks("purple", "purple rain", "deep purple sea")
+
+	var found []uuid.UUID
+	options := models.ListOptions{Limit: 1}
+	for {
+		page, err := self.client.Search(self.context, models.SearchQuery{Text: "purple"}, options)
+		self.Require().NoError(err)
+		for _, result := range page.Items {
+			found = append(found, result.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		options.Cursor = page.NextCursor
+	}
+
+	self.ElementsMatch([]uuid.UUID{books[0].ID, books[1].ID, books[2].ID}, found)
+}
+
+func (self *DatabaseClientTests) TestCreateBooks() {
+	books := []models.Book{self.book, self.newBook("meridian", self.coauthor)}
+
+	created, err := self.client.CreateBooks(self.context, books)
+
+	self.NoError(err)
+	self.Require().Len(created, 2)
+	for index, book := range created {
+		stored, err := self.client.GetBookById(self.context, books[index].ID)
+		self.NoError(err)
+		self.assertBook(book, stored)
+	}
+}
+
+func (self *DatabaseClientTests) TestCreateBooksCreatesNoneIfAnyFailed() {
+	other := self.newBook("meridian", self.coauthor)
+	other.ISBN = self.book.ISBN
+
+	_, err := self.client.CreateBooks(self.context, []models.Book{self.book, other})
+
+	self.ErrorIs(err, models.ErrConflict)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestDeleteBookMovesBookToTrash() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	self.NoError(self.client.DeleteBook(self.context, self.book.ID, created.Version))
+
+	_, err = self.client.GetBookByISBN(self.context, self.book.ISBN)
+	self.ErrorIs(err, models.ErrNotFound)
+	books, err := self.client.GetBooks(self.context, models.ListOptions{})
+	self.NoError(err)
+	self.Empty(books.Items)
+	books, err = self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
+	self.NoError(err)
+	self.Empty(books.Items)
+	results, err := self.client.Search(self.context, models.SearchQuery{Text: "purple"}, models.ListOptions{})
+	self.NoError(err)
+	self.Empty(results.Items)
+	_, err = self.client.UpdateBook(self.context, created)
+	self.Equal(models.NotFoundError{Entity: "book"}, err)
+	err = self.client.DeleteBook(self.context, self.book.ID, created.Version)
+	self.Equal(models.NotFoundError{Entity: "book"}, err)
+	reusing := self.newBook("meridian", self.author)
+	reusing.ISBN = self.book.ISBN
+	_, err = self.client.CreateBook(self.context, reusing)
+	self.NoError(err)
+
+	trash, err := self.client.GetTrash(self.context, models.TrashQuery{}, models.ListOptions{})
+	self.NoError(err)
+	self.Require().Len(trash.Items, 1)
+	self.Equal(models.TrashItemTypeBook, trash.Items[0].Type)
+	self.Equal(self.book.ID, trash.Items[0].ID)
+	self.Equal(self.book.Title, trash.Items[0].Title)
+	self.False(trash.Items[0].DeletedAt.IsZero())
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorMovesAuthorToTrash() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	_, err = self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version)
+	self.Require().NoError(err)
+
+	authors, err := self.client.GetAuthors(self.context, models.ListOptions{})
+	self.NoError(err)
+	self.Equal([]models.Author{self.coauthor}, authors.Items)
+	books, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
+	self.NoError(err)
+	self.Empty(books.Items)
+	results, err := self.client.Search(self.context, models.SearchQuery{Text: "alice"}, models.ListOptions{})
+	self.NoError(err)
+	self.Empty(results.Items)
+	_, err = self.client.UpdateAuthor(self.context, self.author)
+	self.Equal(models.NotFoundError{Entity: "author"}, err)
+	_, err = self.client.CreateAuthor(self.context, models.Author{ID: uuid.New(), Name: self.author.Name})
+	self.NoError(err)
+
+	trash, err := self.client.GetTrash(self.context, models.TrashQuery{Type: models.TrashItemTypeAuthor}, models.ListOptions{})
+	self.NoError(err)
+	self.Require().Len(trash.Items, 1)
+	self.Equal(models.TrashItemTypeAuthor, trash.Items[0].Type)
+	self.Equal(self.author.Name, trash.Items[0].Title)
+}
+
+func (self *DatabaseClientTests) TestDeleteAuthorCountsBooksOfOtherAuthorsInTrash() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	deleted := self.createBooks("meridian")[0]
+	self.Require().NoError(self.client.DeleteBook(self.context, deleted.ID, models.AnyVersion))
+	_, err = self.client.DeleteAuthor(self.context, self.coauthor.ID, models.AnyVersion)
+	self.Require().NoError(err)
+
+	orphanedBooks, err := self.client.DeleteAuthor(self.context, self.author.ID, models.AnyVersion)
+
+	self.NoError(err)
+	self.Equal(int64(1), orphanedBooks)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.Empty(book.Contributors)
+}
+
+func (self *DatabaseClientTests) TestWriteBookValidationErrorIfAuthorInTrash() {
+	created, err := self.client.CreateBook(self.context, self.newBook("meridian", self.author))
+	self.Require().NoError(err)
+	_, err = self.client.DeleteAuthor(self.context, self.coauthor.ID, models.AnyVersion)
+	self.Require().NoError(err)
+
+	_, err = self.client.CreateBook(self.context, self.book)
+	self.ErrorIs(err, models.ErrValidation)
+	_, err = self.client.CreateBooks(self.context, []models.Book{self.book})
+	self.ErrorIs(err, models.ErrValidation)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+
+	updated := created
+	updated.Contributors = self.book.Contributors
+	_, err = self.client.UpdateBook(self.context, updated)
+	self.ErrorIs(err, models.ErrValidation)
+	book, err := self.client.GetBookById(self.context, created.ID)
+	self.NoError(err)
+	self.assertBook(created, book)
+}
+
+func (self *DatabaseClientTests) TestRestoreBook() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.Require().NoError(self.client.DeleteBook(self.context, self.book.ID, models.AnyVersion))
+
+	restored, err := self.client.RestoreBook(self.context, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(created.Version+1, restored.Version)
+	self.False(restored.UpdatedAt.Before(created.UpdatedAt))
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.assertBook(restored, book)
+	trash, err := self.client.GetTrash(self.context, models.TrashQuery{}, models.ListOptions{})
+	self.NoError(err)
+	self.Empty(trash.Items)
+}
+
+func (self *DatabaseClientTests) TestRestoreBookNotFoundUnlessInTrash() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+
+	for _, bookId := range []uuid.UUID{self.book.ID, uuid.New()} {
+		_, err = self.client.RestoreBook(self.context, bookId)
+
+		self.Equal(models.NotFoundError{Entity: "book"}, err)
+	}
+}
+
+func (self *DatabaseClientTests) TestRestoreBookConflictIfISBNReused() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	self.Require().NoError(self.client.DeleteBook(self.context, self.book.ID, created.Version))
+	reusing := self.newBook("meridian", self.author)
+	reusing.ISBN = self.book.ISBN
+	_, err = self.client.CreateBook(self.context, reusing)
+	self.Require().NoError(err)
+
+	_, err = self.client.RestoreBook(self.context, self.book.ID)
+
+	self.ErrorIs(err, models.ErrConflict)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestRestoreAuthorRestoresContributions() {
+	created, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	_, err = self.client.DeleteAuthor(self.context, self.author.ID, models.AnyVersion)
+	self.Require().NoError(err)
+
+	restored, err := self.client.RestoreAuthor(self.context, self.author.ID)
+
+	self.NoError(err)
+	self.Equal(self.author.Version+1, restored.Version)
+	author, err := self.client.GetAuthorById(self.context, self.author.ID)
+	self.NoError(err)
+	self.Equal(restored, author)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.Equal(created.Contributors, book.Contributors)
+	self.Equal(created.Version+2, book.Version)
+	books, err := self.client.GetBooksByAuthorId(self.context, self.author.ID, models.ListOptions{})
+	self.NoError(err)
+	self.Equal([]string{self.book.Title}, titles(books.Items))
+}
+
+func (self *DatabaseClientTests) TestRestoreAuthorNotFoundUnlessInTrash() {
+	for _, authorId := range []uuid.UUID{self.author.ID, uuid.New()} {
+		_, err := self.client.RestoreAuthor(self.context, authorId)
+
+		self.Equal(models.NotFoundError{Entity: "author"}, err)
+	}
+}
+
+func (self *DatabaseClientTests) TestRestoreAuthorConflictIfNameReused() {
+	_, err := self.client.DeleteAuthor(self.context, self.author.ID, self.author.Version)
+	self.Require().NoError(err)
+	_, err = self.client.CreateAuthor(self.context, models.Author{ID: uuid.New(), Name: self.author.Name})
+	self.Require().NoError(err)
+
+	_, err = self.client.RestoreAuthor(self.context, self.author.ID)
+
+	self.ErrorIs(err, models.ErrConflict)
+	_, err = self.client.GetAuthorById(self.context, self.author.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestGetTrashPagesLastDeletedFirst() {
+	books := self.createBooks("a", "b", "c")
+	for _, book := range books {
+		self.Require().NoError(self.client.DeleteBook(self.context, book.ID, models.AnyVersion))
+	}
+	_, err := self.client.DeleteAuthor(self.context, self.coauthor.ID, models.AnyVersion)
+	self.Require().NoError(err)
+
+	var items []string
+	options := models.ListOptions{Limit: 3}
+	for {
+		page, err := self.client.GetTrash(self.context, models.TrashQuery{}, options)
+		self.Require().NoError(err)
+		for _, item := range page.Items {
+			items = append(items, item.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		options.Cursor = page.NextCursor
+	}
+
+	self.Equal([]string{self.coauthor.Name, "c", "b", "a"}, items)
+	page, err := self.client.GetTrash(self.context, models.TrashQuery{Type: models.TrashItemTypeBook}, models.ListOptions{Prefix: "b"})
+	self.NoError(err)
+	self.Require().Len(page.Items, 1)
+	self.Equal(books[1].ID, page.Items[0].ID)
+}
+
+func (self *DatabaseClientTests) TestPurgeTrash() {
+	_, err := self.client.CreateBook(self.context, self.book)
+	self.Require().NoError(err)
+	deleted := self.createBooks("meridian")[0]
+	self.Require().NoError(self.client.DeleteBook(self.context, deleted.ID, models.AnyVersion))
+	_, err = self.client.DeleteAuthor(self.context, self.coauthor.ID, models.AnyVersion)
+	self.Require().NoError(err)
+
+	purged, err := self.client.PurgeTrash(self.context, time.Now().Add(-time.Hour))
+	self.NoError(err)
+	self.Equal(int64(0), purged)
+	purged, err = self.client.PurgeTrash(self.context, time.Now().Add(time.Hour))
+
+	self.NoError(err)
+	self.Equal(int64(2), purged)
+	trash, err := self.client.GetTrash(self.context, models.TrashQuery{}, models.ListOptions{})
+	self.NoError(err)
+	self.Empty(trash.Items)
+	_, err = self.client.RestoreBook(self.context, deleted.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+	_, err = self.client.RestoreAuthor(self.context, self.coauthor.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+	book, err := self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+	self.Equal([]models.Contributor{contributor(self.author, models.ContributorRoleAuthor)}, book.Contributors)
+	_, err = self.client.CreateAuthor(self.context, models.Author{ID: uuid.New(), Name: self.coauthor.Name})
+	self.NoError(err)
+}
+
+func (self *DatabaseClientTests) TestWithTxCommitsUnitOfWork() {
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		if _, err := tx.CreateBook(self.context, self.book); err != nil {
+			return err
+		}
+		_, err := tx.GetBookById(self.context, self.book.ID)
+		return err
+	})
+
+	self.NoError(err)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.NoError(err)
+}
+
+func (self *DatabaseClientTests) TestWithTxRollsBackIfUnitOfWorkFailed() {
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		if _, err := tx.CreateBook(self.context, self.book); err != nil {
+			return err
+		}
+		return self.testError
+	})
+
+	self.ErrorIs(err, self.testError)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestWithTxNestedJoinsTransaction() {
+	err := self.client.WithTx(self.context, func(tx services.DatabaseClient) error {
+		err := tx.WithTx(self.context, func(nested services.DatabaseClient) error {
+			_, err := nested.CreateBook(self.context, self.book)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return self.testError
+	})
+
+	self.ErrorIs(err, self.testError)
+	_, err = self.client.GetBookById(self.context, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
@@ -0,0 +1,503 @@
+// Package memory implements the database client keeping the books and the authors in memory.
+// It enforces the constraints of the PostgreSQL schema and is meant for the local development
+// and the tests, the data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// Names of the entities reported by models.NotFoundError.
+const (
+	bookEntity   = "book"
+	authorEntity = "author"
+)
+
+type storedAuthor struct {
+	models.Author
+	createdAt time.Time
+	// deletedAt is the time the author was moved to the trash, it is zero unless the author is deleted.
+	deletedAt time.Time
+}
+
+func (self storedAuthor) deleted() bool {
+	return !self.deletedAt.IsZero()
+}
+
+// contribution is the row of book_authors, the name of the author is resolved when the book is read.
+type contribution struct {
+	authorId uuid.UUID
+	role     string
+}
+
+// storedBook is the book without the contributors, they are kept as contributions.
+type storedBook struct {
+	models.Book
+	contributions []contribution
+	// deletedAt is the time the book was moved to the trash, it is zero unless the book is deleted.
+	deletedAt time.Time
+}
+
+func (self storedBook) deleted() bool {
+	return !self.deletedAt.IsZero()
+}
+
+// contributes reports whether the author contributes to the book.
+func (self storedBook) contributes(authorId uuid.UUID) bool {
+	for _, contribution := range self.contributions {
+		if contribution.authorId == authorId {
+			return true
+		}
+	}
+	return false
+}
+
+// store is the state of the tables. The stored values are replaced and never modified in place,
+// so the clone shares them with the original.
+type store struct {
+	authors map[uuid.UUID]storedAuthor
+	books   map[uuid.UUID]storedBook
+}
+
+func newStore() *store {
+	return &store{
+		authors: map[uuid.UUID]storedAuthor{},
+		books:   map[uuid.UUID]storedBook{},
+	}
+}
+
+func (self *store) clone() *store {
+	clone := &store{
+		authors: make(map[uuid.UUID]storedAuthor, len(self.authors)),
+		books:   make(map[uuid.UUID]storedBook, len(self.books)),
+	}
+	for id, author := range self.authors {
+		clone.authors[id] = author
+	}
+	for id, book := range self.books {
+		clone.books[id] = book
+	}
+
+	return clone
+}
+
+// book returns the stored book with the contributors resolved, the deleted authors are not listed.
+func (self *store) book(stored storedBook) models.Book {
+	book := stored.Book
+	book.Contributors = nil
+	for _, contribution := range stored.contributions {
+		author := self.authors[contribution.authorId]
+		if author.deleted() {
+			continue
+		}
+		book.Contributors = append(book.Contributors, models.Contributor{
+			Author: models.Author{ID: author.ID, Name: author.Name},
+			Role:   contribution.role,
+		})
+	}
+
+	return book
+}
+
+// shared is the state shared by the client and its transactions.
+type shared struct {
+	mutex sync.RWMutex
+	store *store
+}
+
+type DatabaseClient struct {
+	shared *shared
+	// tx is the copy of the store changed by the transaction, it is nil outside WithTx.
+	tx  *store
+	now func() time.Time
+}
+
+func NewDatabaseClient() *DatabaseClient {
+	return &DatabaseClient{
+		shared: &shared{store: newStore()},
+		now:    time.Now,
+	}
+}
+
+// read runs the query on the store, it is locked for reading unless the client runs in the transaction.
+func (self *DatabaseClient) read(query func(store *store)) {
+	if self.tx != nil {
+		query(self.tx)
+		return
+	}
+	self.shared.mutex.RLock()
+	defer self.shared.mutex.RUnlock()
+	query(self.shared.store)
+}
+
+// write runs the statement on the store, it is locked for writing unless the client runs in the transaction.
+// The statement checks the constraints before it changes the store, so the failed one changes nothing.
+func (self *DatabaseClient) write(statement func(store *store) error) error {
+	if self.tx != nil {
+		return statement(self.tx)
+	}
+	self.shared.mutex.Lock()
+	defer self.shared.mutex.Unlock()
+	return statement(self.shared.store)
+}
+
+// CreateAuthor creates the author and returns it with the version set.
+func (self *DatabaseClient) CreateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	err := self.write(func(store *store) error {
+		if _, ok := store.authors[author.ID]; ok {
+			return conflictError("author id already exists")
+		}
+		if err := checkAuthorName(store, author); err != nil {
+			return err
+		}
+		author.Version = models.FirstVersion
+		store.authors[author.ID] = storedAuthor{Author: author, createdAt: self.now()}
+		return nil
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return author, nil
+}
+
+// CreateBook creates the book and returns it with the timestamps and the version set.
+func (self *DatabaseClient) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	err := self.write(func(store *store) error {
+		var err error
+		book, err = self.createBook(store, book)
+		return err
+	})
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+func (self *DatabaseClient) createBook(store *store, book models.Book) (models.Book, error) {
+	if _, ok := store.books[book.ID]; ok {
+		return models.Book{}, conflictError("book id already exists")
+	}
+	contributions, err := checkBook(store, book)
+	if err != nil {
+		return models.Book{}, err
+	}
+	book.CreatedAt = self.now()
+	book.UpdatedAt = book.CreatedAt
+	book.Version = models.FirstVersion
+	store.books[book.ID] = storedBook{Book: book, contributions: contributions}
+
+	return book, nil
+}
+
+// CreateBooks creates all the books or none of them and returns them with the timestamps and the versions set.
+func (self *DatabaseClient) CreateBooks(ctx context.Context, books []models.Book) ([]models.Book, error) {
+	var created []models.Book
+	err := self.write(func(store *store) error {
+		clone := store.clone()
+		created = make([]models.Book, 0, len(books))
+		for _, book := range books {
+			book, err := self.createBook(clone, book)
+			if err != nil {
+				return err
+			}
+			created = append(created, book)
+		}
+		*store = *clone
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (self *DatabaseClient) GetBookById(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	var book models.Book
+	var err error
+	self.read(func(store *store) {
+		stored, ok := store.books[bookId]
+		if !ok || stored.deleted() {
+			err = models.NotFoundError{Entity: bookEntity}
+			return
+		}
+		book = store.book(stored)
+	})
+
+	return book, err
+}
+
+// GetBookByISBN returns the book by the normalized ISBN-13.
+func (self *DatabaseClient) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	var book models.Book
+	var err error = models.NotFoundError{Entity: bookEntity}
+	self.read(func(store *store) {
+		for _, stored := range store.books {
+			if isbn != "" && stored.ISBN == isbn && !stored.deleted() {
+				book, err = store.book(stored), nil
+				return
+			}
+		}
+	})
+
+	return book, err
+}
+
+func (self *DatabaseClient) GetAuthorById(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	var author models.Author
+	var err error
+	self.read(func(store *store) {
+		stored, ok := store.authors[authorId]
+		if !ok || stored.deleted() {
+			err = models.NotFoundError{Entity: authorEntity}
+			return
+		}
+		author = stored.Author
+	})
+
+	return author, err
+}
+
+func (self *DatabaseClient) GetBooksByAuthorId(
+	ctx context.Context,
+	authorId uuid.UUID,
+	options models.ListOptions,
+) (models.Page[models.Book], error) {
+	return self.getBooks(options, func(store *store, book storedBook) bool {
+		return book.contributes(authorId) && !store.authors[authorId].deleted()
+	})
+}
+
+func (self *DatabaseClient) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	var authors []models.Author
+	var createdAt []time.Time
+	self.read(func(store *store) {
+		for _, author := range store.authors {
+			if author.deleted() {
+				continue
+			}
+			authors = append(authors, author.Author)
+			createdAt = append(createdAt, author.createdAt)
+		}
+	})
+
+	return paginate(authors, options, authorSortOrders, func(index int) pageItem {
+		return pageItem{id: authors[index].ID, title: authors[index].Name, createdAt: createdAt[index]}
+	})
+}
+
+// UpdateAuthor updates the author of the expected version, increments the versions of the books
+// it contributes to and returns the author with the incremented version.
+func (self *DatabaseClient) UpdateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	err := self.write(func(store *store) error {
+		stored, ok := store.authors[author.ID]
+		if !ok || stored.deleted() {
+			return models.NotFoundError{Entity: authorEntity}
+		}
+		if err := checkVersion(authorEntity, stored.Version, author.Version); err != nil {
+			return err
+		}
+		if err := checkAuthorName(store, author); err != nil {
+			return err
+		}
+		stored.Name = author.Name
+		stored.Version++
+		store.authors[author.ID] = stored
+		author = stored.Author
+
+		now := self.now()
+		for id, book := range store.books {
+			if book.contributes(author.ID) {
+				book.UpdatedAt = now
+				book.Version++
+				store.books[id] = book
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return author, nil
+}
+
+// DeleteAuthor moves the author of the expected version to the trash, increments the versions of the books
+// it contributed to and returns the number of books left without an author. The contributions are kept,
+// so the restored author contributes to the books again.
+func (self *DatabaseClient) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	var orphanedBooks int64
+	err := self.write(func(store *store) error {
+		stored, ok := store.authors[authorId]
+		if !ok || stored.deleted() {
+			return models.NotFoundError{Entity: authorEntity}
+		}
+		if err := checkVersion(authorEntity, stored.Version, version); err != nil {
+			return err
+		}
+		now := self.now()
+		stored.deletedAt = now
+		store.authors[authorId] = stored
+
+		for id, book := range store.books {
+			if !book.contributes(authorId) {
+				continue
+			}
+			if !book.deleted() && len(store.book(book).Contributors) == 0 {
+				orphanedBooks++
+			}
+			book.UpdatedAt = now
+			book.Version++
+			store.books[id] = book
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return orphanedBooks, nil
+}
+
+func (self *DatabaseClient) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	return self.getBooks(options, func(*store, storedBook) bool {
+		return true
+	})
+}
+
+// getBooks returns the page of the books not in the trash matching the filter.
+func (self *DatabaseClient) getBooks(
+	options models.ListOptions,
+	filter func(store *store, book storedBook) bool,
+) (models.Page[models.Book], error) {
+	var books []models.Book
+	self.read(func(store *store) {
+		for _, book := range store.books {
+			if !book.deleted() && filter(store, book) {
+				books = append(books, store.book(book))
+			}
+		}
+	})
+
+	return paginate(books, options, bookSortOrders, func(index int) pageItem {
+		return pageItem{id: books[index].ID, title: books[index].Title, createdAt: books[index].CreatedAt}
+	})
+}
+
+// UpdateBook updates the book of the expected version and returns it with the timestamps and the version set.
+func (self *DatabaseClient) UpdateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	err := self.write(func(store *store) error {
+		stored, ok := store.books[book.ID]
+		if !ok || stored.deleted() {
+			return models.NotFoundError{Entity: bookEntity}
+		}
+		if err := checkVersion(bookEntity, stored.Version, book.Version); err != nil {
+			return err
+		}
+		contributions, err := checkBook(store, book)
+		if err != nil {
+			return err
+		}
+		book.CreatedAt = stored.CreatedAt
+		book.UpdatedAt = self.now()
+		book.Version = stored.Version + 1
+		store.books[book.ID] = storedBook{Book: book, contributions: contributions}
+		return nil
+	})
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+// DeleteBook moves the book of the expected version to the trash.
+func (self *DatabaseClient) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	return self.write(func(store *store) error {
+		stored, ok := store.books[bookId]
+		if !ok || stored.deleted() {
+			return models.NotFoundError{Entity: bookEntity}
+		}
+		if err := checkVersion(bookEntity, stored.Version, version); err != nil {
+			return err
+		}
+		stored.deletedAt = self.now()
+		store.books[bookId] = stored
+		return nil
+	})
+}
+
+// checkVersion checks the stored version of the entity is the expected one unless any version is expected.
+func checkVersion(entity string, stored int64, expected int64) error {
+	if expected != models.AnyVersion && expected != stored {
+		return models.VersionMismatchError{Entity: entity}
+	}
+	return nil
+}
+
+// checkAuthorName checks the name of the author is unique among the authors not in the trash.
+func checkAuthorName(store *store, author models.Author) error {
+	for id, stored := range store.authors {
+		if id != author.ID && stored.Name == author.Name && !stored.deleted() {
+			return conflictError("author name already exists")
+		}
+	}
+	return nil
+}
+
+// checkISBN checks the ISBN of the book is unique among the books not in the trash.
+func checkISBN(store *store, book models.Book) error {
+	if book.ISBN == "" {
+		return nil
+	}
+	for id, stored := range store.books {
+		if id != book.ID && stored.ISBN == book.ISBN && !stored.deleted() {
+			return conflictError("book isbn already exists")
+		}
+	}
+	return nil
+}
+
+// checkBook checks the ISBN of the book is unique and its contributors exist and are not in the trash.
+// It returns the contributions of the book to store.
+func checkBook(store *store, book models.Book) ([]contribution, error) {
+	if book.PageCount < 0 {
+		return nil, models.ValidationError{Field: "page_count", Message: "book page count must not be negative"}
+	}
+	if err := checkISBN(store, book); err != nil {
+		return nil, err
+	}
+
+	contributions := make([]contribution, 0, len(book.Contributors))
+	seen := map[contribution]struct{}{}
+	for _, contributor := range book.Contributors {
+		author, ok := store.authors[contributor.Author.ID]
+		if !ok {
+			return nil, fmt.Errorf("%w: author %s does not exist", models.ErrValidation, contributor.Author.ID)
+		}
+		if author.deleted() {
+			return nil, fmt.Errorf("%w: author %s is in the trash", models.ErrValidation, contributor.Author.ID)
+		}
+		contribution := contribution{authorId: contributor.Author.ID, role: contributor.Role}
+		if _, ok := seen[contribution]; ok {
+			return nil, conflictError("book contributor already exists")
+		}
+		seen[contribution] = struct{}{}
+		contributions = append(contributions, contribution)
+	}
+
+	return contributions, nil
+}
+
+func conflictError(message string) error {
+	return fmt.Errorf("%w: %s", models.ErrConflict, message)
+}
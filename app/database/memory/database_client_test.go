@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/database/contract"
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/app/services"
+)
+
+type DatabaseClientTests struct {
+	contract.DatabaseClientTests
+	memoryClient *DatabaseClient
+}
+
+func TestDatabaseClient(t *testing.T) {
+	tests := new(DatabaseClientTests)
+	tests.NewClient = func() services.DatabaseClient {
+		tests.memoryClient = NewDatabaseClient()
+		return tests.memoryClient
+	}
+	suite.Run(t, tests)
+}
+
+func (self *DatabaseClientTests) TestWithTxDiscardsChangesIfUnitOfWorkPanicked() {
+	author := models.Author{ID: uuid.New(), Name: "carol shields"}
+
+	self.PanicsWithValue("test_panic", func() {
+		_ = self.memoryClient.WithTx(context.Background(), func(tx services.DatabaseClient) error {
+			_, _ = tx.CreateAuthor(context.Background(), author)
+			panic("test_panic")
+		})
+	})
+
+	_, err := self.memoryClient.GetAuthorById(context.Background(), author.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *DatabaseClientTests) TestConcurrentWrites() {
+	var wait sync.WaitGroup
+	for index := 0; index < 10; index++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			_, err := self.memoryClient.CreateBook(context.Background(), models.Book{ID: uuid.New(), Title: "meridian"})
+			self.NoError(err)
+		}()
+	}
+	wait.Wait()
+
+	page, err := self.memoryClient.GetBooks(context.Background(), models.ListOptions{})
+	self.NoError(err)
+	self.Len(page.Items, 10)
+}
@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// sortField is the value of the item the page is ordered by.
+type sortField int
+
+const (
+	sortByTitle sortField = iota
+	sortByCreatedAt
+	sortByRank
+	sortByDeletedAt
+)
+
+// sortOrder describes the value the keyset pagination is ordered by, ties are broken by the id.
+type sortOrder struct {
+	field      sortField
+	descending bool
+}
+
+// Sort orders supported by the book lists, the empty one is the default.
+var bookSortOrders = map[string]sortOrder{
+	"":           {field: sortByTitle},
+	"title":      {field: sortByTitle},
+	"-title":     {field: sortByTitle, descending: true},
+	"created_at": {field: sortByCreatedAt},
+}
+
+// Sort orders supported by the author lists, the empty one is the default.
+var authorSortOrders = map[string]sortOrder{
+	"":           {field: sortByTitle},
+	"name":       {field: sortByTitle},
+	"-name":      {field: sortByTitle, descending: true},
+	"created_at": {field: sortByCreatedAt},
+}
+
+// Search results are always ordered by rank, the most relevant first.
+var searchSortOrders = map[string]sortOrder{
+	"": {field: sortByRank, descending: true},
+}
+
+// Trash is always ordered by the deletion time, the last deleted first.
+var trashSortOrders = map[string]sortOrder{
+	"": {field: sortByDeletedAt, descending: true},
+}
+
+// pageItem holds the values of the item the page is ordered and filtered by,
+// title is the title of the book or the name of the author.
+type pageItem struct {
+	id        uuid.UUID
+	title     string
+	createdAt time.Time
+	deletedAt time.Time
+	rank      float64
+}
+
+// compare returns a negative number if the item a goes before the item b in the order.
+// Titles are compared byte-wise, unlike PostgreSQL using the collation of the database.
+func (self sortOrder) compare(a, b pageItem) int {
+	var result int
+	switch self.field {
+	case sortByTitle:
+		result = strings.Compare(a.title, b.title)
+	case sortByCreatedAt:
+		result = compareValues(a.createdAt.UnixNano(), b.createdAt.UnixNano())
+	case sortByRank:
+		result = compareValues(a.rank, b.rank)
+	case sortByDeletedAt:
+		result = compareValues(a.deletedAt.UnixNano(), b.deletedAt.UnixNano())
+	}
+	if result == 0 {
+		result = bytes.Compare(a.id[:], b.id[:])
+	}
+	if self.descending {
+		return -result
+	}
+	return result
+}
+
+func compareValues[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// cursor returns the position of the item.
+func (self sortOrder) cursor(sort string, item pageItem) cursor {
+	position := cursor{Sort: sort, ID: item.id}
+	switch self.field {
+	case sortByTitle:
+		position.Value = item.title
+	case sortByCreatedAt:
+		position.Value = item.createdAt.Format(time.RFC3339Nano)
+	case sortByRank:
+		position.Value = strconv.FormatFloat(item.rank, 'g', -1, 64)
+	case sortByDeletedAt:
+		position.Value = item.deletedAt.Format(time.RFC3339Nano)
+	}
+
+	return position
+}
+
+// item returns the item at the position of the cursor.
+func (self sortOrder) item(position cursor) (pageItem, error) {
+	item := pageItem{id: position.ID}
+	var err error
+	switch self.field {
+	case sortByTitle:
+		item.title = position.Value
+	case sortByCreatedAt:
+		item.createdAt, err = time.Parse(time.RFC3339Nano, position.Value)
+	case sortByRank:
+		item.rank, err = strconv.ParseFloat(position.Value, 64)
+	case sortByDeletedAt:
+		item.deletedAt, err = time.Parse(time.RFC3339Nano, position.Value)
+	}
+	if err != nil {
+		return pageItem{}, invalidCursorError
+	}
+
+	return item, nil
+}
+
+// cursor is the position of the last item of the page.
+type cursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+var invalidCursorError = models.ValidationError{Field: "cursor", Message: "cursor is invalid"}
+
+func encodeCursor(value cursor) string {
+	cursorJson, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(cursorJson)
+}
+
+func decodeCursor(encoded string) (cursor, error) {
+	cursorJson, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor{}, invalidCursorError
+	}
+	var value cursor
+	if err = json.Unmarshal(cursorJson, &value); err != nil {
+		return cursor{}, invalidCursorError
+	}
+
+	return value, nil
+}
+
+// paginate returns the requested page of the items, pageItem returns the values of the item by its index.
+func paginate[T any](
+	items []T,
+	options models.ListOptions,
+	sortOrders map[string]sortOrder,
+	pageItem func(index int) pageItem,
+) (models.Page[T], error) {
+	order, ok := sortOrders[options.Sort]
+	if !ok {
+		return models.Page[T]{}, models.ValidationError{Field: "sort", Message: "sort order is not supported"}
+	}
+	if options.Limit < 0 || options.Limit > models.MaxPageLimit {
+		return models.Page[T]{}, models.ValidationError{
+			Field:   "limit",
+			Message: fmt.Sprintf("limit must be between 1 and %d", models.MaxPageLimit),
+		}
+	}
+	limit := options.Limit
+	if limit == 0 {
+		limit = models.DefaultPageLimit
+	}
+
+	var indexes []int
+	for index := range items {
+		if strings.HasPrefix(pageItem(index).title, options.Prefix) {
+			indexes = append(indexes, index)
+		}
+	}
+	if options.Cursor != "" {
+		position, err := decodeCursor(options.Cursor)
+		if err != nil {
+			return models.Page[T]{}, err
+		}
+		if position.Sort != options.Sort {
+			return models.Page[T]{}, models.ValidationError{Field: "cursor", Message: "cursor does not match the sort order"}
+		}
+		last, err := order.item(position)
+		if err != nil {
+			return models.Page[T]{}, err
+		}
+		after := indexes[:0]
+		for _, index := range indexes {
+			if order.compare(pageItem(index), last) > 0 {
+				after = append(after, index)
+			}
+		}
+		indexes = after
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return order.compare(pageItem(indexes[i]), pageItem(indexes[j])) < 0
+	})
+
+	var page models.Page[T]
+	for position, index := range indexes {
+		if position == limit {
+			page.NextCursor = encodeCursor(order.cursor(options.Sort, pageItem(indexes[position-1])))
+			break
+		}
+		page.Items = append(page.Items, items[index])
+	}
+
+	return page, nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// Search returns the books and the authors matching the query, ordered by rank.
+// Unlike websearch_to_tsquery of PostgreSQL, the query only matches the documents containing all of its words,
+// the operators are ignored.
+func (self *DatabaseClient) Search(
+	ctx context.Context,
+	search models.SearchQuery,
+	options models.ListOptions,
+) (models.Page[models.SearchResult], error) {
+	terms := map[string]struct{}{}
+	for _, word := range splitWords(search.Text) {
+		terms[strings.ToLower(search.Text[word.start:word.end])] = struct{}{}
+	}
+
+	var results []models.SearchResult
+	self.read(func(store *store) {
+		if search.Type == "" || search.Type == models.SearchResultTypeBook {
+			for _, book := range store.books {
+				if book.deleted() {
+					continue
+				}
+				if result, ok := match(terms, models.SearchResultTypeBook, book.Title); ok {
+					result.ID = book.ID
+					results = append(results, result)
+				}
+			}
+		}
+		if search.Type == "" || search.Type == models.SearchResultTypeAuthor {
+			for _, author := range store.authors {
+				if author.deleted() {
+					continue
+				}
+				if result, ok := match(terms, models.SearchResultTypeAuthor, author.Name); ok {
+					result.ID = author.ID
+					results = append(results, result)
+				}
+			}
+		}
+	})
+
+	return paginate(results, options, searchSortOrders, func(index int) pageItem {
+		return pageItem{id: results[index].ID, title: results[index].Title, rank: results[index].Rank}
+	})
+}
+
+// word is the position of the word in the text.
+type word struct {
+	start int
+	end   int
+}
+
+// splitWords returns the positions of the words made of letters and digits.
+func splitWords(text string) []word {
+	var words []word
+	start := -1
+	for index, char := range text {
+		isWordChar := unicode.IsLetter(char) || unicode.IsDigit(char)
+		switch {
+		case isWordChar && start < 0:
+			start = index
+		case !isWordChar && start >= 0:
+			words = append(words, word{start: start, end: index})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{start: start, end: len(text)})
+	}
+
+	return words
+}
+
+// match returns the search result for the text if it contains all the terms. The rank is the share
+// of the words of the text matching the terms and the snippet is the text with them highlighted.
+func match(terms map[string]struct{}, resultType string, text string) (models.SearchResult, bool) {
+	if len(terms) == 0 {
+		return models.SearchResult{}, false
+	}
+
+	words := splitWords(text)
+	matched := map[string]struct{}{}
+	var snippet strings.Builder
+	position, matches := 0, 0
+	for _, word := range words {
+		term := strings.ToLower(text[word.start:word.end])
+		if _, ok := terms[term]; !ok {
+			continue
+		}
+		matched[term] = struct{}{}
+		matches++
+		snippet.WriteString(text[position:word.start])
+		snippet.WriteString("<b>" + text[word.start:word.end] + "</b>")
+		position = word.end
+	}
+	if len(matched) < len(terms) {
+		return models.SearchResult{}, false
+	}
+	snippet.WriteString(text[position:])
+
+	return models.SearchResult{
+		Type:    resultType,
+		Title:   text,
+		Snippet: snippet.String(),
+		Rank:    float64(matches) / float64(len(words)),
+	}, true
+}
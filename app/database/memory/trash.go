@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// RestoreBook restores the book from the trash and returns it with the incremented version.
+func (self *DatabaseClient) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	var book models.Book
+	err := self.write(func(store *store) error {
+		stored, ok := store.books[bookId]
+		if !ok || !stored.deleted() {
+			return models.NotFoundError{Entity: bookEntity}
+		}
+		if err := checkISBN(store, stored.Book); err != nil {
+			return err
+		}
+		stored.deletedAt = time.Time{}
+		stored.UpdatedAt = self.now()
+		stored.Version++
+		store.books[bookId] = stored
+		book = store.book(stored)
+		return nil
+	})
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+// RestoreAuthor restores the author from the trash, increments the versions of the books it contributes to
+// and returns the author with the incremented version.
+func (self *DatabaseClient) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	var author models.Author
+	err := self.write(func(store *store) error {
+		stored, ok := store.authors[authorId]
+		if !ok || !stored.deleted() {
+			return models.NotFoundError{Entity: authorEntity}
+		}
+		if err := checkAuthorName(store, stored.Author); err != nil {
+			return err
+		}
+		stored.deletedAt = time.Time{}
+		stored.Version++
+		store.authors[authorId] = stored
+		author = stored.Author
+
+		now := self.now()
+		for id, book := range store.books {
+			if book.contributes(authorId) {
+				book.UpdatedAt = now
+				book.Version++
+				store.books[id] = book
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return author, nil
+}
+
+// GetTrash returns the books and the authors in the trash, the last deleted first.
+func (self *DatabaseClient) GetTrash(
+	ctx context.Context,
+	trash models.TrashQuery,
+	options models.ListOptions,
+) (models.Page[models.TrashItem], error) {
+	var items []models.TrashItem
+	self.read(func(store *store) {
+		if trash.Type == "" || trash.Type == models.TrashItemTypeBook {
+			for _, book := range store.books {
+				if book.deleted() {
+					items = append(items, models.TrashItem{
+						Type:      models.TrashItemTypeBook,
+						ID:        book.ID,
+						Title:     book.Title,
+						DeletedAt: book.deletedAt,
+					})
+				}
+			}
+		}
+		if trash.Type == "" || trash.Type == models.TrashItemTypeAuthor {
+			for _, author := range store.authors {
+				if author.deleted() {
+					items = append(items, models.TrashItem{
+						Type:      models.TrashItemTypeAuthor,
+						ID:        author.ID,
+						Title:     author.Name,
+						DeletedAt: author.deletedAt,
+					})
+				}
+			}
+		}
+	})
+
+	return paginate(items, options, trashSortOrders, func(index int) pageItem {
+		return pageItem{id: items[index].ID, title: items[index].Title, deletedAt: items[index].DeletedAt}
+	})
+}
+
+// PurgeTrash deletes the books and the authors moved to the trash before the time and returns their number.
+// The purged authors are removed from the contributions of the books.
+func (self *DatabaseClient) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := self.write(func(store *store) error {
+		for id, book := range store.books {
+			if book.deleted() && book.deletedAt.Before(deletedBefore) {
+				delete(store.books, id)
+				purged++
+			}
+		}
+		purgedAuthors := map[uuid.UUID]struct{}{}
+		for id, author := range store.authors {
+			if author.deleted() && author.deletedAt.Before(deletedBefore) {
+				delete(store.authors, id)
+				purgedAuthors[id] = struct{}{}
+				purged++
+			}
+		}
+		if len(purgedAuthors) == 0 {
+			return nil
+		}
+
+		for id, book := range store.books {
+			contributions := make([]contribution, 0, len(book.contributions))
+			for _, contribution := range book.contributions {
+				if _, ok := purgedAuthors[contribution.authorId]; !ok {
+					contributions = append(contributions, contribution)
+				}
+			}
+			if len(contributions) < len(book.contributions) {
+				book.contributions = contributions
+				store.books[id] = book
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/egormizerov/books/app/services"
+)
+
+// WithTx runs the unit of work on the copy of the store which replaces the store if the unit of work succeeds,
+// the copy is discarded if it fails or panics. The store is locked for the whole transaction, so the transactions
+// are serializable. WithTx called inside the unit of work joins the running transaction.
+func (self *DatabaseClient) WithTx(ctx context.Context, unitOfWork func(tx services.DatabaseClient) error) error {
+	if self.tx != nil {
+		return unitOfWork(self)
+	}
+
+	self.shared.mutex.Lock()
+	defer self.shared.mutex.Unlock()
+
+	tx := &DatabaseClient{shared: self.shared, tx: self.shared.store.clone(), now: self.now}
+	if err := unitOfWork(tx); err != nil {
+		return err
+	}
+	self.shared.store = tx.tx
+
+	return nil
+}
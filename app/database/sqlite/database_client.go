@@ -0,0 +1,615 @@
+// Package sqlite implements the database client storing the books and the authors in SQLite,
+// so the whole service runs as the single binary without the external database.
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// Condition of the conditional write matching the row of the version argument unless it is models.AnyVersion.
+const versionCondition = `(:version=0 OR version=:version)`
+
+// Query template to create author.
+var createAuthorQuery = `INSERT INTO authors (id, name, created_at) VALUES (:id, :name, :created_at) RETURNING version`
+
+// Columns selected to scan the book into bookRow, the missing ISBN is selected as the empty string
+// and the contributors as the JSON array ordered by position, the deleted authors are not listed.
+const bookColumns = `id, title, COALESCE(isbn, '') AS isbn, publication_date, description, language, page_count, created_at, updated_at, version,
+(SELECT json_group_array(json_object('id', id, 'name', name, 'role', role)) FROM (
+SELECT authors.id, authors.name, book_authors.role FROM book_authors
+JOIN authors ON authors.id=book_authors.author_id AND authors.deleted_at IS NULL
+WHERE book_authors.book_id=books.id ORDER BY book_authors.position
+)) AS contributors`
+
+// Query template to create book.
+var createBookQuery = `INSERT INTO books (id, title, isbn, publication_date, description, language, page_count, created_at, updated_at)
+VALUES (:id, :title, NULLIF(:isbn, ''), :publication_date, :description, :language, :page_count, :created_at, :updated_at)
+RETURNING created_at, updated_at, version`
+
+// Query template to add contributor to book unless its author is in the trash, the missing author
+// is inserted so that the foreign key rejects it.
+var createContributorQuery = `INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT :book_id, :author_id, :role, :position
+WHERE NOT EXISTS (SELECT 1 FROM authors WHERE id=:author_id AND deleted_at IS NOT NULL)`
+
+// Query template to delete contributors of book.
+var deleteContributorsQuery = `DELETE FROM book_authors WHERE book_id=:book_id`
+
+// Query template to get book by id.
+var getBookByIdQuery = `SELECT ` + bookColumns + ` FROM books WHERE id=:book_id AND deleted_at IS NULL`
+
+// Query template to get book by ISBN.
+var getBookByISBNQuery = `SELECT ` + bookColumns + ` FROM books WHERE isbn=:isbn AND deleted_at IS NULL`
+
+// Query template to get author by id.
+var getAuthorByIdQuery = `SELECT id, name, version FROM authors WHERE id=:author_id AND deleted_at IS NULL`
+
+// Query to get the page of author's books.
+var getBooksByAuthorIdQuery = pageQuery{
+	table:        "books",
+	columns:      bookColumns,
+	prefixColumn: "title",
+	conditions: []string{
+		"deleted_at IS NULL",
+		`id IN (SELECT book_id FROM book_authors JOIN authors ON authors.id=book_authors.author_id
+WHERE book_authors.author_id=:author_id AND authors.deleted_at IS NULL)`,
+	},
+	sortOrders: bookSortOrders,
+}
+
+// Query to get the page of authors.
+var getAuthorsQuery = pageQuery{
+	table:        "authors",
+	columns:      "id, name, version",
+	prefixColumn: "name",
+	conditions:   []string{"deleted_at IS NULL"},
+	sortOrders:   authorSortOrders,
+}
+
+// Query template to update author.
+var updateAuthorQuery = `UPDATE authors SET name=:name, version=version+1 WHERE id=:id AND deleted_at IS NULL AND ` + versionCondition + `
+RETURNING version`
+
+// Query template to increment the versions of the books the author contributes to.
+var touchAuthorsBooksQuery = `UPDATE books SET version=version+1, updated_at=:updated_at
+WHERE id IN (SELECT book_id FROM book_authors WHERE author_id=:author_id)`
+
+// Query template to count books not in the trash which have no contributors except the author and the authors in the trash.
+var countOrphanedBooksQuery = `SELECT count(DISTINCT book_authors.book_id) FROM book_authors
+JOIN books ON books.id=book_authors.book_id AND books.deleted_at IS NULL
+WHERE book_authors.author_id=:author_id AND NOT EXISTS (
+SELECT 1 FROM book_authors AS others JOIN authors ON authors.id=others.author_id
+WHERE others.book_id=book_authors.book_id AND others.author_id<>:author_id AND authors.deleted_at IS NULL
+)`
+
+// Query template to move author to the trash, the author is kept in book_authors, so the restored author
+// contributes to the books again.
+var deleteAuthorQuery = `UPDATE authors SET deleted_at=:deleted_at
+WHERE id=:author_id AND deleted_at IS NULL AND ` + versionCondition
+
+// Query to get the page of books.
+var getBooksQuery = pageQuery{
+	table:        "books",
+	columns:      bookColumns,
+	prefixColumn: "title",
+	conditions:   []string{"deleted_at IS NULL"},
+	sortOrders:   bookSortOrders,
+}
+
+// Query to search books by title and authors by name, bm25 is negated so the most relevant rank is the highest.
+// The full-text indexes keep the rows in the trash, so they are filtered out by id.
+var searchQuery = pageQuery{
+	table: `(SELECT 'book' AS type, id, title, highlight(books_search, 0, '<b>', '</b>') AS snippet, -bm25(books_search) AS rank
+FROM books_search WHERE books_search MATCH :query AND id IN (SELECT id FROM books WHERE deleted_at IS NULL)
+UNION ALL
+SELECT 'author' AS type, id, name, highlight(authors_search, 0, '<b>', '</b>'), -bm25(authors_search)
+FROM authors_search WHERE authors_search MATCH :query AND id IN (SELECT id FROM authors WHERE deleted_at IS NULL)) AS results`,
+	columns:      "type, id, title, snippet, rank",
+	prefixColumn: "title",
+	sortOrders:   searchSortOrders,
+}
+
+// Query template to update book.
+var updateBookQuery = `UPDATE books SET title=:title, isbn=NULLIF(:isbn, ''), publication_date=:publication_date,
+description=:description, language=:language, page_count=:page_count, updated_at=:updated_at, version=version+1
+WHERE id=:id AND deleted_at IS NULL AND ` + versionCondition + ` RETURNING created_at, updated_at, version`
+
+// Query template to move book to the trash.
+var deleteBookQuery = `UPDATE books SET deleted_at=:deleted_at WHERE id=:book_id AND deleted_at IS NULL AND ` + versionCondition
+
+// Query template to get the version of book, it tells the missing book from the version mismatch.
+var getBookVersionQuery = `SELECT version FROM books WHERE id=:id AND deleted_at IS NULL`
+
+// Query template to get the version of author, it tells the missing author from the version mismatch.
+var getAuthorVersionQuery = `SELECT version FROM authors WHERE id=:id AND deleted_at IS NULL`
+
+// Query template to restore book from the trash.
+var restoreBookQuery = `UPDATE books SET deleted_at=NULL, updated_at=:updated_at, version=version+1
+WHERE id=:book_id AND deleted_at IS NOT NULL`
+
+// Query template to restore author from the trash.
+var restoreAuthorQuery = `UPDATE authors SET deleted_at=NULL, version=version+1 WHERE id=:author_id AND deleted_at IS NOT NULL`
+
+// Query to get the page of the books and the authors in the trash.
+var getTrashQuery = pageQuery{
+	table: `(SELECT 'book' AS type, id, title, deleted_at FROM books WHERE deleted_at IS NOT NULL
+UNION ALL
+SELECT 'author' AS type, id, name, deleted_at FROM authors WHERE deleted_at IS NOT NULL) AS items`,
+	columns:      "type, id, title, deleted_at",
+	prefixColumn: "title",
+	sortOrders:   trashSortOrders,
+}
+
+// Query templates to delete the books and the authors moved to the trash before the time,
+// the foreign keys remove them from book_authors.
+var (
+	purgeBooksQuery   = `DELETE FROM books WHERE deleted_at<:deleted_before`
+	purgeAuthorsQuery = `DELETE FROM authors WHERE deleted_at<:deleted_before`
+)
+
+type DatabaseClient struct {
+	// db runs the statements, it is the transaction inside WithTx.
+	db sqlx.ExtContext
+	// conn begins the transactions, it is nil inside WithTx.
+	conn *sqlx.DB
+	// now returns the time of the writes, the timestamps are stored in UTC to be ordered as text.
+	now func() time.Time
+}
+
+// NewDatabaseClient returns the client of the database migrated by migrations.SQLite.
+func NewDatabaseClient(db *sqlx.DB) *DatabaseClient {
+	return &DatabaseClient{db: db, conn: db, now: func() time.Time {
+		return time.Now().UTC()
+	}}
+}
+
+type createAuthorArguments struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// CreateAuthor creates the author and returns it with the version set by the database.
+func (self *DatabaseClient) CreateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	err := getNamed(ctx, self.db, authorEntity, &author.Version, createAuthorQuery, createAuthorArguments{
+		ID:        author.ID,
+		Name:      author.Name,
+		CreatedAt: self.now(),
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return author, nil
+}
+
+type writeBookArguments struct {
+	ID              uuid.UUID  `db:"id"`
+	Title           string     `db:"title"`
+	ISBN            string     `db:"isbn"`
+	PublicationDate *time.Time `db:"publication_date"`
+	Description     string     `db:"description"`
+	Language        string     `db:"language"`
+	PageCount       int        `db:"page_count"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+	Version         int64      `db:"version"`
+}
+
+func newWriteBookArguments(book models.Book, now time.Time) writeBookArguments {
+	return writeBookArguments{
+		ID:              book.ID,
+		Title:           book.Title,
+		ISBN:            book.ISBN,
+		PublicationDate: book.PublicationDate,
+		Description:     book.Description,
+		Language:        book.Language,
+		PageCount:       book.PageCount,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		Version:         book.Version,
+	}
+}
+
+// CreateBook creates the book with its contributors and returns it with the timestamps and the version set.
+func (self *DatabaseClient) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		var err error
+		book, err = tx.writeBook(ctx, createBookQuery, book)
+		return err
+	})
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+// CreateBooks creates the books in the single transaction and returns them with the timestamps and the versions set.
+func (self *DatabaseClient) CreateBooks(ctx context.Context, books []models.Book) ([]models.Book, error) {
+	created := make([]models.Book, 0, len(books))
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		created = created[:0]
+		for _, book := range books {
+			book, err := tx.writeBook(ctx, createBookQuery, book)
+			if err != nil {
+				return err
+			}
+			created = append(created, book)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+type getBookArguments struct {
+	BookId uuid.UUID `db:"book_id"`
+}
+
+func (self *DatabaseClient) GetBookById(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	return self.getBook(ctx, getBookByIdQuery, getBookArguments{
+		BookId: bookId,
+	})
+}
+
+type getBookByISBNArguments struct {
+	ISBN string `db:"isbn"`
+}
+
+// GetBookByISBN returns the book by the normalized ISBN-13.
+func (self *DatabaseClient) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	return self.getBook(ctx, getBookByISBNQuery, getBookByISBNArguments{
+		ISBN: isbn,
+	})
+}
+
+func (self *DatabaseClient) getBook(ctx context.Context, query string, arguments any) (models.Book, error) {
+	var row bookRow
+	if err := getNamed(ctx, self.db, bookEntity, &row, query, arguments); err != nil {
+		return models.Book{}, err
+	}
+
+	return row.item(), nil
+}
+
+type getAuthorArguments struct {
+	AuthorId uuid.UUID `db:"author_id"`
+}
+
+func (self *DatabaseClient) GetAuthorById(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	var row authorRow
+	err := getNamed(ctx, self.db, authorEntity, &row, getAuthorByIdQuery, getAuthorArguments{
+		AuthorId: authorId,
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return row.item(), nil
+}
+
+func (self *DatabaseClient) GetBooksByAuthorId(
+	ctx context.Context,
+	authorId uuid.UUID,
+	options models.ListOptions,
+) (models.Page[models.Book], error) {
+	return queryPage[models.Book, bookRow](ctx, self.db, getBooksByAuthorIdQuery, options, pageArguments{AuthorId: authorId})
+}
+
+func (self *DatabaseClient) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	return queryPage[models.Author, authorRow](ctx, self.db, getAuthorsQuery, options, pageArguments{})
+}
+
+type updateAuthorArguments struct {
+	ID      uuid.UUID `db:"id"`
+	Name    string    `db:"name"`
+	Version int64     `db:"version"`
+}
+
+type touchAuthorsBooksArguments struct {
+	AuthorId  uuid.UUID `db:"author_id"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// UpdateAuthor updates the author of the expected version, increments the versions of the books
+// it contributes to and returns the author with the incremented version.
+func (self *DatabaseClient) UpdateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		err := getNamed(ctx, tx.db, authorEntity, &author.Version, updateAuthorQuery, updateAuthorArguments{
+			ID:      author.ID,
+			Name:    author.Name,
+			Version: author.Version,
+		})
+		if err != nil {
+			return tx.conditionalWriteError(ctx, err, authorEntity, getAuthorVersionQuery, author.ID, author.Version)
+		}
+		_, err = sqlx.NamedExecContext(ctx, tx.db, touchAuthorsBooksQuery, touchAuthorsBooksArguments{
+			AuthorId:  author.ID,
+			UpdatedAt: tx.now(),
+		})
+		return translateError(err)
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return author, nil
+}
+
+type deleteAuthorArguments struct {
+	AuthorId  uuid.UUID `db:"author_id"`
+	Version   int64     `db:"version"`
+	DeletedAt time.Time `db:"deleted_at"`
+}
+
+// DeleteAuthor moves the author of the expected version to the trash, increments the versions of the books
+// it contributed to and returns the number of books left without an author.
+func (self *DatabaseClient) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	var orphanedBooks int64
+	arguments := deleteAuthorArguments{AuthorId: authorId, Version: version, DeletedAt: self.now()}
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		if err := getNamed(ctx, tx.db, authorEntity, &orphanedBooks, countOrphanedBooksQuery, arguments); err != nil {
+			return err
+		}
+		_, err := sqlx.NamedExecContext(ctx, tx.db, touchAuthorsBooksQuery, touchAuthorsBooksArguments{
+			AuthorId:  authorId,
+			UpdatedAt: tx.now(),
+		})
+		if err != nil {
+			return translateError(err)
+		}
+		err = execNamed(ctx, tx.db, authorEntity, deleteAuthorQuery, arguments)
+		return tx.conditionalWriteError(ctx, err, authorEntity, getAuthorVersionQuery, authorId, version)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return orphanedBooks, nil
+}
+
+func (self *DatabaseClient) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	return queryPage[models.Book, bookRow](ctx, self.db, getBooksQuery, options, pageArguments{})
+}
+
+// UpdateBook updates the book of the expected version, replaces its contributors and returns it
+// with the timestamps and the version set.
+func (self *DatabaseClient) UpdateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		updated, err := tx.writeBook(ctx, updateBookQuery, book)
+		if err != nil {
+			return tx.conditionalWriteError(ctx, err, bookEntity, getBookVersionQuery, book.ID, book.Version)
+		}
+		book = updated
+		return nil
+	})
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+type contributorArguments struct {
+	BookId   uuid.UUID `db:"book_id"`
+	AuthorId uuid.UUID `db:"author_id"`
+	Role     string    `db:"role"`
+	Position int       `db:"position"`
+}
+
+// writeBook runs the query returning the timestamps and the version of the written book and replaces
+// its contributors, it must run in the transaction.
+func (self *DatabaseClient) writeBook(ctx context.Context, query string, book models.Book) (models.Book, error) {
+	var row writtenBookRow
+	if err := getNamed(ctx, self.db, bookEntity, &row, query, newWriteBookArguments(book, self.now())); err != nil {
+		return models.Book{}, err
+	}
+	book.CreatedAt = row.CreatedAt
+	book.UpdatedAt = row.UpdatedAt
+	book.Version = row.Version
+
+	_, err := sqlx.NamedExecContext(ctx, self.db, deleteContributorsQuery, getBookArguments{BookId: book.ID})
+	if err != nil {
+		return models.Book{}, translateError(err)
+	}
+	for index, contributor := range book.Contributors {
+		result, err := sqlx.NamedExecContext(ctx, self.db, createContributorQuery, contributorArguments{
+			BookId:   book.ID,
+			AuthorId: contributor.Author.ID,
+			Role:     contributor.Role,
+			Position: index + 1,
+		})
+		if err != nil {
+			return models.Book{}, translateError(err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return models.Book{}, err
+		}
+		if inserted == 0 {
+			return models.Book{}, fmt.Errorf("%w: author %s is in the trash", models.ErrValidation, contributor.Author.ID)
+		}
+	}
+
+	return book, nil
+}
+
+type deleteBookArguments struct {
+	BookId    uuid.UUID `db:"book_id"`
+	Version   int64     `db:"version"`
+	DeletedAt time.Time `db:"deleted_at"`
+}
+
+// DeleteBook moves the book of the expected version to the trash.
+func (self *DatabaseClient) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	err := execNamed(ctx, self.db, bookEntity, deleteBookQuery, deleteBookArguments{
+		BookId:    bookId,
+		Version:   version,
+		DeletedAt: self.now(),
+	})
+	return self.conditionalWriteError(ctx, err, bookEntity, getBookVersionQuery, bookId, version)
+}
+
+type getVersionArguments struct {
+	ID uuid.UUID `db:"id"`
+}
+
+// conditionalWriteError returns the error of the conditional write. The write of the expected version
+// matching no rows fails with models.VersionMismatchError if the entity exists and with models.NotFoundError otherwise.
+func (self *DatabaseClient) conditionalWriteError(
+	ctx context.Context,
+	err error,
+	entity string,
+	versionQuery string,
+	id uuid.UUID,
+	version int64,
+) error {
+	if version == models.AnyVersion || !errors.Is(err, models.ErrNotFound) {
+		return err
+	}
+	var currentVersion int64
+	if err = getNamed(ctx, self.db, entity, &currentVersion, versionQuery, getVersionArguments{ID: id}); err != nil {
+		return err
+	}
+
+	return models.VersionMismatchError{Entity: entity}
+}
+
+// Search returns the books and the authors matching the query, ordered by rank. The words of the query
+// are matched as the FTS5 strings, so the documents must contain all of them and the operators are ignored.
+func (self *DatabaseClient) Search(
+	ctx context.Context,
+	search models.SearchQuery,
+	options models.ListOptions,
+) (models.Page[models.SearchResult], error) {
+	query := searchQuery
+	if search.Type != "" {
+		query.conditions = []string{"type=:type"}
+	}
+	words := strings.FieldsFunc(search.Text, func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+	if len(words) == 0 {
+		// FTS5 rejects the empty query, while the empty PostgreSQL one matches nothing.
+		if _, _, err := query.build(options, pageArguments{}); err != nil {
+			return models.Page[models.SearchResult]{}, err
+		}
+		return models.Page[models.SearchResult]{}, nil
+	}
+	for index, word := range words {
+		words[index] = `"` + word + `"`
+	}
+
+	return queryPage[models.SearchResult, searchResultRow](
+		ctx, self.db, query, options, pageArguments{Query: strings.Join(words, " "), Type: search.Type},
+	)
+}
+
+type restoreBookArguments struct {
+	BookId    uuid.UUID `db:"book_id"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// RestoreBook restores the book from the trash and returns it with the incremented version.
+func (self *DatabaseClient) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	var book models.Book
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		err := execNamed(ctx, tx.db, bookEntity, restoreBookQuery, restoreBookArguments{
+			BookId:    bookId,
+			UpdatedAt: tx.now(),
+		})
+		if err != nil {
+			return err
+		}
+		book, err = tx.GetBookById(ctx, bookId)
+		return err
+	})
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+// RestoreAuthor restores the author from the trash, increments the versions of the books it contributes to
+// and returns the author with the incremented version.
+func (self *DatabaseClient) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	var author models.Author
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		err := execNamed(ctx, tx.db, authorEntity, restoreAuthorQuery, getAuthorArguments{AuthorId: authorId})
+		if err != nil {
+			return err
+		}
+		_, err = sqlx.NamedExecContext(ctx, tx.db, touchAuthorsBooksQuery, touchAuthorsBooksArguments{
+			AuthorId:  authorId,
+			UpdatedAt: tx.now(),
+		})
+		if err != nil {
+			return translateError(err)
+		}
+		author, err = tx.GetAuthorById(ctx, authorId)
+		return err
+	})
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	return author, nil
+}
+
+// GetTrash returns the books and the authors in the trash, the last deleted first.
+func (self *DatabaseClient) GetTrash(
+	ctx context.Context,
+	trash models.TrashQuery,
+	options models.ListOptions,
+) (models.Page[models.TrashItem], error) {
+	query := getTrashQuery
+	if trash.Type != "" {
+		query.conditions = []string{"type=:type"}
+	}
+	return queryPage[models.TrashItem, trashItemRow](ctx, self.db, query, options, pageArguments{Type: trash.Type})
+}
+
+type purgeTrashArguments struct {
+	DeletedBefore time.Time `db:"deleted_before"`
+}
+
+// PurgeTrash deletes the books and the authors moved to the trash before the time and returns their number.
+func (self *DatabaseClient) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	arguments := purgeTrashArguments{DeletedBefore: deletedBefore.UTC()}
+	err := self.withTx(ctx, func(tx *DatabaseClient) error {
+		purged = 0
+		for _, query := range []string{purgeBooksQuery, purgeAuthorsQuery} {
+			result, err := sqlx.NamedExecContext(ctx, tx.db, query, arguments)
+			if err != nil {
+				return translateError(err)
+			}
+			affectedRows, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			purged += affectedRows
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/database"
+	"github.com/egormizerov/books/app/database/contract"
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/app/services"
+	"github.com/egormizerov/books/database/migrations"
+	"github.com/egormizerov/books/pkg/migrate"
+	"github.com/egormizerov/books/pkg/wrappers"
+)
+
+type DatabaseClientTests struct {
+	contract.DatabaseClientTests
+	db *sqlx.DB
+}
+
+// TestDatabaseClient runs the contract tests against the migrated in-memory database.
+func TestDatabaseClient(t *testing.T) {
+	tests := new(DatabaseClientTests)
+	tests.NewClient = func() services.DatabaseClient {
+		logger, _ := logrustest.NewNullLogger()
+		db, err := database.ConnectToDatabase(logger, &wrappers.SimpleSqlxWrapper{}, database.ConnectConfig{
+			Driver:     database.DriverSQLite,
+			SQLitePath: ":memory:",
+		})
+		tests.Require().NoError(err)
+		migrator, err := migrate.NewMigrator(db, migrations.SQLite())
+		tests.Require().NoError(err)
+		_, err = migrator.Up(context.Background())
+		tests.Require().NoError(err)
+
+		tests.db = db
+		return NewDatabaseClient(db)
+	}
+	suite.Run(t, tests)
+}
+
+func (self *DatabaseClientTests) TearDownTest() {
+	self.NoError(self.db.Close())
+}
+
+func (self *DatabaseClientTests) TestMigrationsRevert() {
+	migrator, err := migrate.NewMigrator(self.db, migrations.SQLite())
+	self.Require().NoError(err)
+
+	statuses, err := migrator.Status(context.Background())
+	self.Require().NoError(err)
+	for range statuses {
+		_, err = migrator.Down(context.Background())
+		self.NoError(err)
+	}
+
+	_, err = NewDatabaseClient(self.db).GetBooks(context.Background(), models.ListOptions{})
+	self.ErrorContains(err, "no such table")
+}
+
+func (self *DatabaseClientTests) TestMigrationsRevertSoftDeleteDeletesTrash() {
+	ctx := context.Background()
+	client := NewDatabaseClient(self.db)
+	author, err := client.CreateAuthor(ctx, models.Author{ID: uuid.New(), Name: "test_name"})
+	self.Require().NoError(err)
+	trashed, err := client.CreateAuthor(ctx, models.Author{ID: uuid.New(), Name: "test_trashed_name"})
+	self.Require().NoError(err)
+	book, err := client.CreateBook(ctx, models.Book{
+		ID:    uuid.New(),
+		Title: "test_title",
+		Contributors: []models.Contributor{
+			{Author: author, Role: models.ContributorRoleAuthor},
+			{Author: trashed, Role: models.ContributorRoleEditor},
+		},
+	})
+	self.Require().NoError(err)
+	_, err = client.DeleteAuthor(ctx, trashed.ID, models.AnyVersion)
+	self.Require().NoError(err)
+	_, err = client.CreateAuthor(ctx, models.Author{ID: uuid.New(), Name: trashed.Name})
+	self.Require().NoError(err)
+	migrator, err := migrate.NewMigrator(self.db, migrations.SQLite())
+	self.Require().NoError(err)
+
+	_, err = migrator.Down(ctx)
+	self.Require().NoError(err)
+	_, err = self.db.Exec(
+		"INSERT INTO authors (id, name, created_at) VALUES (?, ?, ?)",
+		uuid.New().String(), author.Name, time.Now(),
+	)
+	self.ErrorContains(err, "UNIQUE")
+	_, err = migrator.Up(ctx)
+	self.Require().NoError(err)
+
+	result, err := client.GetBookById(ctx, book.ID)
+	self.NoError(err)
+	self.Equal(
+		[]models.Contributor{{Author: models.Author{ID: author.ID, Name: author.Name}, Role: models.ContributorRoleAuthor}},
+		result.Contributors,
+	)
+	_, err = client.GetAuthorById(ctx, trashed.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// translateError converts constraint violations reported by SQLite into the domain errors.
+func translateError(err error) error {
+	var sqliteError *sqlite.Error
+	if !errors.As(err, &sqliteError) {
+		return err
+	}
+
+	switch sqliteError.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return fmt.Errorf("%w: %s", models.ErrConflict, sqliteError.Error())
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return fmt.Errorf("%w: %s", models.ErrValidation, sqliteError.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// sortOrder describes the column the keyset pagination is ordered by, ties are broken by the id column.
+type sortOrder struct {
+	column string
+	// sortValue is the SQL expression of the cursor value of the row, the timestamps are compared
+	// as the text they are stored as.
+	sortValue string
+	// cursorValue is the SQL expression converting the cursor value to the type of the column.
+	cursorValue string
+	descending  bool
+}
+
+var (
+	titleSortOrder     = sortOrder{column: "title", sortValue: "title", cursorValue: ":cursor_value"}
+	titleDescSortOrder = sortOrder{column: "title", sortValue: "title", cursorValue: ":cursor_value", descending: true}
+	nameSortOrder      = sortOrder{column: "name", sortValue: "name", cursorValue: ":cursor_value"}
+	nameDescSortOrder  = sortOrder{column: "name", sortValue: "name", cursorValue: ":cursor_value", descending: true}
+	createdAtSortOrder = sortOrder{column: "created_at", sortValue: "CAST(created_at AS text)", cursorValue: ":cursor_value"}
+	rankSortOrder      = sortOrder{column: "rank", sortValue: "rank", cursorValue: "CAST(:cursor_value AS real)", descending: true}
+	deletedAtSortOrder = sortOrder{
+		column:      "deleted_at",
+		sortValue:   "CAST(deleted_at AS text)",
+		cursorValue: ":cursor_value",
+		descending:  true,
+	}
+)
+
+// Sort orders supported by the book lists, the empty one is the default.
+var bookSortOrders = map[string]sortOrder{
+	"":           titleSortOrder,
+	"title":      titleSortOrder,
+	"-title":     titleDescSortOrder,
+	"created_at": createdAtSortOrder,
+}
+
+// Sort orders supported by the author lists, the empty one is the default.
+var authorSortOrders = map[string]sortOrder{
+	"":           nameSortOrder,
+	"name":       nameSortOrder,
+	"-name":      nameDescSortOrder,
+	"created_at": createdAtSortOrder,
+}
+
+// Search results are always ordered by rank, the most relevant first.
+var searchSortOrders = map[string]sortOrder{
+	"": rankSortOrder,
+}
+
+// Trash is always ordered by the deletion time, the last deleted first.
+var trashSortOrders = map[string]sortOrder{
+	"": deletedAtSortOrder,
+}
+
+// pageQuery describes the keyset paginated query.
+type pageQuery struct {
+	table   string
+	columns string
+	// prefixColumn is the column filtered by ListOptions.Prefix.
+	prefixColumn string
+	conditions   []string
+	sortOrders   map[string]sortOrder
+}
+
+type pageArguments struct {
+	AuthorId    uuid.UUID `db:"author_id"`
+	Query       string    `db:"query"`
+	Type        string    `db:"type"`
+	Prefix      string    `db:"prefix"`
+	CursorValue string    `db:"cursor_value"`
+	CursorId    uuid.UUID `db:"cursor_id"`
+	Limit       int       `db:"limit"`
+}
+
+// cursor is the position of the last item of the page.
+type cursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeCursor(value cursor) string {
+	cursorJson, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(cursorJson)
+}
+
+func decodeCursor(encoded string) (cursor, error) {
+	invalidCursorError := models.ValidationError{Field: "cursor", Message: "cursor is invalid"}
+	cursorJson, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor{}, invalidCursorError
+	}
+	var value cursor
+	if err = json.Unmarshal(cursorJson, &value); err != nil {
+		return cursor{}, invalidCursorError
+	}
+
+	return value, nil
+}
+
+// build returns the SQL of the query and its arguments for the requested page.
+// One extra row is requested to find out if the next page exists. The prefix is compared
+// by substr because LIKE of SQLite ignores the case.
+func (self pageQuery) build(options models.ListOptions, arguments pageArguments) (string, pageArguments, error) {
+	order, ok := self.sortOrders[options.Sort]
+	if !ok {
+		return "", pageArguments{}, models.ValidationError{Field: "sort", Message: "sort order is not supported"}
+	}
+	if options.Limit < 0 || options.Limit > models.MaxPageLimit {
+		return "", pageArguments{}, models.ValidationError{
+			Field:   "limit",
+			Message: fmt.Sprintf("limit must be between 1 and %d", models.MaxPageLimit),
+		}
+	}
+	arguments.Limit = options.Limit
+	if arguments.Limit == 0 {
+		arguments.Limit = models.DefaultPageLimit
+	}
+	arguments.Limit++
+
+	comparison, direction := ">", "ASC"
+	if order.descending {
+		comparison, direction = "<", "DESC"
+	}
+	conditions := append([]string{}, self.conditions...)
+	if options.Prefix != "" {
+		conditions = append(conditions, fmt.Sprintf("substr(%s, 1, length(:prefix))=:prefix", self.prefixColumn))
+		arguments.Prefix = options.Prefix
+	}
+	if options.Cursor != "" {
+		position, err := decodeCursor(options.Cursor)
+		if err != nil {
+			return "", pageArguments{}, err
+		}
+		if position.Sort != options.Sort {
+			return "", pageArguments{}, models.ValidationError{Field: "cursor", Message: "cursor does not match the sort order"}
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, :cursor_id)", order.column, comparison, order.cursorValue))
+		arguments.CursorValue = position.Value
+		arguments.CursorId = position.ID
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s AS sort_value FROM %s", self.columns, order.sortValue, self.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT :limit", order.column, direction, direction)
+
+	return query, arguments, nil
+}
+
+// pageRow is the row of the page query converted to the item of the page.
+type pageRow[T any] interface {
+	item() T
+	cursor(sort string) cursor
+}
+
+// queryPage runs the page query and scans its rows into R.
+func queryPage[T any, R pageRow[T]](
+	ctx context.Context,
+	db sqlx.ExtContext,
+	query pageQuery,
+	options models.ListOptions,
+	arguments pageArguments,
+) (models.Page[T], error) {
+	sql, arguments, err := query.build(options, arguments)
+	if err != nil {
+		return models.Page[T]{}, err
+	}
+	var rows []R
+	if err = selectNamed(ctx, db, &rows, sql, arguments); err != nil {
+		return models.Page[T]{}, err
+	}
+
+	var page models.Page[T]
+	for index, row := range rows {
+		if index == arguments.Limit-1 {
+			page.NextCursor = encodeCursor(rows[index-1].cursor(options.Sort))
+			break
+		}
+		page.Items = append(page.Items, row.item())
+	}
+
+	return page, nil
+}
@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+// Names of the entities reported by models.NotFoundError.
+const (
+	bookEntity   = "book"
+	authorEntity = "author"
+)
+
+// bookRow is the row of bookColumns, SortValue is selected by the page queries only.
+type bookRow struct {
+	ID              uuid.UUID          `db:"id"`
+	Title           string             `db:"title"`
+	ISBN            string             `db:"isbn"`
+	PublicationDate *time.Time         `db:"publication_date"`
+	Description     string             `db:"description"`
+	Language        string             `db:"language"`
+	PageCount       int                `db:"page_count"`
+	CreatedAt       time.Time          `db:"created_at"`
+	UpdatedAt       time.Time          `db:"updated_at"`
+	Version         int64              `db:"version"`
+	Contributors    contributorsColumn `db:"contributors"`
+	SortValue       string             `db:"sort_value"`
+}
+
+func (self bookRow) item() models.Book {
+	return models.Book{
+		ID:              self.ID,
+		Title:           self.Title,
+		Contributors:    self.Contributors,
+		ISBN:            self.ISBN,
+		PublicationDate: self.PublicationDate,
+		Description:     self.Description,
+		Language:        self.Language,
+		PageCount:       self.PageCount,
+		CreatedAt:       self.CreatedAt,
+		UpdatedAt:       self.UpdatedAt,
+		Version:         self.Version,
+	}
+}
+
+func (self bookRow) cursor(sort string) cursor {
+	return cursor{Sort: sort, Value: self.SortValue, ID: self.ID}
+}
+
+// authorRow is the row of the authors table, SortValue is selected by the page queries only.
+type authorRow struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	Version   int64     `db:"version"`
+	SortValue string    `db:"sort_value"`
+}
+
+func (self authorRow) item() models.Author {
+	return models.Author{ID: self.ID, Name: self.Name, Version: self.Version}
+}
+
+func (self authorRow) cursor(sort string) cursor {
+	return cursor{Sort: sort, Value: self.SortValue, ID: self.ID}
+}
+
+type searchResultRow struct {
+	Type      string    `db:"type"`
+	ID        uuid.UUID `db:"id"`
+	Title     string    `db:"title"`
+	Snippet   string    `db:"snippet"`
+	Rank      float64   `db:"rank"`
+	SortValue string    `db:"sort_value"`
+}
+
+func (self searchResultRow) item() models.SearchResult {
+	return models.SearchResult{
+		Type:    self.Type,
+		ID:      self.ID,
+		Title:   self.Title,
+		Snippet: self.Snippet,
+		Rank:    self.Rank,
+	}
+}
+
+func (self searchResultRow) cursor(sort string) cursor {
+	return cursor{Sort: sort, Value: self.SortValue, ID: self.ID}
+}
+
+type trashItemRow struct {
+	Type      string    `db:"type"`
+	ID        uuid.UUID `db:"id"`
+	Title     string    `db:"title"`
+	DeletedAt time.Time `db:"deleted_at"`
+	SortValue string    `db:"sort_value"`
+}
+
+func (self trashItemRow) item() models.TrashItem {
+	return models.TrashItem{
+		Type:      self.Type,
+		ID:        self.ID,
+		Title:     self.Title,
+		DeletedAt: self.DeletedAt,
+	}
+}
+
+func (self trashItemRow) cursor(sort string) cursor {
+	return cursor{Sort: sort, Value: self.SortValue, ID: self.ID}
+}
+
+type contributorRow struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Role string    `json:"role"`
+}
+
+// contributorsColumn scans the JSON array of the book contributors.
+type contributorsColumn []models.Contributor
+
+func (self *contributorsColumn) Scan(src any) error {
+	var data []byte
+	switch value := src.(type) {
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("unsupported type of contributors: %T", src)
+	}
+
+	var rows []contributorRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+	*self = nil
+	for _, row := range rows {
+		*self = append(*self, models.Contributor{
+			Author: models.Author{ID: row.ID, Name: row.Name},
+			Role:   row.Role,
+		})
+	}
+
+	return nil
+}
+
+// writtenBookRow is the row returned by the statements writing the book.
+type writtenBookRow struct {
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	Version   int64     `db:"version"`
+}
+
+// getNamed scans the only row of the named query into the destination,
+// entity is reported by models.NotFoundError if the query returns no rows.
+func getNamed(ctx context.Context, db sqlx.ExtContext, entity string, destination any, query string, arguments any) error {
+	query, values, err := db.BindNamed(query, arguments)
+	if err != nil {
+		return err
+	}
+	err = sqlx.GetContext(ctx, db, destination, query, values...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.NotFoundError{Entity: entity}
+	}
+
+	return translateError(err)
+}
+
+// selectNamed scans all the rows of the named query into the destination slice.
+func selectNamed(ctx context.Context, db sqlx.ExtContext, destination any, query string, arguments any) error {
+	query, values, err := db.BindNamed(query, arguments)
+	if err != nil {
+		return err
+	}
+
+	return translateError(sqlx.SelectContext(ctx, db, destination, query, values...))
+}
+
+// execNamed runs the named statement, entity is reported by models.NotFoundError if the statement
+// affects no rows.
+func execNamed(ctx context.Context, db sqlx.ExtContext, entity string, query string, arguments any) error {
+	result, err := sqlx.NamedExecContext(ctx, db, query, arguments)
+	if err != nil {
+		return translateError(err)
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return models.NotFoundError{Entity: entity}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/egormizerov/books/app/services"
+)
+
+// WithTx runs the unit of work in the transaction, it is committed if the unit of work succeeds and
+// rolled back if it fails or panics. The transactions of SQLite are serializable and take the write lock
+// when they begin, so they never fail to serialize. WithTx called inside the unit of work joins
+// the running transaction.
+func (self *DatabaseClient) WithTx(ctx context.Context, unitOfWork func(tx services.DatabaseClient) error) error {
+	return self.withTx(ctx, func(tx *DatabaseClient) error {
+		return unitOfWork(tx)
+	})
+}
+
+// withTx is WithTx passing the client of the transaction to the statements of this package.
+func (self *DatabaseClient) withTx(ctx context.Context, unitOfWork func(tx *DatabaseClient) error) error {
+	if self.conn == nil {
+		return unitOfWork(self)
+	}
+
+	tx, err := self.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = unitOfWork(&DatabaseClient{db: tx, now: self.now}); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return nil
+}
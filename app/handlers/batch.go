@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+var ErrInvalidBatchSize = fmt.Sprintf("Batch must contain between 1 and %d items.", models.MaxBatchSize)
+
+// Modes of the batch selected by the mode query parameter.
+const (
+	// BatchModeAtomic creates either all the items of the batch or none of them, it is the default.
+	BatchModeAtomic = "atomic"
+	// BatchModePartial creates the valid items of the batch and reports the failed ones.
+	BatchModePartial = "partial"
+)
+
+// BatchItemResponseBody is the result of the item of the batch, ID is set if the item was created
+// and Error otherwise.
+type BatchItemResponseBody struct {
+	Index  int      `json:"index"`
+	Status int      `json:"status"`
+	ID     string   `json:"id,omitempty"`
+	Error  *Problem `json:"error,omitempty"`
+}
+
+type BatchResponseBody struct {
+	Results []BatchItemResponseBody `json:"results"`
+}
+
+func (self *BatchItemResponseBody) fail(problem Problem) {
+	self.Status = problem.Status
+	self.Error = &problem
+}
+
+// CreateBooks creates the batch of books. It responds with 201 if all the books were created,
+// with 422 if the atomic batch was rejected and with 207 if only some books of the partial batch were created.
+func (self *Handler) CreateBooks(response http.ResponseWriter, request *http.Request) {
+	mode := request.URL.Query().Get("mode")
+	if mode == "" {
+		mode = BatchModeAtomic
+	}
+	if mode != BatchModeAtomic && mode != BatchModePartial {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidQueryParameters, ProblemField{
+			Field:   "mode",
+			Message: fmt.Sprintf("must be %s or %s", BatchModeAtomic, BatchModePartial),
+		})
+		return
+	}
+	var inputs []CreateBookRequestBody
+	if err := json.NewDecoder(request.Body).Decode(&inputs); err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidInputBody)
+		return
+	}
+	if len(inputs) == 0 || len(inputs) > models.MaxBatchSize {
+		writeProblem(response, request, http.StatusUnprocessableEntity, ErrInvalidBatchSize)
+		return
+	}
+
+	atomic := mode == BatchModeAtomic
+	results := make([]BatchItemResponseBody, len(inputs))
+	books := make([]models.BookInput, 0, len(inputs))
+	indexes := make([]int, 0, len(inputs))
+	for index, input := range inputs {
+		results[index].Index = index
+		if err := self.validator.Struct(input); err != nil {
+			results[index].fail(newProblem(http.StatusUnprocessableEntity, ErrInvalidInputBody, validationFields(input, err)...))
+			continue
+		}
+		books = append(books, models.BookInput{
+			Title:        input.Title,
+			Contributors: contributors(input.Contributors),
+			Details:      input.details(),
+		})
+		indexes = append(indexes, index)
+	}
+
+	if atomic && len(books) < len(inputs) {
+		for _, index := range indexes {
+			results[index].fail(serviceErrorProblem(models.ErrBatchAborted, ErrCreateBook))
+		}
+	} else if len(books) > 0 {
+		bookResults, err := self.service.CreateBooks(request.Context(), books, atomic)
+		if err != nil {
+			writeServiceError(response, request, err, ErrCreateBooks)
+			return
+		}
+		for position, result := range bookResults {
+			item := &results[indexes[position]]
+			if result.Err != nil {
+				item.fail(serviceErrorProblem(result.Err, ErrCreateBook))
+				continue
+			}
+			item.Status = http.StatusCreated
+			item.ID = result.Book.ID.String()
+		}
+	}
+
+	writeBatch(response, request, atomic, results)
+}
+
+// writeBatch responds with the results of the batch and the status code summarizing them.
+func writeBatch(response http.ResponseWriter, request *http.Request, atomic bool, results []BatchItemResponseBody) {
+	status := http.StatusCreated
+	for _, result := range results {
+		if result.Status == http.StatusCreated {
+			continue
+		}
+		status = http.StatusMultiStatus
+		if atomic {
+			status = http.StatusUnprocessableEntity
+		}
+		break
+	}
+
+	bodyJson, err := json.Marshal(BatchResponseBody{Results: results})
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrCreateBooks)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	_, _ = response.Write(bodyJson)
+}
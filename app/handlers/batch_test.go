@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+const EndpointCreateBooks = "/api/books:batch"
+
+// bookInput returns the input passed to the service for the request body of the batch.
+func (self *HandlerTests) bookInput(title string) models.BookInput {
+	return models.BookInput{Title: title, Contributors: self.contributors()}
+}
+
+func (self *HandlerTests) batchResponseBody(body []byte) BatchResponseBody {
+	var responseBody BatchResponseBody
+	self.Require().NoError(json.Unmarshal(body, &responseBody))
+	return responseBody
+}
+
+func (self *HandlerTests) TestCreateBooksErrorIfModeInvalid() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks+"?mode=some", nil)
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), `"field":"mode"`)
+}
+
+func (self *HandlerTests) TestCreateBooksErrorIfJsonDecodeFailed() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks, CreateBookRequestBody{})
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidInputBody)
+}
+
+func (self *HandlerTests) TestCreateBooksErrorIfEmpty() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks, []CreateBookRequestBody{})
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidBatchSize)
+}
+
+func (self *HandlerTests) TestCreateBooksErrorIfTooLarge() {
+	requestBody := make([]CreateBookRequestBody, models.MaxBatchSize+1)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks, requestBody)
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidBatchSize)
+}
+
+func (self *HandlerTests) TestCreateBooks() {
+	otherBook := models.Book{ID: uuid.New(), Title: "test_title_2"}
+	requestBody := []CreateBookRequestBody{
+		{Title: self.book.Title, Contributors: self.contributorsRequestBody()},
+		{Title: otherBook.Title, Contributors: self.contributorsRequestBody()},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks, requestBody)
+	self.serviceMock.
+		On("CreateBooks", request.Context(), []models.BookInput{self.bookInput(self.book.Title), self.bookInput(otherBook.Title)}, true).
+		Return([]models.BookResult{{Book: self.book}, {Book: otherBook}}, nil)
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusCreated, response.Code)
+	self.Equal(BatchResponseBody{Results: []BatchItemResponseBody{
+		{Index: 0, Status: http.StatusCreated, ID: self.book.ID.String()},
+		{Index: 1, Status: http.StatusCreated, ID: otherBook.ID.String()},
+	}}, self.batchResponseBody(response.Body.Bytes()))
+}
+
+func (self *HandlerTests) TestCreateBooksAtomicRejectedIfItemInvalid() {
+	requestBody := []CreateBookRequestBody{
+		{Title: self.book.Title, Contributors: self.contributorsRequestBody()},
+		{Contributors: self.contributorsRequestBody()},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks+"?mode=atomic", requestBody)
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Equal(BatchResponseBody{Results: []BatchItemResponseBody{
+		{
+			Index:  0,
+			Status: http.StatusFailedDependency,
+			Error: &Problem{
+				Type:   "about:blank",
+				Title:  http.StatusText(http.StatusFailedDependency),
+				Status: http.StatusFailedDependency,
+				Detail: ErrBatchAborted,
+			},
+		},
+		{
+			Index:  1,
+			Status: http.StatusUnprocessableEntity,
+			Error: &Problem{
+				Type:   "about:blank",
+				Title:  http.StatusText(http.StatusUnprocessableEntity),
+				Status: http.StatusUnprocessableEntity,
+				Detail: ErrInvalidInputBody,
+				Errors: []ProblemField{{Field: "title", Message: "must not be empty"}},
+			},
+		},
+	}}, self.batchResponseBody(response.Body.Bytes()))
+	self.serviceMock.AssertNotCalled(self.T(), "CreateBooks", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (self *HandlerTests) TestCreateBooksAtomicRejectedIfItemConflicted() {
+	requestBody := []CreateBookRequestBody{
+		{Title: self.book.Title, Contributors: self.contributorsRequestBody()},
+		{Title: "test_title_2", Contributors: self.contributorsRequestBody()},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks, requestBody)
+	self.serviceMock.
+		On("CreateBooks", request.Context(), []models.BookInput{self.bookInput(self.book.Title), self.bookInput("test_title_2")}, true).
+		Return([]models.BookResult{
+			{Err: models.ErrBatchAborted},
+			{Err: fmt.Errorf("%w: duplicate isbn", models.ErrConflict)},
+		}, nil)
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	results := self.batchResponseBody(response.Body.Bytes()).Results
+	self.Require().Len(results, 2)
+	self.Equal(http.StatusFailedDependency, results[0].Status)
+	self.Equal(ErrBatchAborted, results[0].Error.Detail)
+	self.Equal(http.StatusConflict, results[1].Status)
+	self.Equal(ErrResourceConflict, results[1].Error.Detail)
+}
+
+func (self *HandlerTests) TestCreateBooksPartial() {
+	requestBody := []CreateBookRequestBody{
+		{Contributors: self.contributorsRequestBody()},
+		{Title: self.book.Title, Contributors: self.contributorsRequestBody()},
+		{Title: "test_title_2", Contributors: self.contributorsRequestBody()},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks+"?mode=partial", requestBody)
+	self.serviceMock.
+		On("CreateBooks", request.Context(), []models.BookInput{self.bookInput(self.book.Title), self.bookInput("test_title_2")}, false).
+		Return([]models.BookResult{
+			{Book: self.book},
+			{Err: fmt.Errorf("failed to create book: %w", models.ErrConflict)},
+		}, nil)
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusMultiStatus, response.Code)
+	results := self.batchResponseBody(response.Body.Bytes()).Results
+	self.Require().Len(results, 3)
+	self.Equal(http.StatusUnprocessableEntity, results[0].Status)
+	self.Equal(BatchItemResponseBody{Index: 1, Status: http.StatusCreated, ID: self.book.ID.String()}, results[1])
+	self.Equal(http.StatusConflict, results[2].Status)
+	self.Equal(ErrResourceConflict, results[2].Error.Detail)
+}
+
+func (self *HandlerTests) TestCreateBooksErrorIfServiceFailed() {
+	requestBody := []CreateBookRequestBody{{Title: self.book.Title, Contributors: self.contributorsRequestBody()}}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBooks, requestBody)
+	self.serviceMock.
+		On("CreateBooks", request.Context(), []models.BookInput{self.bookInput(self.book.Title)}, true).
+		Return(nil, fmt.Errorf("failed to create books: %w", self.testError))
+
+	self.handler.CreateBooks(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrCreateBooks)
+}
+
+func (self *HandlerTests) TestCreateBooksIsRouted() {
+	requestBody := []CreateBookRequestBody{{Title: self.book.Title, Contributors: self.contributorsRequestBody()}}
+	response, request := self.getRequestAndResponse(http.MethodPost, EndpointCreateBooks, requestBody)
+	self.serviceMock.
+		On("CreateBooks", mock.Anything, []models.BookInput{self.bookInput(self.book.Title)}, true).
+		Return([]models.BookResult{{Book: self.book}}, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusCreated, response.Code)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+var (
+	ErrPreconditionRequired = "Request must set the If-Match header to the ETag of the resource."
+	ErrPreconditionFailed   = "Resource was modified since it was read, get it again and retry."
+)
+
+// entityTag returns the strong entity tag of the version of the resource.
+func entityTag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setValidators sets the ETag of the version of the resource and the Last-Modified header unless it is zero.
+func setValidators(response http.ResponseWriter, version int64, lastModified time.Time) {
+	response.Header().Set("ETag", entityTag(version))
+	if !lastModified.IsZero() {
+		response.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// writeNotModified responds with 304 Not Modified if the representation cached by the client is current.
+// If-None-Match takes precedence over If-Modified-Since, the latter is ignored if lastModified is zero.
+func writeNotModified(response http.ResponseWriter, request *http.Request, version int64, lastModified time.Time) bool {
+	if !notModified(request, version, lastModified) {
+		return false
+	}
+
+	setValidators(response, version, lastModified)
+	response.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+func notModified(request *http.Request, version int64, lastModified time.Time) bool {
+	if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		etag := entityTag(version)
+		for _, tag := range entityTags(ifNoneMatch) {
+			// If-None-Match uses the weak comparison, so the weak tags match the strong ones.
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(request.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	// Last-Modified has the precision of seconds.
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// expectedVersion returns the version of the resource the write is conditional on. The If-Match header
+// is required, "*" matches any version and the single strong ETag matches its version. The weak ETags
+// never match, and neither do the lists, as the write is conditional on the single version.
+func expectedVersion(request *http.Request) (int64, *Problem) {
+	ifMatch := request.Header.Get("If-Match")
+	if ifMatch == "" {
+		problem := newProblem(http.StatusPreconditionRequired, ErrPreconditionRequired)
+		return 0, &problem
+	}
+
+	tags := entityTags(ifMatch)
+	if len(tags) == 1 && tags[0] == "*" {
+		return models.AnyVersion, nil
+	}
+	if len(tags) == 1 && strings.HasPrefix(tags[0], `"`) && strings.HasSuffix(tags[0], `"`) {
+		version, err := strconv.ParseInt(strings.Trim(tags[0], `"`), 10, 64)
+		if err == nil && version > models.AnyVersion {
+			return version, nil
+		}
+	}
+
+	problem := newProblem(http.StatusPreconditionFailed, ErrPreconditionFailed)
+	return 0, &problem
+}
+
+// entityTags splits the comma separated list of the entity tags.
+func entityTags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+var (
+	ErrResourceNotFound = "Requested resource was not found."
+	ErrResourceConflict = "Resource conflicts with an existing one."
+	ErrEndpointNotFound = "Requested endpoint was not found."
+	ErrBatchAborted     = "Item was not created because another item of the batch failed."
+)
+
+// Content type of the error responses, see RFC 7807.
+const problemContentType = "application/problem+json"
+
+// Problem is the error response body described by RFC 7807.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []ProblemField `json:"errors,omitempty"`
+}
+
+// ProblemField describes the invalid field of the request body.
+type ProblemField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func newProblem(status int, detail string, fields ...ProblemField) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Errors: fields,
+	}
+}
+
+// writeProblem responds with the problem+json body for the request.
+func writeProblem(response http.ResponseWriter, request *http.Request, status int, detail string, fields ...ProblemField) {
+	problem := newProblem(status, detail, fields...)
+	problem.Instance = request.URL.Path
+	problemJson, err := json.Marshal(problem)
+	if err != nil {
+		http.Error(response, detail, status)
+		return
+	}
+
+	response.Header().Set("Content-Type", problemContentType)
+	response.Header().Set("X-Content-Type-Options", "nosniff")
+	response.WriteHeader(status)
+	_, _ = response.Write(problemJson)
+}
+
+// writeValidationProblem responds with the list of fields of the input that failed validation.
+func writeValidationProblem(response http.ResponseWriter, request *http.Request, input any, err error) {
+	writeProblem(response, request, http.StatusUnprocessableEntity, ErrInvalidInputBody, validationFields(input, err)...)
+}
+
+// validationFields returns the fields of the input that failed validation.
+func validationFields(input any, err error) []ProblemField {
+	var fields []ProblemField
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			fields = append(fields, ProblemField{
+				Field:   jsonFieldName(input, fieldError.StructNamespace()),
+				Message: validationMessage(fieldError),
+			})
+		}
+	}
+
+	return fields
+}
+
+// writeServiceError responds with the status code matching the domain error returned by the service,
+// message is used for unexpected errors.
+func writeServiceError(response http.ResponseWriter, request *http.Request, err error, message string) {
+	problem := serviceErrorProblem(err, message)
+	writeProblem(response, request, problem.Status, problem.Detail, problem.Errors...)
+}
+
+// serviceErrorProblem returns the problem matching the domain error returned by the service,
+// message is used for unexpected errors.
+func serviceErrorProblem(err error, message string) Problem {
+	var validationError models.ValidationError
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return newProblem(http.StatusNotFound, ErrResourceNotFound)
+	case errors.Is(err, models.ErrConflict):
+		return newProblem(http.StatusConflict, ErrResourceConflict)
+	case errors.Is(err, models.ErrVersionMismatch):
+		return newProblem(http.StatusPreconditionFailed, ErrPreconditionFailed)
+	case errors.As(err, &validationError):
+		return newProblem(http.StatusUnprocessableEntity, ErrInvalidInputBody, ProblemField{
+			Field:   validationError.Field,
+			Message: validationError.Message,
+		})
+	case errors.Is(err, models.ErrValidation):
+		return newProblem(http.StatusUnprocessableEntity, ErrInvalidInputBody)
+	case errors.Is(err, models.ErrBatchAborted):
+		return newProblem(http.StatusFailedDependency, ErrBatchAborted)
+	default:
+		return newProblem(http.StatusInternalServerError, message)
+	}
+}
+
+// jsonFieldName returns the path of the input struct field as it appears in the request body,
+// namespace is the struct namespace of the field error such as CreateBookRequestBody.Contributors[0].AuthorID.
+func jsonFieldName(input any, namespace string) string {
+	fieldType := reflect.TypeOf(input)
+	var path []string
+	for _, segment := range strings.Split(namespace, ".")[1:] {
+		for fieldType != nil && fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		name, index, indexed := strings.Cut(segment, "[")
+		var field reflect.StructField
+		found := false
+		if fieldType != nil && fieldType.Kind() == reflect.Struct {
+			field, found = fieldType.FieldByName(name)
+		}
+		if !found {
+			path = append(path, segment)
+			fieldType = nil
+			continue
+		}
+
+		fieldType = field.Type
+		// Fields of the embedded structs are promoted to the parent object.
+		if field.Anonymous {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "" || jsonName == "-" {
+			jsonName = name
+		}
+		if indexed {
+			jsonName += "[" + index
+			fieldType = fieldType.Elem()
+		}
+		path = append(path, jsonName)
+	}
+
+	return strings.Join(path, ".")
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "must not be empty"
+	case "uuid":
+		return "must be a valid UUID"
+	case "datetime":
+		return fmt.Sprintf("must be a date in the %s format", fieldError.Param())
+	case "min":
+		return fmt.Sprintf("must contain at least %s items", fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fieldError.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fieldError.Param())
+	default:
+		return fmt.Sprintf("must satisfy the '%s' rule", fieldError.Tag())
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+func TestWriteProblem(t *testing.T) {
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+
+	writeProblem(response, request, http.StatusBadRequest, ErrInvalidInputBody)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, problemContentType, response.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "Invalid input body.",
+		"instance": "/api/books"
+	}`, response.Body.String())
+}
+
+func TestWriteValidationProblem(t *testing.T) {
+	input := CreateBookRequestBody{Title: "test_title"}
+	err := validator.New().Struct(input)
+	response := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/books", nil)
+
+	writeValidationProblem(response, request, input, err)
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, []ProblemField{{Field: "contributors", Message: "must not be empty"}}, problem.Errors)
+}
+
+func TestWriteServiceError(t *testing.T) {
+	testCases := []struct {
+		err             error
+		expectedStatus  int
+		expectedMessage string
+		expectedFields  []ProblemField
+	}{
+		{fmt.Errorf("wrapped: %w", models.ErrNotFound), http.StatusNotFound, ErrResourceNotFound, nil},
+		{fmt.Errorf("wrapped: %w", models.ErrConflict), http.StatusConflict, ErrResourceConflict, nil},
+		{
+			fmt.Errorf("wrapped: %w", models.VersionMismatchError{Entity: "book"}),
+			http.StatusPreconditionFailed,
+			ErrPreconditionFailed,
+			nil,
+		},
+		{
+			fmt.Errorf("wrapped: %w", models.ValidationError{Field: "name", Message: "test_message"}),
+			http.StatusUnprocessableEntity,
+			ErrInvalidInputBody,
+			[]ProblemField{{Field: "name", Message: "test_message"}},
+		},
+		{fmt.Errorf("wrapped: %w", models.ErrValidation), http.StatusUnprocessableEntity, ErrInvalidInputBody, nil},
+		{errors.New("test_error"), http.StatusInternalServerError, ErrGetBook, nil},
+	}
+
+	for _, testCase := range testCases {
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+
+		writeServiceError(response, request, testCase.err, ErrGetBook)
+
+		var problem Problem
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &problem))
+		assert.Equal(t, testCase.expectedStatus, response.Code)
+		assert.Equal(t, testCase.expectedStatus, problem.Status)
+		assert.Equal(t, testCase.expectedMessage, problem.Detail)
+		assert.Equal(t, testCase.expectedFields, problem.Errors)
+	}
+}
+
+func TestJsonFieldName(t *testing.T) {
+	assert.Equal(t, "title", jsonFieldName(CreateBookRequestBody{}, "CreateBookRequestBody.Title"))
+	assert.Equal(t, "title", jsonFieldName(&CreateBookRequestBody{}, "CreateBookRequestBody.Title"))
+	assert.Equal(t, "contributors[1].author_id",
+		jsonFieldName(CreateBookRequestBody{}, "CreateBookRequestBody.Contributors[1].AuthorID"))
+	assert.Equal(t, "publication_date",
+		jsonFieldName(CreateBookRequestBody{}, "CreateBookRequestBody.BookDetailsRequestBody.PublicationDate"))
+	assert.Equal(t, "Unknown.Field", jsonFieldName(CreateBookRequestBody{}, "CreateBookRequestBody.Unknown.Field"))
+}
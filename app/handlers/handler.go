@@ -5,15 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"regexp"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
 	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/pkg/health"
+	"github.com/egormizerov/books/pkg/metrics"
+	"github.com/egormizerov/books/pkg/router"
 )
 
+// Layout of the publication date in the request bodies.
+const publicationDateLayout = "2006-01-02"
+
 var (
 	ErrInvalidInputBody     = "Invalid input body."
 	ErrInvalidPathVariables = "Invalid path variables."
@@ -22,174 +28,593 @@ var (
 	ErrCreateAuthor    = "We could not create new author. Please try again."
 	ErrGetAuthorsBooks = "We could not get author's books. Please try again."
 	ErrCreateBook      = "We could not create new book. Please try again."
+	ErrCreateBooks     = "We could not create new books. Please try again."
 	ErrGetBook         = "We could not get book. Please try again."
+	ErrGetAuthors      = "We could not get authors. Please try again."
+	ErrGetAuthor       = "We could not get author. Please try again."
+	ErrUpdateAuthor    = "We could not update author. Please try again."
+	ErrDeleteAuthor    = "We could not delete author. Please try again."
+	ErrGetBooks        = "We could not get books. Please try again."
+	ErrUpdateBook      = "We could not update book. Please try again."
+	ErrDeleteBook      = "We could not delete book. Please try again."
+	ErrSearch          = "We could not search. Please try again."
+	ErrRestoreBook     = "We could not restore book. Please try again."
+	ErrRestoreAuthor   = "We could not restore author. Please try again."
+	ErrGetTrash        = "We could not get trash. Please try again."
 
-	EndpointCreateAuthorMatcher    = regexp.MustCompile("^/api/authors$")
-	EndpointGetAuthorsBooksMatcher = regexp.MustCompile("^/api/authors/(.{36})/books/$")
-	EndpointCreateBookMatcher      = regexp.MustCompile("^/api/books$")
-	EndpointGetBookMatcher         = regexp.MustCompile("^/api/books/(.{36})$")
+	LocationAuthor = "/api/authors/%s"
+	LocationBook   = "/api/books/%s"
 )
 
 //go:generate mockery --name=Service
 type Service interface {
-	CreateAuthor(ctx context.Context, authorName string) error
-	CreateBook(ctx context.Context, title string, authorId uuid.UUID) error
+	CreateAuthor(ctx context.Context, authorName string) (models.Author, error)
+	CreateBook(
+		ctx context.Context,
+		title string,
+		contributors []models.Contributor,
+		details models.BookDetails,
+	) (models.Book, error)
+	CreateBooks(ctx context.Context, books []models.BookInput, atomic bool) ([]models.BookResult, error)
 	GetBook(ctx context.Context, bookId uuid.UUID) (models.Book, error)
-	GetAuthorsBooks(ctx context.Context, authorId uuid.UUID) ([]models.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string) (models.Book, error)
+	GetAuthorsBooks(ctx context.Context, authorId uuid.UUID, options models.ListOptions) (models.Page[models.Book], error)
+	GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error)
+	GetAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error)
+	UpdateAuthor(ctx context.Context, authorId uuid.UUID, authorName string, version int64) (models.Author, error)
+	DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error)
+	GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error)
+	UpdateBook(
+		ctx context.Context,
+		bookId uuid.UUID,
+		title string,
+		contributors []models.Contributor,
+		details models.BookDetails,
+		version int64,
+	) (models.Book, error)
+	DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error
+	Search(ctx context.Context, text string, resultType string, options models.ListOptions) (models.Page[models.SearchResult], error)
+	RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error)
+	RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error)
+	GetTrash(ctx context.Context, itemType string, options models.ListOptions) (models.Page[models.TrashItem], error)
 }
 
 type Handler struct {
 	service   Service
 	logger    *logrus.Logger
 	validator *validator.Validate
+	registry  *metrics.Registry
+	health    *health.Health
+	router    *router.Router
 }
 
-func NewHandler(logger *logrus.Logger, service Service, validator *validator.Validate) *Handler {
-	return &Handler{
+func NewHandler(
+	logger *logrus.Logger,
+	service Service,
+	validator *validator.Validate,
+	registry *metrics.Registry,
+	health *health.Health,
+) *Handler {
+	handler := &Handler{
 		service:   service,
 		logger:    logger,
 		validator: validator,
+		registry:  registry,
+		health:    health,
 	}
+	handler.router = handler.routes()
+
+	return handler
+}
+
+// routes returns the router dispatching the requests to the endpoints of the handler.
+func (self *Handler) routes() *router.Router {
+	routes := router.New()
+	routes.Use(MiddlewareRequestID, MiddlewareLogger(self.logger), MiddlewareAccessLog)
+	// The recovery runs inside the metrics middleware, so the panics are observed as internal server errors.
+	routes.UseRoute(newHTTPMetrics(self.registry).middleware, func(string) router.Middleware {
+		return MiddlewareRecovery
+	})
+	routes.NotFound = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		writeProblem(response, request, http.StatusNotFound, ErrEndpointNotFound)
+	})
+	routes.MethodNotAllowed = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		writeProblem(response, request, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+	})
+
+	routes.Handle(http.MethodGet, "/metrics", self.registry)
+	routes.HandleFunc(http.MethodGet, "/livez", self.health.Live)
+	routes.HandleFunc(http.MethodGet, "/readyz", self.health.Ready)
+
+	api := routes.Group("/api")
+
+	authors := api.Group("/authors")
+	authors.HandleFunc(http.MethodPost, "", self.CreateAuthor)
+	authors.HandleFunc(http.MethodGet, "", self.GetAuthors)
+	authors.HandleFunc(http.MethodGet, "/{id}", self.GetAuthor)
+	authors.HandleFunc(http.MethodPut, "/{id}", self.UpdateAuthor)
+	authors.HandleFunc(http.MethodPatch, "/{id}", self.UpdateAuthor)
+	authors.HandleFunc(http.MethodDelete, "/{id}", self.DeleteAuthor)
+	authors.HandleFunc(http.MethodGet, "/{id}/books", self.GetAuthorsBooks)
+	authors.HandleFunc(http.MethodPost, "/{id}:restore", self.RestoreAuthor)
+
+	api.HandleFunc(http.MethodPost, "/books:batch", self.CreateBooks)
+
+	books := api.Group("/books")
+	books.HandleFunc(http.MethodPost, "", self.CreateBook)
+	books.HandleFunc(http.MethodGet, "", self.GetBooks)
+	books.HandleFunc(http.MethodGet, "/isbn/{isbn}", self.GetBookByISBN)
+	books.HandleFunc(http.MethodGet, "/{id}", self.GetBook)
+	books.HandleFunc(http.MethodPut, "/{id}", self.UpdateBook)
+	books.HandleFunc(http.MethodPatch, "/{id}", self.UpdateBook)
+	books.HandleFunc(http.MethodDelete, "/{id}", self.DeleteBook)
+	books.HandleFunc(http.MethodPost, "/{id}:restore", self.RestoreBook)
+
+	api.HandleFunc(http.MethodGet, "/search", self.Search)
+	api.HandleFunc(http.MethodGet, "/trash", self.GetTrash)
+
+	return routes
 }
 
 func (self *Handler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
-	request = MiddlewareLoggerInContext(request, self.logger)
-
-	switch request.Method {
-	case http.MethodGet:
-		if EndpointGetBookMatcher.MatchString(request.URL.Path) {
-			self.GetBook(response, request)
-			return
-		}
-		if EndpointGetAuthorsBooksMatcher.MatchString(request.URL.Path) {
-			self.GetAuthorsBooks(response, request)
-			return
-		}
-	case http.MethodPost:
-		if EndpointCreateAuthorMatcher.MatchString(request.URL.Path) {
-			self.CreateAuthor(response, request)
-			return
-		}
-		if EndpointCreateBookMatcher.MatchString(request.URL.Path) {
-			self.CreateBook(response, request)
-			return
-		}
-	case http.MethodOptions:
-		response.Header().Set("Allow", "GET, POST, OPTIONS")
-		response.WriteHeader(http.StatusNoContent)
-		return
-	default:
-		response.Header().Set("Allow", "GET, POST, OPTIONS")
-		http.Error(response, ErrMethodNotAllowed, http.StatusMethodNotAllowed)
-		return
-	}
-
-	http.NotFound(response, request)
+	self.router.ServeHTTP(response, request)
 }
 
 type CreateAuthorRequestBody struct {
-	Name string `validate:"required"`
+	Name string `json:"name" validate:"required"`
 }
 
 func (self *Handler) CreateAuthor(response http.ResponseWriter, request *http.Request) {
 	var input CreateAuthorRequestBody
 	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
-		http.Error(response, ErrCreateAuthor, http.StatusInternalServerError)
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidInputBody)
 		return
 	}
 	if err := self.validator.Struct(input); err != nil {
-		http.Error(response, ErrInvalidInputBody, http.StatusUnprocessableEntity)
+		writeValidationProblem(response, request, input, err)
 		return
 	}
 
-	if err := self.service.CreateAuthor(request.Context(), input.Name); err != nil {
-		http.Error(response, ErrCreateAuthor, http.StatusInternalServerError)
+	author, err := self.service.CreateAuthor(request.Context(), input.Name)
+	if err != nil {
+		writeServiceError(response, request, err, ErrCreateAuthor)
 		return
 	}
 
+	authorJson, err := json.Marshal(author)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrCreateAuthor)
+		return
+	}
+	response.Header().Set("Location", fmt.Sprintf(LocationAuthor, author.ID.String()))
+	setValidators(response, author.Version, time.Time{})
 	response.WriteHeader(http.StatusCreated)
+	_, _ = response.Write(authorJson)
 }
 
 func (self *Handler) GetAuthorsBooks(response http.ResponseWriter, request *http.Request) {
-	pathComponents := EndpointGetAuthorsBooksMatcher.FindStringSubmatch(request.URL.Path)
-	if len(pathComponents) < 2 || pathComponents[1] == "" {
-		http.Error(response, ErrInvalidPathVariables, http.StatusUnprocessableEntity)
+	authorId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
 		return
 	}
-	authorId, err := uuid.Parse(pathComponents[1])
-	if err != nil {
-		http.Error(response, ErrGetAuthorsBooks, http.StatusInternalServerError)
+
+	options, invalidField := parseListOptions(request, "title_prefix")
+	if invalidField != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidQueryParameters, *invalidField)
 		return
 	}
 
-	books, err := self.service.GetAuthorsBooks(request.Context(), authorId)
+	books, err := self.service.GetAuthorsBooks(request.Context(), authorId, options)
 	if err != nil {
-		http.Error(response, ErrGetAuthorsBooks, http.StatusInternalServerError)
+		writeServiceError(response, request, err, ErrGetAuthorsBooks)
 		return
 	}
 
-	if books == nil {
-		response.WriteHeader(http.StatusOK)
-		_, _ = response.Write([]byte("[]"))
-		return
+	writePage(response, request, books, ErrGetAuthorsBooks)
+}
+
+// BookDetailsRequestBody are the optional attributes of the created or updated book.
+type BookDetailsRequestBody struct {
+	ISBN            string `json:"isbn"`
+	PublicationDate string `json:"publication_date" validate:"omitempty,datetime=2006-01-02"`
+	Description     string `json:"description"`
+	Language        string `json:"language"`
+	PageCount       int    `json:"page_count" validate:"gte=0"`
+}
+
+func (self BookDetailsRequestBody) details() models.BookDetails {
+	details := models.BookDetails{
+		ISBN:        self.ISBN,
+		Description: self.Description,
+		Language:    self.Language,
+		PageCount:   self.PageCount,
 	}
-	booksJson, err := json.Marshal(books)
-	if err != nil {
-		http.Error(response, ErrGetAuthorsBooks, http.StatusInternalServerError)
-		return
+	// The format of the date is checked by the validator.
+	if publicationDate, err := time.Parse(publicationDateLayout, self.PublicationDate); err == nil {
+		details.PublicationDate = &publicationDate
 	}
-	response.WriteHeader(http.StatusOK)
-	_, _ = response.Write(booksJson)
+
+	return details
 }
 
-type CreateBookRequestBody struct {
-	Title    string `json:"title" validate:"required"`
+type ContributorRequestBody struct {
 	AuthorID string `json:"author_id" validate:"required,uuid"`
+	// Role is author if it is empty.
+	Role string `json:"role" validate:"omitempty,oneof=author editor translator illustrator"`
+}
+
+// contributors converts the validated request bodies to the book contributors.
+func contributors(input []ContributorRequestBody) []models.Contributor {
+	contributors := make([]models.Contributor, 0, len(input))
+	for _, contributor := range input {
+		contributors = append(contributors, models.Contributor{
+			Author: models.Author{ID: uuid.MustParse(contributor.AuthorID)},
+			Role:   contributor.Role,
+		})
+	}
+
+	return contributors
+}
+
+type CreateBookRequestBody struct {
+	Title        string                   `json:"title" validate:"required"`
+	Contributors []ContributorRequestBody `json:"contributors" validate:"required,min=1,dive"`
+	BookDetailsRequestBody
 }
 
 func (self *Handler) CreateBook(response http.ResponseWriter, request *http.Request) {
 	var input CreateBookRequestBody
 	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
-		http.Error(response, ErrCreateBook, http.StatusInternalServerError)
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidInputBody)
 		return
 	}
 	if err := self.validator.Struct(input); err != nil {
-		fmt.Println(input)
-		http.Error(response, ErrInvalidInputBody, http.StatusUnprocessableEntity)
+		writeValidationProblem(response, request, input, err)
 		return
 	}
 
-	if err := self.service.CreateBook(request.Context(), input.Title, uuid.MustParse(input.AuthorID)); err != nil {
-		http.Error(response, ErrCreateBook, http.StatusInternalServerError)
+	book, err := self.service.CreateBook(request.Context(), input.Title, contributors(input.Contributors), input.details())
+	if err != nil {
+		writeServiceError(response, request, err, ErrCreateBook)
 		return
 	}
 
+	bookJson, err := json.Marshal(book)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrCreateBook)
+		return
+	}
+	response.Header().Set("Location", fmt.Sprintf(LocationBook, book.ID.String()))
+	setValidators(response, book.Version, book.UpdatedAt)
 	response.WriteHeader(http.StatusCreated)
+	_, _ = response.Write(bookJson)
 }
 
 func (self *Handler) GetBook(response http.ResponseWriter, request *http.Request) {
-	pathComponents := EndpointGetBookMatcher.FindStringSubmatch(request.URL.Path)
-	if len(pathComponents) < 2 || pathComponents[1] == "" {
-		http.Error(response, ErrInvalidPathVariables, http.StatusUnprocessableEntity)
+	bookId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
 		return
 	}
-	bookId, err := uuid.Parse(pathComponents[1])
+
+	book, err := self.service.GetBook(request.Context(), bookId)
 	if err != nil {
-		http.Error(response, ErrGetBook, http.StatusInternalServerError)
+		writeServiceError(response, request, err, ErrGetBook)
+		return
+	}
+	if writeNotModified(response, request, book.Version, book.UpdatedAt) {
 		return
 	}
 
-	book, err := self.service.GetBook(request.Context(), bookId)
+	bookJson, err := json.Marshal(book)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrGetBook)
+		return
+	}
+	setValidators(response, book.Version, book.UpdatedAt)
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(bookJson)
+}
+
+func (self *Handler) GetBookByISBN(response http.ResponseWriter, request *http.Request) {
+	book, err := self.service.GetBookByISBN(request.Context(), router.PathValue(request, "isbn"))
+	if err != nil {
+		writeServiceError(response, request, err, ErrGetBook)
+		return
+	}
+	if writeNotModified(response, request, book.Version, book.UpdatedAt) {
+		return
+	}
+
+	bookJson, err := json.Marshal(book)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrGetBook)
+		return
+	}
+	setValidators(response, book.Version, book.UpdatedAt)
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(bookJson)
+}
+
+func (self *Handler) GetAuthors(response http.ResponseWriter, request *http.Request) {
+	options, invalidField := parseListOptions(request, "name_prefix")
+	if invalidField != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidQueryParameters, *invalidField)
+		return
+	}
+
+	authors, err := self.service.GetAuthors(request.Context(), options)
+	if err != nil {
+		writeServiceError(response, request, err, ErrGetAuthors)
+		return
+	}
+
+	writePage(response, request, authors, ErrGetAuthors)
+}
+
+func (self *Handler) GetAuthor(response http.ResponseWriter, request *http.Request) {
+	authorId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
+		return
+	}
+
+	author, err := self.service.GetAuthor(request.Context(), authorId)
+	if err != nil {
+		writeServiceError(response, request, err, ErrGetAuthor)
+		return
+	}
+	if writeNotModified(response, request, author.Version, time.Time{}) {
+		return
+	}
+
+	authorJson, err := json.Marshal(author)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrGetAuthor)
+		return
+	}
+	setValidators(response, author.Version, time.Time{})
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(authorJson)
+}
+
+type UpdateAuthorRequestBody struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// UpdateAuthor updates the author of the version matching the If-Match header.
+func (self *Handler) UpdateAuthor(response http.ResponseWriter, request *http.Request) {
+	authorId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
+		return
+	}
+	version, problem := expectedVersion(request)
+	if problem != nil {
+		writeProblem(response, request, problem.Status, problem.Detail)
+		return
+	}
+
+	var input UpdateAuthorRequestBody
+	if err = json.NewDecoder(request.Body).Decode(&input); err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidInputBody)
+		return
+	}
+	if err = self.validator.Struct(input); err != nil {
+		writeValidationProblem(response, request, input, err)
+		return
+	}
+
+	author, err := self.service.UpdateAuthor(request.Context(), authorId, input.Name, version)
+	if err != nil {
+		writeServiceError(response, request, err, ErrUpdateAuthor)
+		return
+	}
+
+	authorJson, err := json.Marshal(author)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrUpdateAuthor)
+		return
+	}
+	setValidators(response, author.Version, time.Time{})
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(authorJson)
+}
+
+type DeleteAuthorResponseBody struct {
+	OrphanedBooks int64 `json:"orphaned_books"`
+}
+
+// DeleteAuthor deletes the author of the version matching the If-Match header.
+func (self *Handler) DeleteAuthor(response http.ResponseWriter, request *http.Request) {
+	authorId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
+		return
+	}
+	version, problem := expectedVersion(request)
+	if problem != nil {
+		writeProblem(response, request, problem.Status, problem.Detail)
+		return
+	}
+
+	orphanedBooks, err := self.service.DeleteAuthor(request.Context(), authorId, version)
+	if err != nil {
+		writeServiceError(response, request, err, ErrDeleteAuthor)
+		return
+	}
+
+	responseJson, err := json.Marshal(DeleteAuthorResponseBody{OrphanedBooks: orphanedBooks})
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrDeleteAuthor)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(responseJson)
+}
+
+func (self *Handler) GetBooks(response http.ResponseWriter, request *http.Request) {
+	options, invalidField := parseListOptions(request, "title_prefix")
+	if invalidField != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidQueryParameters, *invalidField)
+		return
+	}
+
+	books, err := self.service.GetBooks(request.Context(), options)
+	if err != nil {
+		writeServiceError(response, request, err, ErrGetBooks)
+		return
+	}
+
+	writePage(response, request, books, ErrGetBooks)
+}
+
+type UpdateBookRequestBody struct {
+	Title        string                   `json:"title" validate:"required"`
+	Contributors []ContributorRequestBody `json:"contributors" validate:"required,min=1,dive"`
+	BookDetailsRequestBody
+}
+
+// UpdateBook updates the book of the version matching the If-Match header.
+func (self *Handler) UpdateBook(response http.ResponseWriter, request *http.Request) {
+	bookId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
+		return
+	}
+	version, problem := expectedVersion(request)
+	if problem != nil {
+		writeProblem(response, request, problem.Status, problem.Detail)
+		return
+	}
+
+	var input UpdateBookRequestBody
+	if err = json.NewDecoder(request.Body).Decode(&input); err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidInputBody)
+		return
+	}
+	if err = self.validator.Struct(input); err != nil {
+		writeValidationProblem(response, request, input, err)
+		return
+	}
+
+	book, err := self.service.UpdateBook(request.Context(), bookId, input.Title, contributors(input.Contributors), input.details(), version)
+	if err != nil {
+		writeServiceError(response, request, err, ErrUpdateBook)
+		return
+	}
+
+	bookJson, err := json.Marshal(book)
 	if err != nil {
-		http.Error(response, ErrGetBook, http.StatusInternalServerError)
+		writeProblem(response, request, http.StatusInternalServerError, ErrUpdateBook)
+		return
+	}
+	setValidators(response, book.Version, book.UpdatedAt)
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(bookJson)
+}
+
+// DeleteBook deletes the book of the version matching the If-Match header.
+func (self *Handler) DeleteBook(response http.ResponseWriter, request *http.Request) {
+	bookId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
+		return
+	}
+	version, problem := expectedVersion(request)
+	if problem != nil {
+		writeProblem(response, request, problem.Status, problem.Detail)
+		return
+	}
+
+	if err = self.service.DeleteBook(request.Context(), bookId, version); err != nil {
+		writeServiceError(response, request, err, ErrDeleteBook)
+		return
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// Search returns the books and the authors matching the "q" query parameter, "type" limits the results to books or authors.
+func (self *Handler) Search(response http.ResponseWriter, request *http.Request) {
+	options, invalidField := parseListOptions(request, "")
+	if invalidField != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidQueryParameters, *invalidField)
+		return
+	}
+
+	query := request.URL.Query()
+	results, err := self.service.Search(request.Context(), query.Get("q"), query.Get("type"), options)
+	if err != nil {
+		writeServiceError(response, request, err, ErrSearch)
+		return
+	}
+
+	writePage(response, request, results, ErrSearch)
+}
+
+// RestoreBook restores the deleted book from the trash.
+func (self *Handler) RestoreBook(response http.ResponseWriter, request *http.Request) {
+	bookId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
+		return
+	}
+
+	book, err := self.service.RestoreBook(request.Context(), bookId)
+	if err != nil {
+		writeServiceError(response, request, err, ErrRestoreBook)
 		return
 	}
 
 	bookJson, err := json.Marshal(book)
 	if err != nil {
-		http.Error(response, ErrGetBook, http.StatusInternalServerError)
+		writeProblem(response, request, http.StatusInternalServerError, ErrRestoreBook)
 		return
 	}
+	setValidators(response, book.Version, book.UpdatedAt)
 	response.WriteHeader(http.StatusOK)
 	_, _ = response.Write(bookJson)
 }
+
+// RestoreAuthor restores the deleted author from the trash.
+func (self *Handler) RestoreAuthor(response http.ResponseWriter, request *http.Request) {
+	authorId, err := uuid.Parse(router.PathValue(request, "id"))
+	if err != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidPathVariables)
+		return
+	}
+
+	author, err := self.service.RestoreAuthor(request.Context(), authorId)
+	if err != nil {
+		writeServiceError(response, request, err, ErrRestoreAuthor)
+		return
+	}
+
+	authorJson, err := json.Marshal(author)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, ErrRestoreAuthor)
+		return
+	}
+	setValidators(response, author.Version, time.Time{})
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(authorJson)
+}
+
+// GetTrash returns the deleted books and authors, the last deleted first. "type" limits the items to books or authors.
+func (self *Handler) GetTrash(response http.ResponseWriter, request *http.Request) {
+	options, invalidField := parseListOptions(request, "")
+	if invalidField != nil {
+		writeProblem(response, request, http.StatusBadRequest, ErrInvalidQueryParameters, *invalidField)
+		return
+	}
+
+	items, err := self.service.GetTrash(request.Context(), request.URL.Query().Get("type"), options)
+	if err != nil {
+		writeServiceError(response, request, err, ErrGetTrash)
+		return
+	}
+
+	writePage(response, request, items, ErrGetTrash)
+}
@@ -2,36 +2,57 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/egormizerov/books/app/handlers/mocks"
 	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/pkg/health"
 	logcontext "github.com/egormizerov/books/pkg/log/context"
+	"github.com/egormizerov/books/pkg/metrics"
+	"github.com/egormizerov/books/pkg/router"
 )
 
 var (
 	EndpointCreateAuthor    = "/api/authors"
-	EndpointGetAuthorsBooks = "/api/authors/%s/books/"
+	EndpointGetAuthorsBooks = "/api/authors/%s/books"
 	EndpointCreateBook      = "/api/books"
 	EndpointGetBook         = "/api/books/%s"
+	EndpointGetBookByISBN   = "/api/books/isbn/%s"
+	EndpointGetAuthors      = "/api/authors"
+	EndpointAuthor          = "/api/authors/%s"
+	EndpointGetBooks        = "/api/books"
+	EndpointSearch          = "/api/search"
+	EndpointRestoreBook     = "/api/books/%s:restore"
+	EndpointRestoreAuthor   = "/api/authors/%s:restore"
+	EndpointTrash           = "/api/trash"
+
+	testRequestID = "test_request_id"
+	// testETag is the ETag of the first version of the resource.
+	testETag = `"1"`
 )
 
 type HandlerTests struct {
 	suite.Suite
-	handler     Handler
+	handler     *Handler
 	serviceMock *mocks.Service
 	validator   *validator.Validate
 	logger      *logrus.Logger
+	registry    *metrics.Registry
+	health      *health.Health
 	book        models.Book
 	author      models.Author
 	testError   error
@@ -44,47 +65,63 @@ func TestHandler(t *testing.T) {
 func (self *HandlerTests) SetupTest() {
 	self.serviceMock = mocks.NewService(self.T())
 	self.logger = logrus.New()
+	self.logger.SetOutput(io.Discard)
 	self.validator = validator.New()
-	self.handler = Handler{
-		service:   self.serviceMock,
-		logger:    self.logger,
-		validator: self.validator,
-	}
+	self.registry = metrics.NewRegistry()
+	self.health = health.New(time.Second)
+	self.handler = NewHandler(self.logger, self.serviceMock, self.validator, self.registry, self.health)
 	self.author = models.Author{
-		ID:   uuid.New(),
-		Name: "test_name",
+		ID:      uuid.New(),
+		Name:    "test_name",
+		Version: models.FirstVersion,
 	}
 	self.book = models.Book{
-		ID:     uuid.New(),
-		Title:  "test_title",
-		Author: self.author,
+		ID:    uuid.New(),
+		Title: "test_title",
+		Contributors: []models.Contributor{
+			{Author: models.Author{ID: self.author.ID, Name: self.author.Name}, Role: models.ContributorRoleAuthor},
+		},
+		UpdatedAt: time.Date(2022, 10, 2, 3, 4, 5, 0, time.UTC),
+		Version:   models.FirstVersion,
 	}
 	self.testError = errors.New("test_error")
 }
 
-func (self *HandlerTests) TestNewHandler() {
-	result := NewHandler(self.logger, self.serviceMock, self.validator)
+func (self *HandlerTests) contributorsRequestBody() []ContributorRequestBody {
+	return []ContributorRequestBody{{AuthorID: self.author.ID.String()}}
+}
 
-	self.Equal(&Handler{
-		service:   self.serviceMock,
-		logger:    self.logger,
-		validator: self.validator,
-	}, result)
+// contributors returns the contributors passed to the service for contributorsRequestBody.
+func (self *HandlerTests) contributors() []models.Contributor {
+	return []models.Contributor{{Author: models.Author{ID: self.author.ID}}}
+}
+
+func (self *HandlerTests) TestNewHandler() {
+	result := NewHandler(self.logger, self.serviceMock, self.validator, self.registry, self.health)
+
+	self.Equal(self.serviceMock, result.service)
+	self.Equal(self.logger, result.logger)
+	self.Equal(self.validator, result.validator)
+	self.Equal(self.registry, result.registry)
+	self.Equal(self.health, result.health)
+	self.NotNil(result.router)
 }
 
 func (self *HandlerTests) TestServeHTTPCreateBook() {
 	requestBody := CreateBookRequestBody{
-		Title:    self.book.Title,
-		AuthorID: self.book.Author.ID.String(),
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
 	}
 	response, request := self.getRequestAndResponse(http.MethodPost, EndpointCreateBook, requestBody)
 	self.serviceMock.
-		On("CreateBook", self.requestWithLogger(request).Context(), requestBody.Title, uuid.MustParse(requestBody.AuthorID)).
-		Return(nil)
+		On("CreateBook", self.routedRequest(request).Context(), requestBody.Title, self.contributors(), models.BookDetails{}).
+		Return(self.book, nil)
 
 	self.handler.ServeHTTP(response, request)
 
 	self.Equal(http.StatusCreated, response.Code)
+	self.Equal(fmt.Sprintf(EndpointGetBook, self.book.ID.String()), response.Header().Get("Location"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
 }
 
 func (self *HandlerTests) TestServeHTTPCreateAuthor() {
@@ -93,19 +130,21 @@ func (self *HandlerTests) TestServeHTTPCreateAuthor() {
 	}
 	response, request := self.getRequestAndResponse(http.MethodPost, EndpointCreateAuthor, requestBody)
 	self.serviceMock.
-		On("CreateAuthor", self.requestWithLogger(request).Context(), requestBody.Name).
-		Return(nil)
+		On("CreateAuthor", self.routedRequest(request).Context(), requestBody.Name).
+		Return(self.author, nil)
 
 	self.handler.ServeHTTP(response, request)
 
 	self.Equal(http.StatusCreated, response.Code)
+	self.Equal(fmt.Sprintf(EndpointAuthor, self.author.ID.String()), response.Header().Get("Location"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
 }
 
 func (self *HandlerTests) TestServeHTTPGetBook() {
 	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
 	response, request := self.getRequestAndResponse(http.MethodGet, requestEndpoint, nil)
 	self.serviceMock.
-		On("GetBook", self.requestWithLogger(request).Context(), self.book.ID).
+		On("GetBook", self.routedRequest(request, router.Param{Name: "id", Value: self.book.ID.String()}).Context(), self.book.ID).
 		Return(self.book, nil)
 
 	self.handler.ServeHTTP(response, request)
@@ -119,8 +158,80 @@ func (self *HandlerTests) TestServeHTTPGetAuthorsBooks() {
 	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String())
 	response, request := self.getRequestAndResponse(http.MethodGet, requestEndpoint, nil)
 	self.serviceMock.
-		On("GetAuthorsBooks", self.requestWithLogger(request).Context(), self.author.ID).
-		Return(books, nil)
+		On("GetAuthorsBooks", self.routedRequest(request, router.Param{Name: "id", Value: self.author.ID.String()}).Context(), self.author.ID, models.ListOptions{}).
+		Return(models.Page[models.Book]{Items: books}, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(books)))
+}
+
+func (self *HandlerTests) TestServeHTTPGetAuthors() {
+	authors := []models.Author{self.author}
+	response, request := self.getRequestAndResponse(http.MethodGet, EndpointGetAuthors, nil)
+	self.serviceMock.
+		On("GetAuthors", self.routedRequest(request).Context(), models.ListOptions{}).
+		Return(models.Page[models.Author]{Items: authors}, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(authors)))
+}
+
+func (self *HandlerTests) TestServeHTTPGetAuthor() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponse(http.MethodGet, requestEndpoint, nil)
+	self.serviceMock.
+		On("GetAuthor", self.routedRequest(request, router.Param{Name: "id", Value: self.author.ID.String()}).Context(), self.author.ID).
+		Return(self.author, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
+}
+
+func (self *HandlerTests) TestServeHTTPUpdateAuthor() {
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		requestBody := UpdateAuthorRequestBody{
+			Name: self.author.Name,
+		}
+		requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+		response, request := self.getRequestAndResponse(method, requestEndpoint, requestBody)
+		request.Header.Set("If-Match", testETag)
+		self.serviceMock.
+			On("UpdateAuthor", self.routedRequest(request, router.Param{Name: "id", Value: self.author.ID.String()}).Context(), self.author.ID, requestBody.Name, models.FirstVersion).
+			Return(self.author, nil)
+
+		self.handler.ServeHTTP(response, request)
+
+		self.Equal(http.StatusOK, response.Code)
+		self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
+	}
+}
+
+func (self *HandlerTests) TestServeHTTPDeleteAuthor() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponse(http.MethodDelete, requestEndpoint, nil)
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("DeleteAuthor", self.routedRequest(request, router.Param{Name: "id", Value: self.author.ID.String()}).Context(), self.author.ID, models.FirstVersion).
+		Return(int64(1), nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(DeleteAuthorResponseBody{OrphanedBooks: 1})))
+}
+
+func (self *HandlerTests) TestServeHTTPGetBooks() {
+	books := []models.Book{self.book}
+	response, request := self.getRequestAndResponse(http.MethodGet, EndpointGetBooks, nil)
+	self.serviceMock.
+		On("GetBooks", self.routedRequest(request).Context(), models.ListOptions{}).
+		Return(models.Page[models.Book]{Items: books}, nil)
 
 	self.handler.ServeHTTP(response, request)
 
@@ -128,32 +239,172 @@ func (self *HandlerTests) TestServeHTTPGetAuthorsBooks() {
 	self.Contains(response.Body.String(), string(self.mustMarshal(books)))
 }
 
+func (self *HandlerTests) TestServeHTTPUpdateBook() {
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		requestBody := UpdateBookRequestBody{
+			Title:        self.book.Title,
+			Contributors: self.contributorsRequestBody(),
+		}
+		requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+		response, request := self.getRequestAndResponse(method, requestEndpoint, requestBody)
+		request.Header.Set("If-Match", testETag)
+		self.serviceMock.
+			On("UpdateBook", self.routedRequest(request, router.Param{Name: "id", Value: self.book.ID.String()}).Context(), self.book.ID, requestBody.Title, self.contributors(), models.BookDetails{}, models.FirstVersion).
+			Return(self.book, nil)
+
+		self.handler.ServeHTTP(response, request)
+
+		self.Equal(http.StatusOK, response.Code)
+		self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
+	}
+}
+
+func (self *HandlerTests) TestServeHTTPDeleteBook() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponse(http.MethodDelete, requestEndpoint, nil)
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("DeleteBook", self.routedRequest(request, router.Param{Name: "id", Value: self.book.ID.String()}).Context(), self.book.ID, models.FirstVersion).
+		Return(nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusNoContent, response.Code)
+}
+
+func (self *HandlerTests) TestServeHTTPSearch() {
+	results := []models.SearchResult{{Type: models.SearchResultTypeBook, ID: self.book.ID, Title: self.book.Title}}
+	response, request := self.getRequestAndResponse(http.MethodGet, EndpointSearch+"?q=test", nil)
+	self.serviceMock.
+		On("Search", self.routedRequest(request).Context(), "test", "", models.ListOptions{}).
+		Return(models.Page[models.SearchResult]{Items: results}, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(results)))
+}
+
 func (self *HandlerTests) TestServerHTTPOptions() {
-	response, request := self.getRequestAndResponse(http.MethodOptions, "/", nil)
+	response, request := self.getRequestAndResponse(http.MethodOptions, EndpointGetBooks, nil)
 
 	self.handler.ServeHTTP(response, request)
 
-	self.Equal("GET, POST, OPTIONS", response.Header().Get("Allow"))
+	self.Equal("GET, HEAD, OPTIONS, POST", response.Header().Get("Allow"))
 	self.Equal(http.StatusNoContent, response.Code)
 }
 
 func (self *HandlerTests) TestServerHTTPErrorIfMethodNotAllowed() {
-	response, request := self.getRequestAndResponse(http.MethodPut, "/", nil)
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponse(http.MethodPost, requestEndpoint, nil)
 
 	self.handler.ServeHTTP(response, request)
 
-	self.Equal("GET, POST, OPTIONS", response.Header().Get("Allow"))
+	self.Equal("DELETE, GET, HEAD, OPTIONS, PATCH, PUT", response.Header().Get("Allow"))
 	self.Equal(http.StatusMethodNotAllowed, response.Code)
+	self.Equal(problemContentType, response.Header().Get("Content-Type"))
 	self.Contains(response.Body.String(), ErrMethodNotAllowed)
 }
 
+func (self *HandlerTests) TestServeHTTPWithTrailingSlash() {
+	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String()) + "/"
+	response, request := self.getRequestAndResponse(http.MethodGet, requestEndpoint, nil)
+	self.serviceMock.
+		On("GetAuthorsBooks", self.routedRequest(request, router.Param{Name: "id", Value: self.author.ID.String()}).Context(), self.author.ID, models.ListOptions{}).
+		Return(models.Page[models.Book]{Items: []models.Book{}}, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+}
+
+func (self *HandlerTests) TestServeHTTPReturnsRequestID() {
+	response, request := self.getRequestAndResponse(http.MethodGet, "/", nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(testRequestID, response.Header().Get(RequestIDHeader))
+}
+
+func (self *HandlerTests) TestServeHTTPErrorIfHandlerPanics() {
+	response, request := self.getRequestAndResponse(http.MethodGet, EndpointGetBooks, nil)
+	self.serviceMock.
+		On("GetBooks", self.routedRequest(request).Context(), models.ListOptions{}).
+		Run(func(mock.Arguments) { panic("test_panic") })
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrInternalServerError)
+}
+
+func (self *HandlerTests) TestServeHTTPMetrics() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponse(http.MethodGet, requestEndpoint, nil)
+	self.serviceMock.
+		On("GetBook", self.routedRequest(request, router.Param{Name: "id", Value: self.book.ID.String()}).Context(), self.book.ID).
+		Return(models.Book{}, models.ErrNotFound)
+	self.handler.ServeHTTP(response, request)
+	response, request = self.getRequestAndResponse(http.MethodGet, "/unknown", nil)
+	self.handler.ServeHTTP(response, request)
+	response, request = self.getRequestAndResponse(http.MethodGet, "/metrics", nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(metrics.ContentType, response.Header().Get("Content-Type"))
+	self.Contains(response.Body.String(), `books_http_requests_total{method="GET",route="/api/books/{id}",status="404"} 1`)
+	self.Contains(response.Body.String(), `books_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	self.Contains(response.Body.String(), `books_http_request_duration_seconds_count{method="GET",route="/api/books/{id}",status="404"} 1`)
+}
+
+func (self *HandlerTests) TestServeHTTPMetricsLabelUnknownMethodAsOther() {
+	response, request := self.getRequestAndResponse("UNKNOWN", "/unknown", nil)
+	self.handler.ServeHTTP(response, request)
+	response, request = self.getRequestAndResponse(http.MethodGet, "/metrics", nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Contains(response.Body.String(), `books_http_requests_total{method="other",route="unmatched",status="404"} 1`)
+	self.NotContains(response.Body.String(), `method="UNKNOWN"`)
+}
+
+func (self *HandlerTests) TestServeHTTPLivez() {
+	response, request := self.getRequestAndResponse(http.MethodGet, "/livez", nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.JSONEq(`{"status":"ok"}`, response.Body.String())
+}
+
+func (self *HandlerTests) TestServeHTTPReadyz() {
+	self.health.Register("database", func(context.Context) error { return nil })
+	response, request := self.getRequestAndResponse(http.MethodGet, "/readyz", nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), `"database":{"status":"ok"`)
+}
+
+func (self *HandlerTests) TestServeHTTPReadyzIfDraining() {
+	response, request := self.getRequestAndResponse(http.MethodGet, "/readyz", nil)
+
+	self.health.Drain()
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusServiceUnavailable, response.Code)
+	self.JSONEq(`{"status":"draining"}`, response.Body.String())
+}
+
 func (self *HandlerTests) TestServerHTTPNotFound() {
 	response, request := self.getRequestAndResponse(http.MethodGet, "/", nil)
 
 	self.handler.ServeHTTP(response, request)
 
 	self.Equal(http.StatusNotFound, response.Code)
-	self.Contains(response.Body.String(), "404 page not found")
+	self.Contains(response.Body.String(), ErrEndpointNotFound)
 }
 
 func (self *HandlerTests) TestCreateAuthorErrorIfJsonDecodeFailed() {
@@ -161,8 +412,8 @@ func (self *HandlerTests) TestCreateAuthorErrorIfJsonDecodeFailed() {
 
 	self.handler.CreateAuthor(response, request)
 
-	self.Equal(http.StatusInternalServerError, response.Code)
-	self.Contains(response.Body.String(), ErrCreateAuthor)
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidInputBody)
 }
 
 func (self *HandlerTests) TestCreateAuthorErrorIfValidateFailed() {
@@ -181,7 +432,7 @@ func (self *HandlerTests) TestCreateAuthorErrorIfServiceFailed() {
 	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateAuthor, requestBody)
 	self.serviceMock.
 		On("CreateAuthor", request.Context(), requestBody.Name).
-		Return(self.testError)
+		Return(models.Author{}, self.testError)
 
 	self.handler.CreateAuthor(response, request)
 
@@ -189,46 +440,53 @@ func (self *HandlerTests) TestCreateAuthorErrorIfServiceFailed() {
 	self.Contains(response.Body.String(), ErrCreateAuthor)
 }
 
-func (self *HandlerTests) TestCreateAuthor() {
+func (self *HandlerTests) TestCreateAuthorErrorIfConflict() {
 	requestBody := CreateAuthorRequestBody{
 		Name: "test_name",
 	}
 	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateAuthor, requestBody)
 	self.serviceMock.
 		On("CreateAuthor", request.Context(), requestBody.Name).
-		Return(nil)
+		Return(models.Author{}, fmt.Errorf("failed to create author: %w", models.ErrConflict))
 
 	self.handler.CreateAuthor(response, request)
 
-	self.Equal(http.StatusCreated, response.Code)
+	self.Equal(http.StatusConflict, response.Code)
+	self.Contains(response.Body.String(), ErrResourceConflict)
 }
 
-func (self *HandlerTests) TestGetAuthorsBooksErrorIfInvalidInput() {
-	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, "")
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+func (self *HandlerTests) TestCreateAuthor() {
+	requestBody := CreateAuthorRequestBody{
+		Name: "test_name",
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateAuthor, requestBody)
+	self.serviceMock.
+		On("CreateAuthor", request.Context(), requestBody.Name).
+		Return(self.author, nil)
 
-	self.handler.GetAuthorsBooks(response, request)
+	self.handler.CreateAuthor(response, request)
 
-	self.Equal(http.StatusUnprocessableEntity, response.Code)
-	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+	self.Equal(http.StatusCreated, response.Code)
+	self.Equal(fmt.Sprintf(EndpointAuthor, self.author.ID.String()), response.Header().Get("Location"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
 }
 
 func (self *HandlerTests) TestGetAuthorsBooksErrorIfParseUUIDFailed() {
 	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
 
 	self.handler.GetAuthorsBooks(response, request)
 
-	self.Equal(http.StatusInternalServerError, response.Code)
-	self.Contains(response.Body.String(), ErrGetAuthorsBooks)
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
 }
 
 func (self *HandlerTests) TestGetAuthorsBooksErrorIfGetAuthorsBooksFailed() {
 	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String())
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
 	self.serviceMock.
-		On("GetAuthorsBooks", request.Context(), self.author.ID).
-		Return(nil, self.testError)
+		On("GetAuthorsBooks", request.Context(), self.author.ID, models.ListOptions{}).
+		Return(models.Page[models.Book]{}, self.testError)
 
 	self.handler.GetAuthorsBooks(response, request)
 
@@ -238,24 +496,24 @@ func (self *HandlerTests) TestGetAuthorsBooksErrorIfGetAuthorsBooksFailed() {
 
 func (self *HandlerTests) TestGetAuthorsBooksIfGetAuthorsBooksReturnsEmptyBooks() {
 	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String())
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
 	self.serviceMock.
-		On("GetAuthorsBooks", request.Context(), self.author.ID).
-		Return([]models.Book{}, nil)
+		On("GetAuthorsBooks", request.Context(), self.author.ID, models.ListOptions{}).
+		Return(models.Page[models.Book]{Items: []models.Book{}}, nil)
 
 	self.handler.GetAuthorsBooks(response, request)
 
 	self.Equal(http.StatusOK, response.Code)
-	self.Contains(response.Body.String(), "[]")
+	self.JSONEq(`{"items":[]}`, response.Body.String())
 }
 
 func (self *HandlerTests) TestGetAuthorsBooks() {
 	books := []models.Book{self.book}
 	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String())
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
 	self.serviceMock.
-		On("GetAuthorsBooks", request.Context(), self.author.ID).
-		Return(books, nil)
+		On("GetAuthorsBooks", request.Context(), self.author.ID, models.ListOptions{}).
+		Return(models.Page[models.Book]{Items: books}, nil)
 
 	self.handler.GetAuthorsBooks(response, request)
 
@@ -263,13 +521,60 @@ func (self *HandlerTests) TestGetAuthorsBooks() {
 	self.Contains(response.Body.String(), string(self.mustMarshal(books)))
 }
 
+func (self *HandlerTests) TestGetAuthorsBooksWithListOptions() {
+	books := []models.Book{self.book}
+	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String()) +
+		"?limit=1&cursor=test_cursor&sort=-title&title_prefix=test"
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	self.serviceMock.
+		On("GetAuthorsBooks", request.Context(), self.author.ID, models.ListOptions{
+			Limit:  1,
+			Cursor: "test_cursor",
+			Sort:   "-title",
+			Prefix: "test",
+		}).
+		Return(models.Page[models.Book]{Items: books, NextCursor: "next_cursor"}, nil)
+
+	self.handler.GetAuthorsBooks(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(
+		fmt.Sprintf(`<%s?cursor=next_cursor&limit=1&sort=-title&title_prefix=test>; rel="next"`,
+			fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String())),
+		response.Header().Get("Link"),
+	)
+	self.Equal(string(self.mustMarshal(ListResponseBody[models.Book]{Items: books, NextCursor: "next_cursor"})), response.Body.String())
+}
+
+func (self *HandlerTests) TestGetAuthorsBooksErrorIfInvalidLimit() {
+	requestEndpoint := fmt.Sprintf(EndpointGetAuthorsBooks, self.author.ID.String()) + "?limit=1000"
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+
+	self.handler.GetAuthorsBooks(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidQueryParameters)
+}
+
+func (self *HandlerTests) TestGetAuthorsWithNamePrefix() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointGetAuthors+"?name_prefix=test", nil)
+	self.serviceMock.
+		On("GetAuthors", request.Context(), models.ListOptions{Prefix: "test"}).
+		Return(models.Page[models.Author]{}, nil)
+
+	self.handler.GetAuthors(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Empty(response.Header().Get("Link"))
+}
+
 func (self *HandlerTests) TestCreateBookErrorIfJsonDecodeFailed() {
 	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, "")
 
 	self.handler.CreateBook(response, request)
 
-	self.Equal(http.StatusInternalServerError, response.Code)
-	self.Contains(response.Body.String(), ErrCreateBook)
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidInputBody)
 }
 
 func (self *HandlerTests) TestCreateBookErrorIfValidateFailed() {
@@ -281,15 +586,58 @@ func (self *HandlerTests) TestCreateBookErrorIfValidateFailed() {
 	self.Contains(response.Body.String(), ErrInvalidInputBody)
 }
 
+func (self *HandlerTests) TestCreateBookErrorIfValidateFailedReportsFields() {
+	requestBody := CreateBookRequestBody{
+		Contributors: []ContributorRequestBody{{AuthorID: "not_uuid", Role: "reader"}},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
+
+	self.handler.CreateBook(response, request)
+
+	var problem Problem
+	self.NoError(json.Unmarshal(response.Body.Bytes(), &problem))
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Equal(problemContentType, response.Header().Get("Content-Type"))
+	self.Equal(Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(http.StatusUnprocessableEntity),
+		Status:   http.StatusUnprocessableEntity,
+		Detail:   ErrInvalidInputBody,
+		Instance: EndpointCreateBook,
+		Errors: []ProblemField{
+			{Field: "title", Message: "must not be empty"},
+			{Field: "contributors[0].author_id", Message: "must be a valid UUID"},
+			{Field: "contributors[0].role", Message: "must be one of: author editor translator illustrator"},
+		},
+	}, problem)
+}
+
+func (self *HandlerTests) TestCreateBookErrorIfNoContributors() {
+	requestBody := CreateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: []ContributorRequestBody{},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
+
+	self.handler.CreateBook(response, request)
+
+	var problem Problem
+	self.NoError(json.Unmarshal(response.Body.Bytes(), &problem))
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Equal([]ProblemField{
+		{Field: "contributors", Message: "must contain at least 1 items"},
+	}, problem.Errors)
+}
+
 func (self *HandlerTests) TestCreateBookErrorIfServiceFailed() {
 	requestBody := CreateBookRequestBody{
-		Title:    self.book.Title,
-		AuthorID: self.book.Author.ID.String(),
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
 	}
 	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
 	self.serviceMock.
-		On("CreateBook", request.Context(), requestBody.Title, uuid.MustParse(requestBody.AuthorID)).
-		Return(self.testError)
+		On("CreateBook", request.Context(), requestBody.Title, self.contributors(), models.BookDetails{}).
+		Return(models.Book{}, self.testError)
 
 	self.handler.CreateBook(response, request)
 
@@ -299,42 +647,109 @@ func (self *HandlerTests) TestCreateBookErrorIfServiceFailed() {
 
 func (self *HandlerTests) TestCreateBook() {
 	requestBody := CreateBookRequestBody{
-		Title:    self.book.Title,
-		AuthorID: self.book.Author.ID.String(),
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
 	}
 	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
 	self.serviceMock.
-		On("CreateBook", request.Context(), requestBody.Title, uuid.MustParse(requestBody.AuthorID)).
-		Return(nil)
+		On("CreateBook", request.Context(), requestBody.Title, self.contributors(), models.BookDetails{}).
+		Return(self.book, nil)
+
+	self.handler.CreateBook(response, request)
+
+	self.Equal(http.StatusCreated, response.Code)
+	self.Equal(fmt.Sprintf(EndpointGetBook, self.book.ID.String()), response.Header().Get("Location"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
+}
+
+func (self *HandlerTests) TestCreateBookWithDetails() {
+	publicationDate := time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC)
+	requestBody := CreateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
+		BookDetailsRequestBody: BookDetailsRequestBody{
+			ISBN:            "0-306-40615-2",
+			PublicationDate: "1979-01-01",
+			Description:     "test_description",
+			Language:        "en",
+			PageCount:       256,
+		},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
+	self.serviceMock.
+		On("CreateBook", request.Context(), requestBody.Title, self.contributors(), models.BookDetails{
+			ISBN:            "0-306-40615-2",
+			PublicationDate: &publicationDate,
+			Description:     "test_description",
+			Language:        "en",
+			PageCount:       256,
+		}).
+		Return(self.book, nil)
 
 	self.handler.CreateBook(response, request)
 
 	self.Equal(http.StatusCreated, response.Code)
 }
 
-func (self *HandlerTests) TestGetBookErrorIfInvalidInput() {
-	requestEndpoint := fmt.Sprintf(EndpointGetBook, "")
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+func (self *HandlerTests) TestCreateBookErrorIfInvalidPublicationDate() {
+	requestBody := CreateBookRequestBody{
+		Title:                  self.book.Title,
+		Contributors:           self.contributorsRequestBody(),
+		BookDetailsRequestBody: BookDetailsRequestBody{PublicationDate: "01.01.1979", PageCount: -1},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
 
-	self.handler.GetBook(response, request)
+	self.handler.CreateBook(response, request)
 
+	var problem Problem
+	self.NoError(json.Unmarshal(response.Body.Bytes(), &problem))
 	self.Equal(http.StatusUnprocessableEntity, response.Code)
-	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+	self.Equal(Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(http.StatusUnprocessableEntity),
+		Status:   http.StatusUnprocessableEntity,
+		Detail:   ErrInvalidInputBody,
+		Instance: EndpointCreateBook,
+		Errors: []ProblemField{
+			{Field: "publication_date", Message: "must be a date in the 2006-01-02 format"},
+			{Field: "page_count", Message: "must be greater than or equal to 0"},
+		},
+	}, problem)
+}
+
+func (self *HandlerTests) TestCreateBookErrorIfInvalidISBN() {
+	requestBody := CreateBookRequestBody{
+		Title:                  self.book.Title,
+		Contributors:           self.contributorsRequestBody(),
+		BookDetailsRequestBody: BookDetailsRequestBody{ISBN: "123"},
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
+	self.serviceMock.
+		On("CreateBook", request.Context(), requestBody.Title, self.contributors(), models.BookDetails{ISBN: "123"}).
+		Return(models.Book{}, fmt.Errorf("failed to init book: %w", models.ValidationError{
+			Field:   "isbn",
+			Message: "book ISBN must be a valid ISBN-10 or ISBN-13",
+		}))
+
+	self.handler.CreateBook(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Contains(response.Body.String(), `"field":"isbn"`)
 }
 
 func (self *HandlerTests) TestGetBookErrorIfParseUUIDFailed() {
 	requestEndpoint := fmt.Sprintf(EndpointGetBook, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
 
 	self.handler.GetBook(response, request)
 
-	self.Equal(http.StatusInternalServerError, response.Code)
-	self.Contains(response.Body.String(), ErrGetBook)
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
 }
 
 func (self *HandlerTests) TestGetBookErrorIfServiceFailed() {
 	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
 	self.serviceMock.
 		On("GetBook", request.Context(), self.book.ID).
 		Return(models.Book{}, self.testError)
@@ -345,9 +760,22 @@ func (self *HandlerTests) TestGetBookErrorIfServiceFailed() {
 	self.Contains(response.Body.String(), ErrGetBook)
 }
 
+func (self *HandlerTests) TestGetBookErrorIfNotFound() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	self.serviceMock.
+		On("GetBook", request.Context(), self.book.ID).
+		Return(models.Book{}, fmt.Errorf("failed to get book by id: %w", models.ErrNotFound))
+
+	self.handler.GetBook(response, request)
+
+	self.Equal(http.StatusNotFound, response.Code)
+	self.Contains(response.Body.String(), ErrResourceNotFound)
+}
+
 func (self *HandlerTests) TestGetBook() {
 	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
-	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil)
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
 	self.serviceMock.
 		On("GetBook", request.Context(), self.book.ID).
 		Return(self.book, nil)
@@ -358,24 +786,853 @@ func (self *HandlerTests) TestGetBook() {
 	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
 }
 
-func (self *HandlerTests) getRequestAndResponse(httpMethod string, endpoint string, body any) (*httptest.ResponseRecorder, *http.Request) {
-	requestBodyReader := bytes.NewReader(self.mustMarshal(body))
-	request := httptest.NewRequest(httpMethod, endpoint, requestBodyReader)
-	response := httptest.NewRecorder()
-	return response, request
+func (self *HandlerTests) TestServeHTTPGetBookByISBN() {
+	response, request := self.getRequestAndResponse(http.MethodGet, fmt.Sprintf(EndpointGetBookByISBN, "978-0-306-40615-7"), nil)
+	self.serviceMock.
+		On("GetBookByISBN", self.routedRequest(request, router.Param{Name: "isbn", Value: "978-0-306-40615-7"}).Context(), "978-0-306-40615-7").
+		Return(self.book, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
 }
 
-func (self *HandlerTests) getRequestAndResponseWithLogger(httpMethod string, endpoint string, body any) (*httptest.ResponseRecorder, *http.Request) {
-	response, request := self.getRequestAndResponse(httpMethod, endpoint, body)
-	request = self.requestWithLogger(request)
-	return response, request
+func (self *HandlerTests) TestGetBookByISBNErrorIfNotFound() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBookByISBN, "9780306406157")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "isbn", Value: "9780306406157"})
+	self.serviceMock.
+		On("GetBookByISBN", request.Context(), "9780306406157").
+		Return(models.Book{}, fmt.Errorf("failed to get book by isbn: %w", models.ErrNotFound))
+
+	self.handler.GetBookByISBN(response, request)
+
+	self.Equal(http.StatusNotFound, response.Code)
+	self.Contains(response.Body.String(), ErrResourceNotFound)
+}
+
+func (self *HandlerTests) TestGetBookByISBN() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBookByISBN, "9780306406157")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "isbn", Value: "9780306406157"})
+	self.serviceMock.
+		On("GetBookByISBN", request.Context(), "9780306406157").
+		Return(self.book, nil)
+
+	self.handler.GetBookByISBN(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
+}
+
+func (self *HandlerTests) TestGetAuthorsErrorIfServiceFailed() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointGetAuthors, nil)
+	self.serviceMock.
+		On("GetAuthors", request.Context(), models.ListOptions{}).
+		Return(models.Page[models.Author]{}, self.testError)
+
+	self.handler.GetAuthors(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrGetAuthors)
+}
+
+func (self *HandlerTests) TestGetAuthorsIfServiceReturnsEmptyAuthors() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointGetAuthors, nil)
+	self.serviceMock.
+		On("GetAuthors", request.Context(), models.ListOptions{}).
+		Return(models.Page[models.Author]{}, nil)
+
+	self.handler.GetAuthors(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.JSONEq(`{"items":[]}`, response.Body.String())
+}
+
+func (self *HandlerTests) TestGetAuthors() {
+	authors := []models.Author{self.author}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointGetAuthors, nil)
+	self.serviceMock.
+		On("GetAuthors", request.Context(), models.ListOptions{}).
+		Return(models.Page[models.Author]{Items: authors}, nil)
+
+	self.handler.GetAuthors(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(authors)))
+}
+
+func (self *HandlerTests) TestGetAuthorErrorIfParseUUIDFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
+
+	self.handler.GetAuthor(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+}
+
+func (self *HandlerTests) TestGetAuthorErrorIfServiceFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	self.serviceMock.
+		On("GetAuthor", request.Context(), self.author.ID).
+		Return(models.Author{}, self.testError)
+
+	self.handler.GetAuthor(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrGetAuthor)
+}
+
+func (self *HandlerTests) TestGetAuthor() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	self.serviceMock.
+		On("GetAuthor", request.Context(), self.author.ID).
+		Return(self.author, nil)
+
+	self.handler.GetAuthor(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
+}
+
+func (self *HandlerTests) TestUpdateAuthorErrorIfParseUUIDFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
+
+	self.handler.UpdateAuthor(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+}
+
+func (self *HandlerTests) TestUpdateAuthorErrorIfJsonDecodeFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, "", router.Param{Name: "id", Value: self.author.ID.String()})
+	request.Header.Set("If-Match", testETag)
+
+	self.handler.UpdateAuthor(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidInputBody)
+}
+
+func (self *HandlerTests) TestUpdateAuthorErrorIfValidateFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	request.Header.Set("If-Match", testETag)
+
+	self.handler.UpdateAuthor(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidInputBody)
+}
+
+func (self *HandlerTests) TestUpdateAuthorErrorIfServiceFailed() {
+	requestBody := UpdateAuthorRequestBody{
+		Name: self.author.Name,
+	}
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.author.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("UpdateAuthor", request.Context(), self.author.ID, requestBody.Name, models.FirstVersion).
+		Return(models.Author{}, self.testError)
+
+	self.handler.UpdateAuthor(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrUpdateAuthor)
+}
+
+func (self *HandlerTests) TestUpdateAuthor() {
+	requestBody := UpdateAuthorRequestBody{
+		Name: self.author.Name,
+	}
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.author.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("UpdateAuthor", request.Context(), self.author.ID, requestBody.Name, models.FirstVersion).
+		Return(self.author, nil)
+
+	self.handler.UpdateAuthor(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
+}
+
+func (self *HandlerTests) TestDeleteAuthorErrorIfParseUUIDFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
+
+	self.handler.DeleteAuthor(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+}
+
+func (self *HandlerTests) TestDeleteAuthorErrorIfServiceFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("DeleteAuthor", request.Context(), self.author.ID, models.FirstVersion).
+		Return(int64(0), self.testError)
+
+	self.handler.DeleteAuthor(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrDeleteAuthor)
+}
+
+func (self *HandlerTests) TestDeleteAuthor() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("DeleteAuthor", request.Context(), self.author.ID, models.FirstVersion).
+		Return(int64(2), nil)
+
+	self.handler.DeleteAuthor(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(DeleteAuthorResponseBody{OrphanedBooks: 2})))
+}
+
+func (self *HandlerTests) TestGetBooksErrorIfServiceFailed() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointGetBooks, nil)
+	self.serviceMock.
+		On("GetBooks", request.Context(), models.ListOptions{}).
+		Return(models.Page[models.Book]{}, self.testError)
+
+	self.handler.GetBooks(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrGetBooks)
+}
+
+func (self *HandlerTests) TestGetBooksIfServiceReturnsEmptyBooks() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointGetBooks, nil)
+	self.serviceMock.
+		On("GetBooks", request.Context(), models.ListOptions{}).
+		Return(models.Page[models.Book]{}, nil)
+
+	self.handler.GetBooks(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.JSONEq(`{"items":[]}`, response.Body.String())
+}
+
+func (self *HandlerTests) TestGetBooks() {
+	books := []models.Book{self.book}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointGetBooks, nil)
+	self.serviceMock.
+		On("GetBooks", request.Context(), models.ListOptions{}).
+		Return(models.Page[models.Book]{Items: books}, nil)
+
+	self.handler.GetBooks(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(books)))
+}
+
+func (self *HandlerTests) TestUpdateBookErrorIfParseUUIDFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+}
+
+func (self *HandlerTests) TestUpdateBookErrorIfJsonDecodeFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, "", router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidInputBody)
+}
+
+func (self *HandlerTests) TestUpdateBookErrorIfValidateFailed() {
+	requestBody := UpdateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: []ContributorRequestBody{{AuthorID: "not_uuid"}},
+	}
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidInputBody)
+}
+
+func (self *HandlerTests) TestUpdateBookErrorIfServiceFailed() {
+	requestBody := UpdateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
+	}
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("UpdateBook", request.Context(), self.book.ID, requestBody.Title, self.contributors(), models.BookDetails{}, models.FirstVersion).
+		Return(models.Book{}, self.testError)
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrUpdateBook)
+}
+
+func (self *HandlerTests) TestUpdateBook() {
+	requestBody := UpdateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
+	}
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("UpdateBook", request.Context(), self.book.ID, requestBody.Title, self.contributors(), models.BookDetails{}, models.FirstVersion).
+		Return(self.book, nil)
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
+}
+
+func (self *HandlerTests) TestDeleteBookErrorIfParseUUIDFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
+
+	self.handler.DeleteBook(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+}
+
+func (self *HandlerTests) TestDeleteBookErrorIfServiceFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("DeleteBook", request.Context(), self.book.ID, models.FirstVersion).
+		Return(self.testError)
+
+	self.handler.DeleteBook(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrDeleteBook)
+}
+
+func (self *HandlerTests) TestDeleteBook() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("DeleteBook", request.Context(), self.book.ID, models.FirstVersion).
+		Return(nil)
+
+	self.handler.DeleteBook(response, request)
+
+	self.Equal(http.StatusNoContent, response.Code)
+}
+
+func (self *HandlerTests) TestGetBookSetsValidators() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	self.serviceMock.
+		On("GetBook", request.Context(), self.book.ID).
+		Return(self.book, nil)
+
+	self.handler.GetBook(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(testETag, response.Header().Get("ETag"))
+	self.Equal("Sun, 02 Oct 2022 03:04:05 GMT", response.Header().Get("Last-Modified"))
+	self.NotContains(response.Body.String(), "Version")
+}
+
+func (self *HandlerTests) TestGetBookNotModifiedIfNoneMatch() {
+	for _, ifNoneMatch := range []string{testETag, `W/"1"`, `"2", "1"`, "*"} {
+		requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+		response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+		request.Header.Set("If-None-Match", ifNoneMatch)
+		self.serviceMock.
+			On("GetBook", request.Context(), self.book.ID).
+			Return(self.book, nil).Once()
+
+		self.handler.GetBook(response, request)
+
+		self.Equal(http.StatusNotModified, response.Code, ifNoneMatch)
+		self.Equal(testETag, response.Header().Get("ETag"))
+		self.Empty(response.Body.String())
+	}
+}
+
+func (self *HandlerTests) TestGetBookIfNoneMatchDiffers() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-None-Match", `"2"`)
+	// If-Modified-Since is ignored with If-None-Match.
+	request.Header.Set("If-Modified-Since", "Mon, 03 Oct 2022 00:00:00 GMT")
+	self.serviceMock.
+		On("GetBook", request.Context(), self.book.ID).
+		Return(self.book, nil)
+
+	self.handler.GetBook(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
+}
+
+func (self *HandlerTests) TestGetBookNotModifiedSince() {
+	testCases := []struct {
+		ifModifiedSince string
+		expectedStatus  int
+	}{
+		{"Sun, 02 Oct 2022 03:04:05 GMT", http.StatusNotModified},
+		{"Sun, 02 Oct 2022 03:04:04 GMT", http.StatusOK},
+		{"not_a_date", http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+		response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+		request.Header.Set("If-Modified-Since", testCase.ifModifiedSince)
+		self.serviceMock.
+			On("GetBook", request.Context(), self.book.ID).
+			Return(self.book, nil).Once()
+
+		self.handler.GetBook(response, request)
+
+		self.Equal(testCase.expectedStatus, response.Code, testCase.ifModifiedSince)
+	}
+}
+
+func (self *HandlerTests) TestServeHTTPGetBookByISBNNotModified() {
+	response, request := self.getRequestAndResponse(http.MethodGet, fmt.Sprintf(EndpointGetBookByISBN, "9780306406157"), nil)
+	request.Header.Set("If-None-Match", testETag)
+	self.serviceMock.
+		On("GetBookByISBN", self.routedRequest(request, router.Param{Name: "isbn", Value: "9780306406157"}).Context(), "9780306406157").
+		Return(self.book, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusNotModified, response.Code)
+	self.Empty(response.Body.String())
+}
+
+func (self *HandlerTests) TestGetAuthorNotModified() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	request.Header.Set("If-None-Match", testETag)
+	self.serviceMock.
+		On("GetAuthor", request.Context(), self.author.ID).
+		Return(self.author, nil)
+
+	self.handler.GetAuthor(response, request)
+
+	self.Equal(http.StatusNotModified, response.Code)
+	self.Equal(testETag, response.Header().Get("ETag"))
+	self.Empty(response.Header().Get("Last-Modified"))
+}
+
+func (self *HandlerTests) TestCreateBookSetsETag() {
+	requestBody := CreateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
+	}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, EndpointCreateBook, requestBody)
+	self.serviceMock.
+		On("CreateBook", request.Context(), requestBody.Title, self.contributors(), models.BookDetails{}).
+		Return(self.book, nil)
+
+	self.handler.CreateBook(response, request)
+
+	self.Equal(http.StatusCreated, response.Code)
+	self.Equal(testETag, response.Header().Get("ETag"))
+}
+
+func (self *HandlerTests) TestUpdateBookErrorIfPreconditionRequired() {
+	requestBody := UpdateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
+	}
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.book.ID.String()})
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusPreconditionRequired, response.Code)
+	self.Contains(response.Body.String(), ErrPreconditionRequired)
+}
+
+func (self *HandlerTests) TestUpdateBookErrorIfIfMatchNeverMatches() {
+	for _, ifMatch := range []string{`W/"1"`, `"1", "2"`, `"0"`, `"a"`, "1"} {
+		requestBody := UpdateBookRequestBody{
+			Title:        self.book.Title,
+			Contributors: self.contributorsRequestBody(),
+		}
+		requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+		response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.book.ID.String()})
+		request.Header.Set("If-Match", ifMatch)
+
+		self.handler.UpdateBook(response, request)
+
+		self.Equal(http.StatusPreconditionFailed, response.Code, ifMatch)
+		self.Contains(response.Body.String(), ErrPreconditionFailed)
+	}
+}
+
+func (self *HandlerTests) TestUpdateBookErrorIfVersionMismatch() {
+	requestBody := UpdateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
+	}
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("UpdateBook", request.Context(), self.book.ID, requestBody.Title, self.contributors(), models.BookDetails{}, models.FirstVersion).
+		Return(models.Book{}, fmt.Errorf("failed to update book: %w", models.VersionMismatchError{Entity: "book"}))
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusPreconditionFailed, response.Code)
+	self.Contains(response.Body.String(), ErrPreconditionFailed)
+}
+
+func (self *HandlerTests) TestUpdateBookOfAnyVersion() {
+	requestBody := UpdateBookRequestBody{
+		Title:        self.book.Title,
+		Contributors: self.contributorsRequestBody(),
+	}
+	updated := self.book
+	updated.Version = 2
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPut, requestEndpoint, requestBody, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", "*")
+	self.serviceMock.
+		On("UpdateBook", request.Context(), self.book.ID, requestBody.Title, self.contributors(), models.BookDetails{}, models.AnyVersion).
+		Return(updated, nil)
+
+	self.handler.UpdateBook(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(`"2"`, response.Header().Get("ETag"))
+}
+
+func (self *HandlerTests) TestDeleteAuthorErrorIfPreconditionRequired() {
+	requestEndpoint := fmt.Sprintf(EndpointAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+
+	self.handler.DeleteAuthor(response, request)
+
+	self.Equal(http.StatusPreconditionRequired, response.Code)
+}
+
+func (self *HandlerTests) TestDeleteBookErrorIfVersionMismatch() {
+	requestEndpoint := fmt.Sprintf(EndpointGetBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodDelete, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	request.Header.Set("If-Match", testETag)
+	self.serviceMock.
+		On("DeleteBook", request.Context(), self.book.ID, models.FirstVersion).
+		Return(fmt.Errorf("failed to delete book: %w", models.VersionMismatchError{Entity: "book"}))
+
+	self.handler.DeleteBook(response, request)
+
+	self.Equal(http.StatusPreconditionFailed, response.Code)
+}
+
+func (self *HandlerTests) TestSearchErrorIfInvalidLimit() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointSearch+"?q=test&limit=0", nil)
+
+	self.handler.Search(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidQueryParameters)
+}
+
+func (self *HandlerTests) TestSearchErrorIfServiceFailed() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointSearch, nil)
+	self.serviceMock.
+		On("Search", request.Context(), "", "", models.ListOptions{}).
+		Return(models.Page[models.SearchResult]{}, models.ValidationError{Field: "q", Message: "test_message"})
+
+	self.handler.Search(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Contains(response.Body.String(), "test_message")
+}
+
+func (self *HandlerTests) TestSearch() {
+	results := []models.SearchResult{{Type: models.SearchResultTypeAuthor, ID: self.author.ID, Title: self.author.Name}}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointSearch+"?q=test&type=author&limit=1", nil)
+	self.serviceMock.
+		On("Search", request.Context(), "test", models.SearchResultTypeAuthor, models.ListOptions{Limit: 1}).
+		Return(models.Page[models.SearchResult]{Items: results, NextCursor: "next_cursor"}, nil)
+
+	self.handler.Search(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(`</api/search?cursor=next_cursor&limit=1&q=test&type=author>; rel="next"`, response.Header().Get("Link"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(results)))
+}
+
+func (self *HandlerTests) TestServeHTTPRestoreBook() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreBook, self.book.ID.String())
+	response, request := self.getRequestAndResponse(http.MethodPost, requestEndpoint, nil)
+	self.serviceMock.
+		On("RestoreBook", self.routedRequest(request, router.Param{Name: "id", Value: self.book.ID.String()}).Context(), self.book.ID).
+		Return(self.book, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.book)))
+}
+
+func (self *HandlerTests) TestServeHTTPRestoreAuthor() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponse(http.MethodPost, requestEndpoint, nil)
+	self.serviceMock.
+		On("RestoreAuthor", self.routedRequest(request, router.Param{Name: "id", Value: self.author.ID.String()}).Context(), self.author.ID).
+		Return(self.author, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
+}
+
+func (self *HandlerTests) TestServeHTTPGetTrash() {
+	items := []models.TrashItem{{Type: models.TrashItemTypeBook, ID: self.book.ID, Title: self.book.Title}}
+	response, request := self.getRequestAndResponse(http.MethodGet, EndpointTrash, nil)
+	self.serviceMock.
+		On("GetTrash", self.routedRequest(request).Context(), "", models.ListOptions{}).
+		Return(models.Page[models.TrashItem]{Items: items}, nil)
+
+	self.handler.ServeHTTP(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Contains(response.Body.String(), string(self.mustMarshal(items)))
+}
+
+func (self *HandlerTests) TestRestoreBookErrorIfParseUUIDFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreBook, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
+
+	self.handler.RestoreBook(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+}
+
+func (self *HandlerTests) TestRestoreBookErrorIfNotInTrash() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	self.serviceMock.
+		On("RestoreBook", request.Context(), self.book.ID).
+		Return(models.Book{}, fmt.Errorf("failed to restore book: %w", models.ErrNotFound))
+
+	self.handler.RestoreBook(response, request)
+
+	self.Equal(http.StatusNotFound, response.Code)
+	self.Contains(response.Body.String(), ErrResourceNotFound)
+}
+
+func (self *HandlerTests) TestRestoreBookErrorIfServiceFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	self.serviceMock.
+		On("RestoreBook", request.Context(), self.book.ID).
+		Return(models.Book{}, self.testError)
+
+	self.handler.RestoreBook(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrRestoreBook)
+}
+
+func (self *HandlerTests) TestRestoreBook() {
+	restored := self.book
+	restored.Version = 3
+	requestEndpoint := fmt.Sprintf(EndpointRestoreBook, self.book.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: self.book.ID.String()})
+	self.serviceMock.
+		On("RestoreBook", request.Context(), self.book.ID).
+		Return(restored, nil)
+
+	self.handler.RestoreBook(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(`"3"`, response.Header().Get("ETag"))
+	self.Equal("Sun, 02 Oct 2022 03:04:05 GMT", response.Header().Get("Last-Modified"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(restored)))
+}
+
+func (self *HandlerTests) TestRestoreAuthorErrorIfParseUUIDFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreAuthor, "b9bac125+94e7+4c4b+8df2+6cd055402bcc")
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: "b9bac125+94e7+4c4b+8df2+6cd055402bcc"})
+
+	self.handler.RestoreAuthor(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidPathVariables)
+}
+
+func (self *HandlerTests) TestRestoreAuthorErrorIfServiceFailed() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	self.serviceMock.
+		On("RestoreAuthor", request.Context(), self.author.ID).
+		Return(models.Author{}, self.testError)
+
+	self.handler.RestoreAuthor(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrRestoreAuthor)
+}
+
+func (self *HandlerTests) TestRestoreAuthor() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	self.serviceMock.
+		On("RestoreAuthor", request.Context(), self.author.ID).
+		Return(self.author, nil)
+
+	self.handler.RestoreAuthor(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(testETag, response.Header().Get("ETag"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(self.author)))
+}
+
+func (self *HandlerTests) TestGetTrashErrorIfInvalidLimit() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointTrash+"?limit=1000", nil)
+
+	self.handler.GetTrash(response, request)
+
+	self.Equal(http.StatusBadRequest, response.Code)
+	self.Contains(response.Body.String(), ErrInvalidQueryParameters)
+}
+
+func (self *HandlerTests) TestGetTrashErrorIfInvalidType() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointTrash+"?type=test_type", nil)
+	self.serviceMock.
+		On("GetTrash", request.Context(), "test_type", models.ListOptions{}).
+		Return(models.Page[models.TrashItem]{}, models.ValidationError{Field: "type", Message: "test_message"})
+
+	self.handler.GetTrash(response, request)
+
+	self.Equal(http.StatusUnprocessableEntity, response.Code)
+	self.Contains(response.Body.String(), "test_message")
+}
+
+func (self *HandlerTests) TestGetTrashErrorIfServiceFailed() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointTrash, nil)
+	self.serviceMock.
+		On("GetTrash", request.Context(), "", models.ListOptions{}).
+		Return(models.Page[models.TrashItem]{}, self.testError)
+
+	self.handler.GetTrash(response, request)
+
+	self.Equal(http.StatusInternalServerError, response.Code)
+	self.Contains(response.Body.String(), ErrGetTrash)
+}
+
+func (self *HandlerTests) TestGetTrash() {
+	items := []models.TrashItem{{Type: models.TrashItemTypeAuthor, ID: self.author.ID, Title: self.author.Name}}
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointTrash+"?type=author&limit=1", nil)
+	self.serviceMock.
+		On("GetTrash", request.Context(), models.TrashItemTypeAuthor, models.ListOptions{Limit: 1}).
+		Return(models.Page[models.TrashItem]{Items: items, NextCursor: "next_cursor"}, nil)
+
+	self.handler.GetTrash(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(`</api/trash?cursor=next_cursor&limit=1&type=author>; rel="next"`, response.Header().Get("Link"))
+	self.Contains(response.Body.String(), string(self.mustMarshal(items)))
+}
+
+func (self *HandlerTests) TestGetTrashIgnoresTitlePrefix() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointTrash+"?title_prefix=test", nil)
+	self.serviceMock.
+		On("GetTrash", request.Context(), "", models.ListOptions{}).
+		Return(models.Page[models.TrashItem]{}, nil)
+
+	self.handler.GetTrash(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+}
+
+func (self *HandlerTests) TestSearchIgnoresTitlePrefix() {
+	response, request := self.getRequestAndResponseWithLogger(http.MethodGet, EndpointSearch+"?q=test&title_prefix=test", nil)
+	self.serviceMock.
+		On("Search", request.Context(), "test", "", models.ListOptions{}).
+		Return(models.Page[models.SearchResult]{}, nil)
+
+	self.handler.Search(response, request)
+
+	self.Equal(http.StatusOK, response.Code)
+}
+
+func (self *HandlerTests) TestRestoreAuthorErrorIfNameReused() {
+	requestEndpoint := fmt.Sprintf(EndpointRestoreAuthor, self.author.ID.String())
+	response, request := self.getRequestAndResponseWithLogger(http.MethodPost, requestEndpoint, nil, router.Param{Name: "id", Value: self.author.ID.String()})
+	self.serviceMock.
+		On("RestoreAuthor", request.Context(), self.author.ID).
+		Return(models.Author{}, fmt.Errorf("failed to restore author: %w", models.ErrConflict))
+
+	self.handler.RestoreAuthor(response, request)
+
+	self.Equal(http.StatusConflict, response.Code)
+	self.Contains(response.Body.String(), ErrResourceConflict)
+}
+
+func (self *HandlerTests) getRequestAndResponse(httpMethod string, endpoint string, body any) (*httptest.ResponseRecorder, *http.Request) {
+	requestBodyReader := bytes.NewReader(self.mustMarshal(body))
+	request := httptest.NewRequest(httpMethod, endpoint, requestBodyReader)
+	request.Header.Set(RequestIDHeader, testRequestID)
+	response := httptest.NewRecorder()
+	return response, request
+}
+
+// getRequestAndResponseWithLogger returns the request passed to the handler by the router, params are the
+// path parameters matched by the route.
+func (self *HandlerTests) getRequestAndResponseWithLogger(
+	httpMethod string,
+	endpoint string,
+	body any,
+	params ...router.Param,
+) (*httptest.ResponseRecorder, *http.Request) {
+	response, request := self.getRequestAndResponse(httpMethod, endpoint, body)
+	request = self.routedRequest(request, params...)
+	return response, request
+}
+
+// routedRequest returns the request as it is passed by the router to the endpoint matching it.
+func (self *HandlerTests) routedRequest(request *http.Request, params ...router.Param) *http.Request {
+	return router.WithParams(self.requestWithLogger(request), params)
 }
 
+// requestWithLogger returns the request as it is passed by the middlewares of the handler.
 func (self *HandlerTests) requestWithLogger(request *http.Request) *http.Request {
+	request = request.WithContext(withRequestID(request.Context(), request.Header.Get(RequestIDHeader)))
 	return request.WithContext(
 		logcontext.WithLogger(
 			request.Context(),
-			logrus.NewEntry(self.logger),
+			requestLogger(self.logger, request),
 		),
 	)
 }
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/egormizerov/books/pkg/metrics"
+	"github.com/egormizerov/books/pkg/router"
+)
+
+// Label of the route of the requests not matching any route.
+const unmatchedRoute = "unmatched"
+
+// Label of the method of the requests with any method not in knownMethods.
+const otherMethod = "other"
+
+// knownMethods are labeled as they are, the clients may send any method, so the others share the label.
+var knownMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodOptions: {},
+}
+
+func methodLabel(method string) string {
+	if _, ok := knownMethods[method]; ok {
+		return method
+	}
+	return otherMethod
+}
+
+// httpMetrics observes the requests handled by the routes.
+type httpMetrics struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+func newHTTPMetrics(registry *metrics.Registry) *httpMetrics {
+	httpMetrics := &httpMetrics{
+		requests: metrics.NewCounterVec(
+			"books_http_requests_total",
+			"Total number of handled HTTP requests.",
+			"method", "route", "status",
+		),
+		duration: metrics.NewHistogramVec(
+			"books_http_request_duration_seconds",
+			"Duration of handling HTTP requests.",
+			metrics.DefaultBuckets,
+			"method", "route", "status",
+		),
+	}
+	registry.Register(httpMetrics.requests, httpMetrics.duration)
+
+	return httpMetrics
+}
+
+// middleware is the route middleware counting the requests of the route and observing their duration.
+func (self *httpMetrics) middleware(pattern string) router.Middleware {
+	if pattern == "" {
+		pattern = unmatchedRoute
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+			recorder := &responseRecorder{ResponseWriter: response}
+
+			next.ServeHTTP(recorder, request)
+
+			method, status := methodLabel(request.Method), strconv.Itoa(recorder.statusCode())
+			self.requests.Inc(method, pattern, status)
+			self.duration.Observe(time.Since(start).Seconds(), method, pattern, status)
+		})
+	}
+}
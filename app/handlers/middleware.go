@@ -1,14 +1,158 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
 	logcontext "github.com/egormizerov/books/pkg/log/context"
+	"github.com/egormizerov/books/pkg/router"
 )
 
-func MiddlewareLoggerInContext(request *http.Request, logger *logrus.Logger) *http.Request {
-	contextWithLogger := logcontext.WithLogger(request.Context(), logrus.NewEntry(logger))
-	return request.WithContext(contextWithLogger)
+// Header carrying the id of the request, it is taken from the request or generated and returned in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// Maximum length of the request id accepted from the client.
+const maxRequestIDLength = 128
+
+var ErrInternalServerError = "Internal server error."
+
+type requestIDContextKey struct{}
+
+// RequestIDFromContext returns the id of the request assigned by MiddlewareRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// MiddlewareRequestID propagates the request id sent by the client or assigns the new one.
+func MiddlewareRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		requestID := request.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		response.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(response, request.WithContext(withRequestID(request.Context(), requestID)))
+	})
+}
+
+// isValidRequestID reports whether the request id is safe to be logged and returned in the header.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for index := 0; index < len(requestID); index++ {
+		if requestID[index] < '!' || requestID[index] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// MiddlewareLogger adds the logger describing the request to the context of the request.
+func MiddlewareLogger(logger *logrus.Logger) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			contextWithLogger := logcontext.WithLogger(request.Context(), requestLogger(logger, request))
+			next.ServeHTTP(response, request.WithContext(contextWithLogger))
+		})
+	}
+}
+
+func requestLogger(logger *logrus.Logger, request *http.Request) *logrus.Entry {
+	return logrus.NewEntry(logger).WithFields(logrus.Fields{
+		"request_id":  RequestIDFromContext(request.Context()),
+		"method":      request.Method,
+		"path":        request.URL.Path,
+		"remote_addr": request.RemoteAddr,
+	})
+}
+
+// MiddlewareAccessLog writes the log line with the status and the latency of every request.
+func MiddlewareAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: response}
+
+		next.ServeHTTP(recorder, request)
+
+		logcontext.FromContext(request.Context()).
+			WithField("status", recorder.statusCode()).
+			WithField("bytes", recorder.bytes).
+			WithField("latency_ms", float64(time.Since(start).Microseconds())/1000).
+			Info("request completed")
+	})
+}
+
+// MiddlewareRecovery responds with the internal server error if the handler panics.
+func MiddlewareRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		recorder := &responseRecorder{ResponseWriter: response}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// ErrAbortHandler aborts the response on purpose, the server handles it.
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logcontext.FromContext(request.Context()).
+				WithField("panic", fmt.Sprint(recovered)).
+				WithField("stack", string(debug.Stack())).
+				Error("request handler panicked")
+			if recorder.status == 0 {
+				writeProblem(recorder, request, http.StatusInternalServerError, ErrInternalServerError)
+			}
+		}()
+
+		next.ServeHTTP(recorder, request)
+	})
+}
+
+// responseRecorder remembers the status code and the size of the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (self *responseRecorder) WriteHeader(statusCode int) {
+	if self.status == 0 {
+		self.status = statusCode
+	}
+	self.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (self *responseRecorder) Write(data []byte) (int, error) {
+	if self.status == 0 {
+		self.status = http.StatusOK
+	}
+	written, err := self.ResponseWriter.Write(data)
+	self.bytes += written
+	return written, err
+}
+
+// Unwrap returns the original writer for http.ResponseController.
+func (self *responseRecorder) Unwrap() http.ResponseWriter {
+	return self.ResponseWriter
+}
+
+func (self *responseRecorder) statusCode() int {
+	if self.status == 0 {
+		return http.StatusOK
+	}
+	return self.status
 }
@@ -2,20 +2,139 @@ package handlers
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	logcontext "github.com/egormizerov/books/pkg/log/context"
 )
 
-func TestMiddlewareLoggerInContext(t *testing.T) {
+func TestMiddlewareRequestIDPropagatesID(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+	request.Header.Set(RequestIDHeader, "test_request_id")
+	response := httptest.NewRecorder()
+	var requestID string
+
+	MiddlewareRequestID(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		requestID = RequestIDFromContext(request.Context())
+	})).ServeHTTP(response, request)
+
+	assert.Equal(t, "test_request_id", requestID)
+	assert.Equal(t, "test_request_id", response.Header().Get(RequestIDHeader))
+}
+
+func TestMiddlewareRequestIDGeneratesID(t *testing.T) {
+	for _, header := range []string{"", "invalid request id", strings.Repeat("a", maxRequestIDLength+1)} {
+		request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+		request.Header.Set(RequestIDHeader, header)
+		response := httptest.NewRecorder()
+		var requestID string
+
+		MiddlewareRequestID(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+			requestID = RequestIDFromContext(request.Context())
+		})).ServeHTTP(response, request)
+
+		_, err := uuid.Parse(requestID)
+		assert.NoError(t, err)
+		assert.Equal(t, requestID, response.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestMiddlewareLogger(t *testing.T) {
 	logger := logrus.New()
-	request := &http.Request{}
-	contextWithLogger := logcontext.WithLogger(request.Context(), logrus.NewEntry(logger))
+	request := httptest.NewRequest(http.MethodDelete, "/api/books/1", nil)
+	request = request.WithContext(withRequestID(request.Context(), "test_request_id"))
+	var entry *logrus.Entry
+
+	MiddlewareLogger(logger)(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		entry = logcontext.FromContext(request.Context())
+	})).ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, logger, entry.Logger)
+	assert.Equal(t, logrus.Fields{
+		"request_id":  "test_request_id",
+		"method":      http.MethodDelete,
+		"path":        "/api/books/1",
+		"remote_addr": request.RemoteAddr,
+	}, entry.Data)
+}
+
+func TestMiddlewareAccessLog(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+	request = request.WithContext(logcontext.WithLogger(request.Context(), logrus.NewEntry(logger)))
+
+	MiddlewareAccessLog(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		response.WriteHeader(http.StatusCreated)
+		_, _ = response.Write([]byte("test_body"))
+	})).ServeHTTP(httptest.NewRecorder(), request)
+
+	require.Len(t, hook.AllEntries(), 1)
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, http.StatusCreated, entry.Data["status"])
+	assert.Equal(t, len("test_body"), entry.Data["bytes"])
+	assert.Contains(t, entry.Data, "latency_ms")
+}
+
+func TestMiddlewareAccessLogDefaultStatus(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+	request = request.WithContext(logcontext.WithLogger(request.Context(), logrus.NewEntry(logger)))
+
+	MiddlewareAccessLog(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, http.StatusOK, hook.LastEntry().Data["status"])
+}
+
+func TestMiddlewareRecovery(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+	request = request.WithContext(logcontext.WithLogger(request.Context(), logrus.NewEntry(logger)))
+	response := httptest.NewRecorder()
+
+	MiddlewareRecovery(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("test_panic")
+	})).ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Equal(t, problemContentType, response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), ErrInternalServerError)
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, "test_panic", entry.Data["panic"])
+	assert.Contains(t, entry.Data["stack"], "TestMiddlewareRecovery")
+}
+
+func TestMiddlewareRecoveryIfResponseIsWritten(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+	request = request.WithContext(logcontext.WithLogger(request.Context(), logrus.NewEntry(logger)))
+	response := httptest.NewRecorder()
+
+	MiddlewareRecovery(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		response.WriteHeader(http.StatusAccepted)
+		panic("test_panic")
+	})).ServeHTTP(response, request)
+
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	assert.Empty(t, response.Body.String())
+}
 
-	result := MiddlewareLoggerInContext(request, logger)
+func TestMiddlewareRecoveryRepanicsIfHandlerIsAborted(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/api/books", nil)
 
-	assert.Equal(t, request.WithContext(contextWithLogger), result)
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		MiddlewareRecovery(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		})).ServeHTTP(httptest.NewRecorder(), request)
+	})
 }
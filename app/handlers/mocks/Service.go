@@ -18,26 +18,98 @@ type Service struct {
 }
 
 // CreateAuthor provides a mock function with given fields: ctx, authorName
-func (_m *Service) CreateAuthor(ctx context.Context, authorName string) error {
+func (_m *Service) CreateAuthor(ctx context.Context, authorName string) (models.Author, error) {
 	ret := _m.Called(ctx, authorName)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+	var r0 models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Author); ok {
 		r0 = rf(ctx, authorName)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(models.Author)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, authorName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateBook provides a mock function with given fields: ctx, title, contributors, details
+func (_m *Service) CreateBook(ctx context.Context, title string, contributors []models.Contributor, details models.BookDetails) (models.Book, error) {
+	ret := _m.Called(ctx, title, contributors, details)
+
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.Contributor, models.BookDetails) models.Book); ok {
+		r0 = rf(ctx, title, contributors, details)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []models.Contributor, models.BookDetails) error); ok {
+		r1 = rf(ctx, title, contributors, details)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// CreateBook provides a mock function with given fields: ctx, title, authorId
-func (_m *Service) CreateBook(ctx context.Context, title string, authorId uuid.UUID) error {
-	ret := _m.Called(ctx, title, authorId)
+// CreateBooks provides a mock function with given fields: ctx, books, atomic
+func (_m *Service) CreateBooks(ctx context.Context, books []models.BookInput, atomic bool) ([]models.BookResult, error) {
+	ret := _m.Called(ctx, books, atomic)
+
+	var r0 []models.BookResult
+	if rf, ok := ret.Get(0).(func(context.Context, []models.BookInput, bool) []models.BookResult); ok {
+		r0 = rf(ctx, books, atomic)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BookResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []models.BookInput, bool) error); ok {
+		r1 = rf(ctx, books, atomic)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAuthor provides a mock function with given fields: ctx, authorId, version
+func (_m *Service) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	ret := _m.Called(ctx, authorId, version)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) int64); ok {
+		r0 = rf(ctx, authorId, version)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, int64) error); ok {
+		r1 = rf(ctx, authorId, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteBook provides a mock function with given fields: ctx, bookId, version
+func (_m *Service) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	ret := _m.Called(ctx, bookId, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, uuid.UUID) error); ok {
-		r0 = rf(ctx, title, authorId)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) error); ok {
+		r0 = rf(ctx, bookId, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -45,17 +117,15 @@ func (_m *Service) CreateBook(ctx context.Context, title string, authorId uuid.U
 	return r0
 }
 
-// GetAuthorsBooks provides a mock function with given fields: ctx, authorId
-func (_m *Service) GetAuthorsBooks(ctx context.Context, authorId uuid.UUID) ([]models.Book, error) {
+// GetAuthor provides a mock function with given fields: ctx, authorId
+func (_m *Service) GetAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
 	ret := _m.Called(ctx, authorId)
 
-	var r0 []models.Book
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.Book); ok {
+	var r0 models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) models.Author); ok {
 		r0 = rf(ctx, authorId)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Book)
-		}
+		r0 = ret.Get(0).(models.Author)
 	}
 
 	var r1 error
@@ -68,6 +138,48 @@ func (_m *Service) GetAuthorsBooks(ctx context.Context, authorId uuid.UUID) ([]m
 	return r0, r1
 }
 
+// GetAuthors provides a mock function with given fields: ctx, options
+func (_m *Service) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	ret := _m.Called(ctx, options)
+
+	var r0 models.Page[models.Author]
+	if rf, ok := ret.Get(0).(func(context.Context, models.ListOptions) models.Page[models.Author]); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.Author])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.ListOptions) error); ok {
+		r1 = rf(ctx, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAuthorsBooks provides a mock function with given fields: ctx, authorId, options
+func (_m *Service) GetAuthorsBooks(ctx context.Context, authorId uuid.UUID, options models.ListOptions) (models.Page[models.Book], error) {
+	ret := _m.Called(ctx, authorId, options)
+
+	var r0 models.Page[models.Book]
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.ListOptions) models.Page[models.Book]); ok {
+		r0 = rf(ctx, authorId, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.Book])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, models.ListOptions) error); ok {
+		r1 = rf(ctx, authorId, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBook provides a mock function with given fields: ctx, bookId
 func (_m *Service) GetBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
 	ret := _m.Called(ctx, bookId)
@@ -89,6 +201,174 @@ func (_m *Service) GetBook(ctx context.Context, bookId uuid.UUID) (models.Book,
 	return r0, r1
 }
 
+// GetBookByISBN provides a mock function with given fields: ctx, isbn
+func (_m *Service) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	ret := _m.Called(ctx, isbn)
+
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Book); ok {
+		r0 = rf(ctx, isbn)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, isbn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBooks provides a mock function with given fields: ctx, options
+func (_m *Service) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	ret := _m.Called(ctx, options)
+
+	var r0 models.Page[models.Book]
+	if rf, ok := ret.Get(0).(func(context.Context, models.ListOptions) models.Page[models.Book]); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.Book])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.ListOptions) error); ok {
+		r1 = rf(ctx, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrash provides a mock function with given fields: ctx, itemType, options
+func (_m *Service) GetTrash(ctx context.Context, itemType string, options models.ListOptions) (models.Page[models.TrashItem], error) {
+	ret := _m.Called(ctx, itemType, options)
+
+	var r0 models.Page[models.TrashItem]
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.ListOptions) models.Page[models.TrashItem]); ok {
+		r0 = rf(ctx, itemType, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.TrashItem])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.ListOptions) error); ok {
+		r1 = rf(ctx, itemType, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreAuthor provides a mock function with given fields: ctx, authorId
+func (_m *Service) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	ret := _m.Called(ctx, authorId)
+
+	var r0 models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) models.Author); ok {
+		r0 = rf(ctx, authorId)
+	} else {
+		r0 = ret.Get(0).(models.Author)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, authorId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreBook provides a mock function with given fields: ctx, bookId
+func (_m *Service) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	ret := _m.Called(ctx, bookId)
+
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) models.Book); ok {
+		r0 = rf(ctx, bookId)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, bookId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, text, resultType, options
+func (_m *Service) Search(ctx context.Context, text string, resultType string, options models.ListOptions) (models.Page[models.SearchResult], error) {
+	ret := _m.Called(ctx, text, resultType, options)
+
+	var r0 models.Page[models.SearchResult]
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.ListOptions) models.Page[models.SearchResult]); ok {
+		r0 = rf(ctx, text, resultType, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.SearchResult])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, models.ListOptions) error); ok {
+		r1 = rf(ctx, text, resultType, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAuthor provides a mock function with given fields: ctx, authorId, authorName, version
+func (_m *Service) UpdateAuthor(ctx context.Context, authorId uuid.UUID, authorName string, version int64) (models.Author, error) {
+	ret := _m.Called(ctx, authorId, authorName, version)
+
+	var r0 models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, int64) models.Author); ok {
+		r0 = rf(ctx, authorId, authorName, version)
+	} else {
+		r0 = ret.Get(0).(models.Author)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, int64) error); ok {
+		r1 = rf(ctx, authorId, authorName, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateBook provides a mock function with given fields: ctx, bookId, title, contributors, details, version
+func (_m *Service) UpdateBook(ctx context.Context, bookId uuid.UUID, title string, contributors []models.Contributor, details models.BookDetails, version int64) (models.Book, error) {
+	ret := _m.Called(ctx, bookId, title, contributors, details, version)
+
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []models.Contributor, models.BookDetails, int64) models.Book); ok {
+		r0 = rf(ctx, bookId, title, contributors, details, version)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, []models.Contributor, models.BookDetails, int64) error); ok {
+		r1 = rf(ctx, bookId, title, contributors, details, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewService interface {
 	mock.TestingT
 	Cleanup(func())
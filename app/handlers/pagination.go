@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/egormizerov/books/app/models"
+)
+
+var ErrInvalidQueryParameters = "Invalid query parameters."
+
+// ListResponseBody is the page returned by the list endpoints.
+type ListResponseBody[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// parseListOptions reads the page of the list from the query parameters, prefixParameter is the name
+// of the parameter filtering the list by prefix, it is empty if the list is not filtered by prefix.
+func parseListOptions(request *http.Request, prefixParameter string) (models.ListOptions, *ProblemField) {
+	query := request.URL.Query()
+	options := models.ListOptions{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+	}
+	if prefixParameter != "" {
+		options.Prefix = query.Get(prefixParameter)
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > models.MaxPageLimit {
+			return models.ListOptions{}, &ProblemField{
+				Field:   "limit",
+				Message: fmt.Sprintf("must be a number between 1 and %d", models.MaxPageLimit),
+			}
+		}
+		options.Limit = value
+	}
+
+	return options, nil
+}
+
+// writePage responds with the page and the Link header pointing to the next page.
+func writePage[T any](response http.ResponseWriter, request *http.Request, page models.Page[T], message string) {
+	body := ListResponseBody[T]{
+		Items:      page.Items,
+		NextCursor: page.NextCursor,
+	}
+	if body.Items == nil {
+		body.Items = []T{}
+	}
+	bodyJson, err := json.Marshal(body)
+	if err != nil {
+		writeProblem(response, request, http.StatusInternalServerError, message)
+		return
+	}
+
+	if page.NextCursor != "" {
+		response.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextPageUrl(request.URL, page.NextCursor)))
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(bodyJson)
+}
+
+func nextPageUrl(requestUrl *url.URL, nextCursor string) string {
+	query := requestUrl.Query()
+	query.Set("cursor", nextCursor)
+	nextUrl := url.URL{Path: requestUrl.Path, RawQuery: query.Encode()}
+	return nextUrl.String()
+}
@@ -3,17 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 
 	"github.com/egormizerov/books/app/config"
-	"github.com/egormizerov/books/app/database"
-	"github.com/egormizerov/books/app/database/client"
 	"github.com/egormizerov/books/app/handlers"
 	"github.com/egormizerov/books/app/services"
+	"github.com/egormizerov/books/pkg/health"
 	"github.com/egormizerov/books/pkg/log"
+	"github.com/egormizerov/books/pkg/metrics"
 	"github.com/egormizerov/books/pkg/process"
 	"github.com/egormizerov/books/pkg/server"
 	"github.com/egormizerov/books/pkg/wrappers"
@@ -26,37 +27,81 @@ func main() {
 
 	appConfig := config.NewAppConfig()
 	logger := log.NewLogrusLogger(appConfig.LoggerEnableJson, appConfig.LoggerLogLevel)
-	databaseConnection, err := database.ConnectToDatabase(&wrappers.SimpleSqlxWrapper{}, database.ConnectConfig{
-		User:     appConfig.DatabaseUser,
-		Password: appConfig.DatabasePassword,
-		Host:     appConfig.DatabaseHost,
-		Port:     appConfig.DatabasePort,
-		Database: appConfig.DatabaseDatabase,
-	})
-	if err != nil {
-		logger.
-			WithError(err).
-			Fatal("failed to get database connection")
+	if len(os.Args) > 1 && os.Args[1] == migrateCommand {
+		migrator := newMigrator(logger, appConfig, connectToDatabase(logger, appConfig))
+		if err := runMigrateCommand(context.Background(), logger, migrator, os.Args[2:]); err != nil {
+			logger.
+				WithError(err).
+				Fatal("failed to migrate database")
+		}
+		return
 	}
 
-	databaseClient := client.NewDatabaseClient(databaseConnection)
+	registry := metrics.NewRegistry()
+	storage := newStorage(logger, appConfig, registry)
+	var databaseClient services.DatabaseClient = services.NewMetricsDatabaseClient(storage.databaseClient, registry)
+	if appConfig.CacheCapacity > 0 {
+		databaseClient = services.NewCachingDatabaseClient(
+			databaseClient,
+			services.CacheOptions{
+				Capacity:    appConfig.CacheCapacity,
+				TTL:         appConfig.CacheTTL,
+				LoadTimeout: appConfig.CacheLoadTimeout,
+			},
+			registry,
+		)
+	}
 	service := services.NewService(databaseClient, &wrappers.SimpleUUIDWrapper{})
-	handler := handlers.NewHandler(logger, service, validator.New())
+	readiness := health.New(appConfig.HealthCheckTimeout)
+	if storage.readinessCheck != nil {
+		readiness.Register("database", storage.readinessCheck)
+	}
+	handler := handlers.NewHandler(logger, service, validator.New(), registry, readiness)
 	serverHost := fmt.Sprintf("%s:%s", appConfig.ServerHost, appConfig.ServerPort)
 	httpServer := server.NewServer(serverHost, handler)
-	go func() {
-		if err = httpServer.Listen(); err != nil {
-			logger.
-				WithError(err).
-				Fatal("server unexpectedly stopped")
-		}
-	}()
-	logger.Info("server has started")
 
-	process.WaitForTermination()
-	if err = httpServer.Shutdown(context.Background()); err != nil {
+	// Components are stopped in the reverse order: the readiness drains first, then the server
+	// finishes the active requests, the purge of the trash is interrupted and the database is closed last.
+	lifecycle := process.NewLifecycle(logger, appConfig.ServerShutdownTimeout)
+	if storage.close != nil {
+		lifecycle.Add(process.Component{
+			Name: "database",
+			Stop: func(context.Context) error { return storage.close() },
+		})
+	}
+	if appConfig.TrashPurgeInterval > 0 {
+		purger := services.NewTrashPurger(databaseClient, logger, services.PurgeOptions{
+			Retention: appConfig.TrashRetention,
+			Interval:  appConfig.TrashPurgeInterval,
+		})
+		lifecycle.Add(process.Component{
+			Name: "trash purger",
+			Run:  purger.Run,
+			Stop: purger.Stop,
+		})
+	}
+	lifecycle.Add(process.Component{
+		Name: "http server",
+		Run:  httpServer.Listen,
+		Stop: httpServer.Shutdown,
+	})
+	lifecycle.Add(process.Component{
+		Name: "readiness",
+		Stop: func(ctx context.Context) error {
+			readiness.Drain()
+			logger.Info("server is draining")
+			return process.Sleep(ctx, appConfig.ServerDrainDelay)
+		},
+	})
+
+	ctx, stop := process.TerminationContext(context.Background())
+	logger.Info("server has started")
+	err := lifecycle.Run(ctx)
+	stop()
+	if err != nil {
 		logger.
 			WithError(err).
-			Fatal("failed to shutdown server")
+			Error("server stopped with error")
 	}
+	os.Exit(process.ExitCode(err))
 }
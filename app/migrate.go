@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/egormizerov/books/pkg/migrate"
+)
+
+const (
+	migrateCommand = "migrate"
+
+	migrateCommandUp     = "up"
+	migrateCommandDown   = "down"
+	migrateCommandStatus = "status"
+)
+
+var errUnknownMigrateCommand = errors.New("usage: books migrate up|down|status")
+
+// runMigrateCommand handles `books migrate up|down|status`.
+func runMigrateCommand(ctx context.Context, logger logrus.FieldLogger, migrator *migrate.Migrator, args []string) error {
+	if len(args) != 1 {
+		return errUnknownMigrateCommand
+	}
+
+	switch args[0] {
+	case migrateCommandUp:
+		return migrateUp(ctx, logger, migrator)
+	case migrateCommandDown:
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		logger.
+			WithField("migration", migrationName(migration)).
+			Info("migration reverted")
+	case migrateCommandStatus:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = fmt.Sprintf("applied at %s", status.AppliedAt.Format("2006-01-02 15:04:05 MST"))
+			}
+			fmt.Printf("%s\t%s\n", migrationName(status.Migration), state)
+		}
+	default:
+		return errUnknownMigrateCommand
+	}
+
+	return nil
+}
+
+func migrateUp(ctx context.Context, logger logrus.FieldLogger, migrator *migrate.Migrator) error {
+	migrations, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		logger.
+			WithField("migration", migrationName(migration)).
+			Info("migration applied")
+	}
+
+	return nil
+}
+
+func migrationName(migration migrate.Migration) string {
+	return fmt.Sprintf("%04d_%s", migration.Version, migration.Name)
+}
@@ -1,18 +1,17 @@
 package models
 
-import (
-	"errors"
-	"github.com/google/uuid"
-)
+import "github.com/google/uuid"
 
 type Author struct {
-	ID   uuid.UUID
-	Name string
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Version is exposed as the ETag rather than in the body, it is not set for the book contributors.
+	Version int64 `json:"-"`
 }
 
 func NewAuthor(name string, authorId uuid.UUID) (Author, error) {
 	if name == "" {
-		return Author{}, errors.New("author name must not be empty")
+		return Author{}, ValidationError{Field: "name", Message: "author name must not be empty"}
 	}
 	return Author{
 		ID:   authorId,
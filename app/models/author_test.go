@@ -11,6 +11,7 @@ func TestNewAuthorErrorIfInvalidName(t *testing.T) {
 	result, err := NewAuthor("", uuid.New())
 
 	assert.EqualError(t, err, "author name must not be empty")
+	assert.ErrorIs(t, err, ErrValidation)
 	assert.Equal(t, Author{}, result)
 }
 
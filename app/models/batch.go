@@ -0,0 +1,23 @@
+package models
+
+import "errors"
+
+// MaxBatchSize is the maximum number of items created by a single batch.
+const MaxBatchSize = 1000
+
+// ErrBatchAborted is returned for the valid items of the atomic batch which were not created
+// because another item of the batch failed.
+var ErrBatchAborted = errors.New("batch aborted")
+
+// BookInput is the book requested to be created by the batch.
+type BookInput struct {
+	Title        string
+	Contributors []Contributor
+	Details      BookDetails
+}
+
+// BookResult is the result of the book of the batch, Err is set if the book was not created.
+type BookResult struct {
+	Book Book
+	Err  error
+}
@@ -1,23 +1,69 @@
 package models
 
 import (
-	"errors"
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Book struct {
-	ID     uuid.UUID
-	Title  string
-	Author Author
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	// Contributors are ordered as they are credited, the book has none if all of its authors have been deleted.
+	Contributors []Contributor `json:"contributors"`
+	// ISBN is the normalized ISBN-13, it is empty if the book has none.
+	ISBN            string     `json:"isbn"`
+	PublicationDate *time.Time `json:"publication_date"`
+	Description     string     `json:"description"`
+	// Language is the ISO 639-1 code, it is empty if the language is unknown.
+	Language string `json:"language"`
+	// PageCount is zero if the number of pages is unknown.
+	PageCount int       `json:"page_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Version is also incremented when the contributors are renamed or deleted, as they are part of the book.
+	// It is exposed as the ETag rather than in the body.
+	Version int64 `json:"-"`
+}
+
+// BookDetails are the optional attributes of the book.
+type BookDetails struct {
+	ISBN            string
+	PublicationDate *time.Time
+	Description     string
+	Language        string
+	PageCount       int
 }
 
-func NewBook(title string, bookId uuid.UUID, author Author) (Book, error) {
+func NewBook(title string, bookId uuid.UUID, contributors []Contributor, details BookDetails) (Book, error) {
 	if title == "" {
-		return Book{}, errors.New("book title must not be empty")
+		return Book{}, ValidationError{Field: "title", Message: "book title must not be empty"}
+	}
+	contributors, err := validateContributors(contributors)
+	if err != nil {
+		return Book{}, err
+	}
+	if details.ISBN != "" {
+		if details.ISBN, err = NormalizeISBN(details.ISBN); err != nil {
+			return Book{}, err
+		}
+	}
+	if details.Language != "" {
+		if details.Language, err = NormalizeLanguage(details.Language); err != nil {
+			return Book{}, err
+		}
+	}
+	if details.PageCount < 0 {
+		return Book{}, ValidationError{Field: "page_count", Message: "book page count must not be negative"}
 	}
 	return Book{
-		ID:     bookId,
-		Title:  title,
-		Author: author,
+		ID:              bookId,
+		Title:           title,
+		Contributors:    contributors,
+		ISBN:            details.ISBN,
+		PublicationDate: details.PublicationDate,
+		Description:     details.Description,
+		Language:        details.Language,
+		PageCount:       details.PageCount,
 	}, nil
 }
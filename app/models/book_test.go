@@ -1,16 +1,62 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNewBookErrorIfInvalidTitle(t *testing.T) {
-	result, err := NewBook("", uuid.New(), Author{})
+	result, err := NewBook("", uuid.New(), nil, BookDetails{})
 
 	assert.EqualError(t, err, "book title must not be empty")
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, Book{}, result)
+}
+
+func TestNewBookErrorIfInvalidISBN(t *testing.T) {
+	result, err := NewBook("test_title", uuid.New(), nil, BookDetails{ISBN: "978-0-306-40615-8"})
+
+	assert.Equal(t, ValidationError{Field: "isbn", Message: "book ISBN must be a valid ISBN-10 or ISBN-13"}, err)
+	assert.Equal(t, Book{}, result)
+}
+
+func TestNewBookErrorIfInvalidLanguage(t *testing.T) {
+	result, err := NewBook("test_title", uuid.New(), nil, BookDetails{Language: "english"})
+
+	assert.Equal(t, ValidationError{Field: "language", Message: "book language must be an ISO 639-1 code"}, err)
+	assert.Equal(t, Book{}, result)
+}
+
+func TestNewBookErrorIfNegativePageCount(t *testing.T) {
+	result, err := NewBook("test_title", uuid.New(), nil, BookDetails{PageCount: -1})
+
+	assert.Equal(t, ValidationError{Field: "page_count", Message: "book page count must not be negative"}, err)
+	assert.Equal(t, Book{}, result)
+}
+
+func TestNewBookErrorIfInvalidContributorRole(t *testing.T) {
+	result, err := NewBook("test_title", uuid.New(), []Contributor{{Author: Author{ID: uuid.New()}, Role: "reader"}}, BookDetails{})
+
+	assert.Equal(t, ValidationError{
+		Field:   "contributors",
+		Message: "book contributor role must be author, editor, translator or illustrator",
+	}, err)
+	assert.Equal(t, Book{}, result)
+}
+
+func TestNewBookErrorIfRepeatedContributor(t *testing.T) {
+	author := Author{ID: uuid.New()}
+
+	result, err := NewBook("test_title", uuid.New(), []Contributor{
+		{Author: author},
+		{Author: author, Role: ContributorRoleAuthor},
+	}, BookDetails{})
+
+	assert.Equal(t, ValidationError{Field: "contributors", Message: "book contributor must not be repeated in the same role"}, err)
 	assert.Equal(t, Book{}, result)
 }
 
@@ -21,13 +67,66 @@ func TestNewBook(t *testing.T) {
 		ID:   uuid.New(),
 		Name: "test_name",
 	}
+	translator := Author{
+		ID:   uuid.New(),
+		Name: "test_translator",
+	}
+	publicationDate := time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	result, err := NewBook(bookTitle, bookId, author)
+	result, err := NewBook(bookTitle, bookId, []Contributor{
+		{Author: author},
+		{Author: translator, Role: ContributorRoleTranslator},
+	}, BookDetails{
+		ISBN:            "0-306-40615-2",
+		PublicationDate: &publicationDate,
+		Description:     "test_description",
+		Language:        "EN",
+		PageCount:       256,
+	})
 
 	assert.NoError(t, err)
 	assert.Equal(t, Book{
-		ID:     bookId,
-		Title:  bookTitle,
-		Author: author,
+		ID:    bookId,
+		Title: bookTitle,
+		Contributors: []Contributor{
+			{Author: author, Role: ContributorRoleAuthor},
+			{Author: translator, Role: ContributorRoleTranslator},
+		},
+		ISBN:            "9780306406157",
+		PublicationDate: &publicationDate,
+		Description:     "test_description",
+		Language:        "en",
+		PageCount:       256,
 	}, result)
 }
+
+func TestBookMarshalsSnakeCaseFields(t *testing.T) {
+	publicationDate := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	book := Book{
+		ID:              uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		Title:           "test_title",
+		Contributors:    []Contributor{{Author: Author{ID: uuid.MustParse("6ba7b811-9dad-11d1-80b4-00c04fd430c8"), Name: "test_name", Version: 2}, Role: ContributorRoleAuthor}},
+		ISBN:            "9780306406157",
+		PublicationDate: &publicationDate,
+		PageCount:       10,
+		CreatedAt:       publicationDate,
+		UpdatedAt:       publicationDate,
+		Version:         3,
+	}
+
+	result, err := json.Marshal(book)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"title": "test_title",
+		"contributors": [{"author": {"id": "6ba7b811-9dad-11d1-80b4-00c04fd430c8", "name": "test_name"}, "role": "author"}],
+		"isbn": "9780306406157",
+		"publication_date": "2001-02-03T00:00:00Z",
+		"description": "",
+		"language": "",
+		"page_count": 10,
+		"created_at": "2001-02-03T00:00:00Z",
+		"updated_at": "2001-02-03T00:00:00Z"
+	}`, string(result))
+}
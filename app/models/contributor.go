@@ -0,0 +1,46 @@
+package models
+
+// Roles of the book contributors.
+const (
+	ContributorRoleAuthor      = "author"
+	ContributorRoleEditor      = "editor"
+	ContributorRoleTranslator  = "translator"
+	ContributorRoleIllustrator = "illustrator"
+)
+
+// Contributor is the author taking part in the book in the role.
+type Contributor struct {
+	Author Author `json:"author"`
+	Role   string `json:"role"`
+}
+
+// validateContributors sets the default role of the contributors and checks the roles are known and not repeated.
+func validateContributors(contributors []Contributor) ([]Contributor, error) {
+	type contribution struct {
+		author string
+		role   string
+	}
+
+	validated := make([]Contributor, 0, len(contributors))
+	seen := map[contribution]struct{}{}
+	for _, contributor := range contributors {
+		switch contributor.Role {
+		case "":
+			contributor.Role = ContributorRoleAuthor
+		case ContributorRoleAuthor, ContributorRoleEditor, ContributorRoleTranslator, ContributorRoleIllustrator:
+		default:
+			return nil, ValidationError{
+				Field:   "contributors",
+				Message: "book contributor role must be author, editor, translator or illustrator",
+			}
+		}
+		key := contribution{author: contributor.Author.ID.String(), role: contributor.Role}
+		if _, ok := seen[key]; ok {
+			return nil, ValidationError{Field: "contributors", Message: "book contributor must not be repeated in the same role"}
+		}
+		seen[key] = struct{}{}
+		validated = append(validated, contributor)
+	}
+
+	return validated, nil
+}
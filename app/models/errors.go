@@ -0,0 +1,54 @@
+package models
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the entity conflicts with an existing one.
+	ErrConflict = errors.New("conflict")
+	// ErrValidation is returned when the entity is invalid.
+	ErrValidation = errors.New("validation failed")
+	// ErrVersionMismatch is returned when the entity was changed since the version expected by the write.
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// ValidationError describes the invalid field of the entity, it matches ErrValidation.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (self ValidationError) Error() string {
+	return self.Message
+}
+
+func (self ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// NotFoundError describes the missing entity, it matches ErrNotFound.
+type NotFoundError struct {
+	Entity string
+}
+
+func (self NotFoundError) Error() string {
+	return self.Entity + " not found"
+}
+
+func (self NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// VersionMismatchError describes the entity changed since the expected version, it matches ErrVersionMismatch.
+type VersionMismatchError struct {
+	Entity string
+}
+
+func (self VersionMismatchError) Error() string {
+	return self.Entity + " version mismatch"
+}
+
+func (self VersionMismatchError) Is(target error) bool {
+	return target == ErrVersionMismatch
+}
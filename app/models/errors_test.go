@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationError(t *testing.T) {
+	err := ValidationError{Field: "test_field", Message: "test_message"}
+
+	assert.EqualError(t, err, "test_message")
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, fmt.Errorf("wrapped: %w", err), ErrValidation)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestNotFoundError(t *testing.T) {
+	err := NotFoundError{Entity: "book"}
+
+	assert.EqualError(t, err, "book not found")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, fmt.Errorf("wrapped: %w", err), ErrNotFound)
+	assert.False(t, errors.Is(err, ErrValidation))
+}
+
+func TestVersionMismatchError(t *testing.T) {
+	err := VersionMismatchError{Entity: "book"}
+
+	assert.EqualError(t, err, "book version mismatch")
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	assert.ErrorIs(t, fmt.Errorf("wrapped: %w", err), ErrVersionMismatch)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
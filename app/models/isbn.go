@@ -0,0 +1,68 @@
+package models
+
+import "strings"
+
+var errInvalidISBN = ValidationError{Field: "isbn", Message: "book ISBN must be a valid ISBN-10 or ISBN-13"}
+
+// NormalizeISBN validates the checksum of the ISBN-10 or ISBN-13 and returns it as the ISBN-13 without separators.
+func NormalizeISBN(isbn string) (string, error) {
+	isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+
+	switch len(isbn) {
+	case 10:
+		if !isDigits(isbn[:9]) || !validISBN10Checksum(isbn) {
+			return "", errInvalidISBN
+		}
+		isbn = "978" + isbn[:9]
+		return isbn + isbn13CheckDigit(isbn), nil
+	case 13:
+		if !isDigits(isbn) || !(strings.HasPrefix(isbn, "978") || strings.HasPrefix(isbn, "979")) {
+			return "", errInvalidISBN
+		}
+		if isbn13CheckDigit(isbn[:12]) != isbn[12:] {
+			return "", errInvalidISBN
+		}
+		return isbn, nil
+	default:
+		return "", errInvalidISBN
+	}
+}
+
+func validISBN10Checksum(isbn string) bool {
+	sum := 0
+	for index, char := range isbn {
+		var digit int
+		switch {
+		case char >= '0' && char <= '9':
+			digit = int(char - '0')
+		case char == 'X' && index == 9:
+			digit = 10
+		default:
+			return false
+		}
+		sum += (10 - index) * digit
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit returns the check digit of the first 12 digits of the ISBN-13.
+func isbn13CheckDigit(isbn string) string {
+	sum := 0
+	for index, char := range isbn[:12] {
+		weight := 1
+		if index%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(char-'0')
+	}
+	return string(rune('0' + (10-sum%10)%10))
+}
+
+func isDigits(value string) bool {
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
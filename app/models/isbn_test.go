@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	for isbn, expected := range map[string]string{
+		"9780306406157":     "9780306406157",
+		"978-0-306-40615-7": "9780306406157",
+		"0306406152":        "9780306406157",
+		"0 306 40615 2":     "9780306406157",
+		"080442957x":        "9780804429573",
+		"979-10-90636-07-1": "9791090636071",
+	} {
+		result, err := NormalizeISBN(isbn)
+
+		assert.NoError(t, err, isbn)
+		assert.Equal(t, expected, result, isbn)
+	}
+}
+
+func TestNormalizeISBNErrorIfInvalid(t *testing.T) {
+	for _, isbn := range []string{
+		"",
+		"978030640615",
+		"9780306406158",
+		"9770306406157",
+		"0306406153",
+		"03064061X2",
+		"abcdefghij",
+	} {
+		result, err := NormalizeISBN(isbn)
+
+		assert.ErrorIs(t, err, ErrValidation, isbn)
+		assert.Empty(t, result, isbn)
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	result, err := NormalizeLanguage(" Ru ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ru", result)
+}
+
+func TestNormalizeLanguageErrorIfUnknown(t *testing.T) {
+	result, err := NormalizeLanguage("xx")
+
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Empty(t, result)
+}
@@ -0,0 +1,27 @@
+package models
+
+const (
+	// DefaultPageLimit is the number of items returned when the limit is not requested.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the maximum number of items returned in a single page.
+	MaxPageLimit = 100
+)
+
+// ListOptions describes the requested page of the list.
+type ListOptions struct {
+	// Limit is the maximum number of items in the page, DefaultPageLimit is used if it is zero.
+	Limit int
+	// Cursor is the opaque position after which the page starts, the first page is returned if it is empty.
+	Cursor string
+	// Sort is the name of the sort order, prefixed by "-" for the descending order.
+	Sort string
+	// Prefix filters the items by the beginning of their title or name.
+	Prefix string
+}
+
+// Page is the slice of the list.
+type Page[T any] struct {
+	Items []T
+	// NextCursor is the cursor of the next page, it is empty for the last page.
+	NextCursor string
+}
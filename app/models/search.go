@@ -0,0 +1,40 @@
+package models
+
+import "github.com/google/uuid"
+
+// Types of the search results.
+const (
+	SearchResultTypeBook   = "book"
+	SearchResultTypeAuthor = "author"
+)
+
+// SearchQuery is the full-text search request.
+type SearchQuery struct {
+	Text string
+	// Type limits the results to the single type, results of all types are returned if it is empty.
+	Type string
+}
+
+func NewSearchQuery(text string, resultType string) (SearchQuery, error) {
+	if text == "" {
+		return SearchQuery{}, ValidationError{Field: "q", Message: "search query must not be empty"}
+	}
+	if resultType != "" && resultType != SearchResultTypeBook && resultType != SearchResultTypeAuthor {
+		return SearchQuery{}, ValidationError{Field: "type", Message: "search result type must be book or author"}
+	}
+	return SearchQuery{
+		Text: text,
+		Type: resultType,
+	}, nil
+}
+
+// SearchResult is the book or the author matching the search query.
+type SearchResult struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+	// Title is the title of the book or the name of the author.
+	Title string `json:"title"`
+	// Snippet is the title with the matched words highlighted.
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSearchQueryErrorIfEmptyText(t *testing.T) {
+	result, err := NewSearchQuery("", "")
+
+	assert.EqualError(t, err, "search query must not be empty")
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, SearchQuery{}, result)
+}
+
+func TestNewSearchQueryErrorIfUnknownType(t *testing.T) {
+	result, err := NewSearchQuery("test_text", "test_type")
+
+	assert.EqualError(t, err, "search result type must be book or author")
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, SearchQuery{}, result)
+}
+
+func TestNewSearchQuery(t *testing.T) {
+	result, err := NewSearchQuery("test_text", SearchResultTypeBook)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SearchQuery{
+		Text: "test_text",
+		Type: SearchResultTypeBook,
+	}, result)
+}
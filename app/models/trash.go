@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the trash items.
+const (
+	TrashItemTypeBook   = "book"
+	TrashItemTypeAuthor = "author"
+)
+
+// TrashQuery filters the deleted books and authors.
+type TrashQuery struct {
+	// Type limits the items to the single type, items of all types are returned if it is empty.
+	Type string
+}
+
+func NewTrashQuery(itemType string) (TrashQuery, error) {
+	if itemType != "" && itemType != TrashItemTypeBook && itemType != TrashItemTypeAuthor {
+		return TrashQuery{}, ValidationError{Field: "type", Message: "trash item type must be book or author"}
+	}
+	return TrashQuery{
+		Type: itemType,
+	}, nil
+}
+
+// TrashItem is the deleted book or author, it can be restored until it is purged.
+type TrashItem struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+	// Title is the title of the book or the name of the author.
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
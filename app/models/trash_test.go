@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrashQueryErrorIfUnknownType(t *testing.T) {
+	result, err := NewTrashQuery("test_type")
+
+	assert.EqualError(t, err, "trash item type must be book or author")
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, TrashQuery{}, result)
+}
+
+func TestNewTrashQuery(t *testing.T) {
+	for _, itemType := range []string{"", TrashItemTypeBook, TrashItemTypeAuthor} {
+		result, err := NewTrashQuery(itemType)
+
+		assert.NoError(t, err)
+		assert.Equal(t, TrashQuery{Type: itemType}, result)
+	}
+}
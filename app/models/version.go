@@ -0,0 +1,8 @@
+package models
+
+const (
+	// AnyVersion is the version expected by the unconditional write.
+	AnyVersion int64 = 0
+	// FirstVersion is the version of the created entity, every write increments it.
+	FirstVersion int64 = 1
+)
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/pkg/cache"
+	"github.com/egormizerov/books/pkg/metrics"
+)
+
+// Names of the caches of the database client.
+const (
+	cacheBook        = "book"
+	cacheAuthor      = "author"
+	cacheAuthorBooks = "author_books"
+)
+
+// Results of the cache lookups.
+const (
+	cacheResultHit  = "hit"
+	cacheResultMiss = "miss"
+)
+
+// authorBooksKey is the key of the page of the author's books.
+type authorBooksKey struct {
+	authorId uuid.UUID
+	options  models.ListOptions
+}
+
+// CacheOptions are the limits of each cache of the caching database client.
+type CacheOptions struct {
+	// Capacity is the maximum number of the cached values.
+	Capacity int
+	// TTL is the time after which the cached value is loaded again, the values never expire if it is zero.
+	TTL time.Duration
+	// LoadTimeout limits the load of the missing value, cache.DefaultLoadTimeout is used if it is zero.
+	LoadTimeout time.Duration
+}
+
+// CachingDatabaseClient caches the books, the authors and the pages of the author's books read by id.
+// The concurrent reads of the same missing value load it once. The writes invalidate the values they
+// may have changed, the books embed their contributors, so the author's writes invalidate the books
+// it contributes to, and the restore of the author, which is not listed by the books, invalidates all of them.
+// The invalidations run after the writes even if they failed, as the failed write may have been committed.
+// The writes of the other instances are not seen until the values expire.
+type CachingDatabaseClient struct {
+	databaseClient DatabaseClient
+	books          *cache.Cache[uuid.UUID, models.Book]
+	authors        *cache.Cache[uuid.UUID, models.Author]
+	authorBooks    *cache.Cache[authorBooksKey, models.Page[models.Book]]
+	requests       *metrics.CounterVec
+}
+
+func NewCachingDatabaseClient(
+	databaseClient DatabaseClient,
+	options CacheOptions,
+	registry *metrics.Registry,
+) *CachingDatabaseClient {
+	client := &CachingDatabaseClient{
+		databaseClient: databaseClient,
+		books:          cache.New[uuid.UUID, models.Book](options.Capacity, options.TTL, options.LoadTimeout),
+		authors:        cache.New[uuid.UUID, models.Author](options.Capacity, options.TTL, options.LoadTimeout),
+		authorBooks:    cache.New[authorBooksKey, models.Page[models.Book]](options.Capacity, options.TTL, options.LoadTimeout),
+		requests: metrics.NewCounterVec(
+			"books_cache_requests_total",
+			"Total number of the cache lookups.",
+			"cache", "result",
+		),
+	}
+	registry.Register(client.requests)
+
+	return client
+}
+
+// lookup returns the cached value of the key or loads it and records whether the value was cached.
+func lookup[K comparable, V any](
+	self *CachingDatabaseClient,
+	ctx context.Context,
+	name string,
+	values *cache.Cache[K, V],
+	key K,
+	load func(ctx context.Context) (V, error),
+) (V, error) {
+	value, hit, err := values.GetOrLoad(ctx, key, load)
+	result := cacheResultMiss
+	if hit {
+		result = cacheResultHit
+	}
+	self.requests.Inc(name, result)
+
+	return value, err
+}
+
+func (self *CachingDatabaseClient) CreateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	return self.databaseClient.CreateAuthor(ctx, author)
+}
+
+func (self *CachingDatabaseClient) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	defer self.authorBooks.Purge()
+	return self.databaseClient.CreateBook(ctx, book)
+}
+
+func (self *CachingDatabaseClient) CreateBooks(ctx context.Context, books []models.Book) ([]models.Book, error) {
+	defer self.authorBooks.Purge()
+	return self.databaseClient.CreateBooks(ctx, books)
+}
+
+func (self *CachingDatabaseClient) GetBookById(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	book, err := lookup(self, ctx, cacheBook, self.books, bookId, func(ctx context.Context) (models.Book, error) {
+		return self.databaseClient.GetBookById(ctx, bookId)
+	})
+	return cloneBook(book), err
+}
+
+func (self *CachingDatabaseClient) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	return self.databaseClient.GetBookByISBN(ctx, isbn)
+}
+
+func (self *CachingDatabaseClient) GetAuthorById(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	return lookup(self, ctx, cacheAuthor, self.authors, authorId, func(ctx context.Context) (models.Author, error) {
+		return self.databaseClient.GetAuthorById(ctx, authorId)
+	})
+}
+
+func (self *CachingDatabaseClient) GetBooksByAuthorId(
+	ctx context.Context,
+	authorId uuid.UUID,
+	options models.ListOptions,
+) (models.Page[models.Book], error) {
+	key := authorBooksKey{authorId: authorId, options: options}
+	books, err := lookup(self, ctx, cacheAuthorBooks, self.authorBooks, key, func(ctx context.Context) (models.Page[models.Book], error) {
+		return self.databaseClient.GetBooksByAuthorId(ctx, authorId, options)
+	})
+	if err != nil {
+		return models.Page[models.Book]{}, err
+	}
+
+	items := make([]models.Book, len(books.Items))
+	for index, book := range books.Items {
+		items[index] = cloneBook(book)
+	}
+	return models.Page[models.Book]{Items: items, NextCursor: books.NextCursor}, nil
+}
+
+func (self *CachingDatabaseClient) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	return self.databaseClient.GetAuthors(ctx, options)
+}
+
+func (self *CachingDatabaseClient) UpdateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	defer self.invalidateAuthor(author.ID)
+	return self.databaseClient.UpdateAuthor(ctx, author)
+}
+
+func (self *CachingDatabaseClient) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	defer self.invalidateAuthor(authorId)
+	return self.databaseClient.DeleteAuthor(ctx, authorId, version)
+}
+
+func (self *CachingDatabaseClient) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	return self.databaseClient.GetBooks(ctx, options)
+}
+
+func (self *CachingDatabaseClient) UpdateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	defer self.invalidateBook(book.ID)
+	return self.databaseClient.UpdateBook(ctx, book)
+}
+
+func (self *CachingDatabaseClient) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	defer self.invalidateBook(bookId)
+	return self.databaseClient.DeleteBook(ctx, bookId, version)
+}
+
+func (self *CachingDatabaseClient) Search(
+	ctx context.Context,
+	query models.SearchQuery,
+	options models.ListOptions,
+) (models.Page[models.SearchResult], error) {
+	return self.databaseClient.Search(ctx, query, options)
+}
+
+func (self *CachingDatabaseClient) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	defer self.invalidateBook(bookId)
+	return self.databaseClient.RestoreBook(ctx, bookId)
+}
+
+func (self *CachingDatabaseClient) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	defer self.invalidateRestoredAuthor(authorId)
+	return self.databaseClient.RestoreAuthor(ctx, authorId)
+}
+
+func (self *CachingDatabaseClient) GetTrash(
+	ctx context.Context,
+	query models.TrashQuery,
+	options models.ListOptions,
+) (models.Page[models.TrashItem], error) {
+	return self.databaseClient.GetTrash(ctx, query, options)
+}
+
+// PurgeTrash invalidates nothing, as the values in the trash are not cached.
+func (self *CachingDatabaseClient) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return self.databaseClient.PurgeTrash(ctx, deletedBefore)
+}
+
+// WithTx runs the unit of work on the uncached transaction, so it reads its own writes,
+// and invalidates all the caches after the transaction ends.
+func (self *CachingDatabaseClient) WithTx(ctx context.Context, unitOfWork func(tx DatabaseClient) error) error {
+	defer self.invalidateAll()
+	return self.databaseClient.WithTx(ctx, unitOfWork)
+}
+
+func (self *CachingDatabaseClient) invalidateBook(bookId uuid.UUID) {
+	self.books.Delete(bookId)
+	self.authorBooks.Purge()
+}
+
+func (self *CachingDatabaseClient) invalidateAuthor(authorId uuid.UUID) {
+	self.authors.Delete(authorId)
+	self.books.DeleteFunc(func(_ uuid.UUID, book models.Book) bool {
+		for _, contributor := range book.Contributors {
+			if contributor.Author.ID == authorId {
+				return true
+			}
+		}
+		return false
+	})
+	self.authorBooks.Purge()
+}
+
+func (self *CachingDatabaseClient) invalidateRestoredAuthor(authorId uuid.UUID) {
+	self.authors.Delete(authorId)
+	self.books.Purge()
+	self.authorBooks.Purge()
+}
+
+func (self *CachingDatabaseClient) invalidateAll() {
+	self.books.Purge()
+	self.authors.Purge()
+	self.authorBooks.Purge()
+}
+
+// cloneBook copies the contributors and the publication date of the book, so the caller can't change the cached book.
+func cloneBook(book models.Book) models.Book {
+	if book.Contributors != nil {
+		book.Contributors = append([]models.Contributor(nil), book.Contributors...)
+	}
+	if book.PublicationDate != nil {
+		publicationDate := *book.PublicationDate
+		book.PublicationDate = &publicationDate
+	}
+
+	return book
+}
@@ -0,0 +1,284 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/app/services"
+	"github.com/egormizerov/books/app/services/mocks"
+	"github.com/egormizerov/books/pkg/metrics"
+)
+
+type CachingDatabaseClientTests struct {
+	suite.Suite
+	client             *services.CachingDatabaseClient
+	mockDatabaseClient *mocks.DatabaseClient
+	registry           *metrics.Registry
+	ctx                context.Context
+
+	book      models.Book
+	testError error
+}
+
+func TestCachingDatabaseClient(t *testing.T) {
+	suite.Run(t, new(CachingDatabaseClientTests))
+}
+
+func (self *CachingDatabaseClientTests) SetupTest() {
+	self.mockDatabaseClient = mocks.NewDatabaseClient(self.T())
+	self.registry = metrics.NewRegistry()
+	self.client = services.NewCachingDatabaseClient(
+		self.mockDatabaseClient,
+		services.CacheOptions{Capacity: 10, TTL: time.Minute},
+		self.registry,
+	)
+	self.ctx = context.Background()
+
+	publicationDate := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	self.book = models.Book{
+		ID:              uuid.New(),
+		Title:           "test_title",
+		Contributors:    []models.Contributor{{Author: models.Author{ID: uuid.New(), Name: "test_name"}, Role: "author"}},
+		PublicationDate: &publicationDate,
+	}
+	self.testError = errors.New("test_error")
+}
+
+func (self *CachingDatabaseClientTests) metrics() string {
+	var buffer bytes.Buffer
+	self.Require().NoError(self.registry.Write(&buffer))
+	return buffer.String()
+}
+
+func (self *CachingDatabaseClientTests) TestGetBookById() {
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+
+	first, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	second, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(self.book, first)
+	self.Equal(self.book, second)
+	self.Contains(self.metrics(), `books_cache_requests_total{cache="book",result="hit"} 1`)
+	self.Contains(self.metrics(), `books_cache_requests_total{cache="book",result="miss"} 1`)
+}
+
+func (self *CachingDatabaseClientTests) TestGetBookByIdReturnsCopy() {
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+
+	first, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	first.Contributors[0].Role = "editor"
+	*first.PublicationDate = time.Time{}
+	second, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal("author", second.Contributors[0].Role)
+	self.Equal(2001, second.PublicationDate.Year())
+}
+
+func (self *CachingDatabaseClientTests) TestGetBookByIdDoesNotCacheError() {
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(models.Book{}, models.ErrNotFound).Once()
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.ErrorIs(err, models.ErrNotFound)
+	book, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(self.book, book)
+}
+
+func (self *CachingDatabaseClientTests) TestGetBooksByAuthorId() {
+	authorId := self.book.Contributors[0].Author.ID
+	options := models.ListOptions{Limit: 1}
+	page := models.Page[models.Book]{Items: []models.Book{self.book}, NextCursor: "test_cursor"}
+	self.mockDatabaseClient.On("GetBooksByAuthorId", mock.Anything, authorId, options).Return(page, nil).Once()
+	self.mockDatabaseClient.On("GetBooksByAuthorId", mock.Anything, authorId, models.ListOptions{}).
+		Return(models.Page[models.Book]{}, nil).Once()
+
+	first, err := self.client.GetBooksByAuthorId(self.ctx, authorId, options)
+	self.NoError(err)
+	second, err := self.client.GetBooksByAuthorId(self.ctx, authorId, options)
+	self.NoError(err)
+	_, err = self.client.GetBooksByAuthorId(self.ctx, authorId, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal(page, first)
+	self.Equal(page, second)
+	self.Contains(self.metrics(), `books_cache_requests_total{cache="author_books",result="hit"} 1`)
+	self.Contains(self.metrics(), `books_cache_requests_total{cache="author_books",result="miss"} 2`)
+}
+
+func (self *CachingDatabaseClientTests) TestUpdateBookInvalidatesBook() {
+	updated := self.book
+	updated.Title = "test_updated_title"
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+	self.mockDatabaseClient.On("UpdateBook", self.ctx, updated).Return(updated, nil).Once()
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(updated, nil).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	_, err = self.client.UpdateBook(self.ctx, updated)
+	self.NoError(err)
+	book, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(updated, book)
+}
+
+func (self *CachingDatabaseClientTests) TestDeleteBookInvalidatesBookIfDatabaseClientFailed() {
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+	self.mockDatabaseClient.On("DeleteBook", self.ctx, self.book.ID, models.AnyVersion).Return(self.testError).Once()
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(models.Book{}, models.ErrNotFound).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	err = self.client.DeleteBook(self.ctx, self.book.ID, models.AnyVersion)
+	self.ErrorIs(err, self.testError)
+	_, err = self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.ErrorIs(err, models.ErrNotFound)
+}
+
+func (self *CachingDatabaseClientTests) TestCreateBookInvalidatesAuthorBooks() {
+	authorId := self.book.Contributors[0].Author.ID
+	page := models.Page[models.Book]{Items: []models.Book{self.book}}
+	self.mockDatabaseClient.On("GetBooksByAuthorId", mock.Anything, authorId, models.ListOptions{}).
+		Return(models.Page[models.Book]{}, nil).Once()
+	self.mockDatabaseClient.On("CreateBook", self.ctx, self.book).Return(self.book, nil).Once()
+	self.mockDatabaseClient.On("GetBooksByAuthorId", mock.Anything, authorId, models.ListOptions{}).Return(page, nil).Once()
+
+	_, err := self.client.GetBooksByAuthorId(self.ctx, authorId, models.ListOptions{})
+	self.NoError(err)
+	_, err = self.client.CreateBook(self.ctx, self.book)
+	self.NoError(err)
+	books, err := self.client.GetBooksByAuthorId(self.ctx, authorId, models.ListOptions{})
+
+	self.NoError(err)
+	self.Equal(page, books)
+}
+
+func (self *CachingDatabaseClientTests) TestUpdateAuthorInvalidatesBooks() {
+	author := models.Author{ID: self.book.Contributors[0].Author.ID, Name: "test_updated_name"}
+	updated := services.CloneBook(self.book)
+	updated.Contributors[0].Author = author
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+	self.mockDatabaseClient.On("GetAuthorById", mock.Anything, author.ID).Return(self.book.Contributors[0].Author, nil).Once()
+	self.mockDatabaseClient.On("UpdateAuthor", self.ctx, author).Return(author, nil).Once()
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(updated, nil).Once()
+	self.mockDatabaseClient.On("GetAuthorById", mock.Anything, author.ID).Return(author, nil).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	_, err = self.client.GetAuthorById(self.ctx, author.ID)
+	self.NoError(err)
+	_, err = self.client.UpdateAuthor(self.ctx, author)
+	self.NoError(err)
+	book, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	result, err := self.client.GetAuthorById(self.ctx, author.ID)
+
+	self.NoError(err)
+	self.Equal(updated, book)
+	self.Equal(author, result)
+}
+
+func (self *CachingDatabaseClientTests) TestUpdateAuthorKeepsBooksOfOtherAuthors() {
+	author := models.Author{ID: uuid.New(), Name: "test_other_name"}
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+	self.mockDatabaseClient.On("UpdateAuthor", self.ctx, author).Return(author, nil).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	_, err = self.client.UpdateAuthor(self.ctx, author)
+	self.NoError(err)
+	book, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(self.book, book)
+}
+
+func (self *CachingDatabaseClientTests) TestRestoreAuthorInvalidatesBooks() {
+	author := models.Author{ID: uuid.New(), Name: "test_restored_name"}
+	restored := services.CloneBook(self.book)
+	restored.Contributors = append(restored.Contributors, models.Contributor{Author: author, Role: "author"})
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+	self.mockDatabaseClient.On("RestoreAuthor", self.ctx, author.ID).Return(author, nil).Once()
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(restored, nil).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	_, err = self.client.RestoreAuthor(self.ctx, author.ID)
+	self.NoError(err)
+	book, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(restored, book)
+}
+
+func (self *CachingDatabaseClientTests) TestGetBookByIdCachesBookIfContextIsDone() {
+	ctx, cancel := context.WithCancel(self.ctx)
+	loaded := make(chan struct{})
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).
+		Run(func(args mock.Arguments) {
+			cancel()
+			<-loaded
+			self.NoError(args.Get(0).(context.Context).Err())
+		}).
+		Return(self.book, nil).Once()
+
+	_, err := self.client.GetBookById(ctx, self.book.ID)
+	self.ErrorIs(err, context.Canceled)
+	close(loaded)
+	book, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(self.book, book)
+}
+
+func (self *CachingDatabaseClientTests) TestRestoreBookInvalidatesBook() {
+	restored := self.book
+	restored.Version++
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Once()
+	self.mockDatabaseClient.On("RestoreBook", self.ctx, self.book.ID).Return(restored, nil).Once()
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(restored, nil).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	_, err = self.client.RestoreBook(self.ctx, self.book.ID)
+	self.NoError(err)
+	book, err := self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(restored, book)
+}
+
+func (self *CachingDatabaseClientTests) TestWithTxInvalidatesAll() {
+	self.mockDatabaseClient.On("GetBookById", mock.Anything, self.book.ID).Return(self.book, nil).Twice()
+	self.mockDatabaseClient.On("WithTx", self.ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			self.NoError(args.Get(1).(func(tx services.DatabaseClient) error)(self.mockDatabaseClient))
+		}).
+		Return(nil).Once()
+
+	_, err := self.client.GetBookById(self.ctx, self.book.ID)
+	self.NoError(err)
+	err = self.client.WithTx(self.ctx, func(tx services.DatabaseClient) error {
+		self.Same(self.mockDatabaseClient, tx)
+		return nil
+	})
+	self.NoError(err)
+	_, err = self.client.GetBookById(self.ctx, self.book.ID)
+
+	self.NoError(err)
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"time"
+
+	"github.com/egormizerov/books/pkg/wrappers"
+)
+
+// The tests are in the services_test package, as the mocks of the DatabaseClient import this package.
+
+var CloneBook = cloneBook
+
+func (self *Service) UUID() wrappers.UUIDWrapper {
+	return self.uuid
+}
+
+func (self *TrashPurger) SetNow(now func() time.Time) {
+	self.now = now
+}
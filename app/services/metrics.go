@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/pkg/metrics"
+)
+
+// Results of the database client calls.
+const (
+	resultSuccess  = "success"
+	resultNotFound = "not_found"
+	resultError    = "error"
+)
+
+// MetricsDatabaseClient counts the calls of the database client and observes their duration by method.
+type MetricsDatabaseClient struct {
+	databaseClient DatabaseClient
+	calls          *metrics.CounterVec
+	duration       *metrics.HistogramVec
+}
+
+func NewMetricsDatabaseClient(databaseClient DatabaseClient, registry *metrics.Registry) *MetricsDatabaseClient {
+	client := &MetricsDatabaseClient{
+		databaseClient: databaseClient,
+		calls: metrics.NewCounterVec(
+			"books_database_calls_total",
+			"Total number of the database client calls.",
+			"method", "result",
+		),
+		duration: metrics.NewHistogramVec(
+			"books_database_call_duration_seconds",
+			"Duration of the database client calls.",
+			metrics.DefaultBuckets,
+			"method",
+		),
+	}
+	registry.Register(client.calls, client.duration)
+
+	return client
+}
+
+// observe records the result and the duration of the call of the method.
+func observe[T any](self *MetricsDatabaseClient, method string, call func() (T, error)) (T, error) {
+	start := time.Now()
+	value, err := call()
+
+	result := resultSuccess
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		result = resultNotFound
+	case err != nil:
+		result = resultError
+	}
+	self.calls.Inc(method, result)
+	self.duration.Observe(time.Since(start).Seconds(), method)
+
+	return value, err
+}
+
+// observeError records the call of the method returning only the error.
+func observeError(self *MetricsDatabaseClient, method string, call func() error) error {
+	_, err := observe(self, method, func() (struct{}, error) {
+		return struct{}{}, call()
+	})
+	return err
+}
+
+func (self *MetricsDatabaseClient) CreateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	return observe(self, "CreateAuthor", func() (models.Author, error) {
+		return self.databaseClient.CreateAuthor(ctx, author)
+	})
+}
+
+func (self *MetricsDatabaseClient) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	return observe(self, "CreateBook", func() (models.Book, error) {
+		return self.databaseClient.CreateBook(ctx, book)
+	})
+}
+
+func (self *MetricsDatabaseClient) CreateBooks(ctx context.Context, books []models.Book) ([]models.Book, error) {
+	return observe(self, "CreateBooks", func() ([]models.Book, error) {
+		return self.databaseClient.CreateBooks(ctx, books)
+	})
+}
+
+func (self *MetricsDatabaseClient) GetBookById(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	return observe(self, "GetBookById", func() (models.Book, error) {
+		return self.databaseClient.GetBookById(ctx, bookId)
+	})
+}
+
+func (self *MetricsDatabaseClient) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	return observe(self, "GetBookByISBN", func() (models.Book, error) {
+		return self.databaseClient.GetBookByISBN(ctx, isbn)
+	})
+}
+
+func (self *MetricsDatabaseClient) GetAuthorById(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	return observe(self, "GetAuthorById", func() (models.Author, error) {
+		return self.databaseClient.GetAuthorById(ctx, authorId)
+	})
+}
+
+func (self *MetricsDatabaseClient) GetBooksByAuthorId(
+	ctx context.Context,
+	authorId uuid.UUID,
+	options models.ListOptions,
+) (models.Page[models.Book], error) {
+	return observe(self, "GetBooksByAuthorId", func() (models.Page[models.Book], error) {
+		return self.databaseClient.GetBooksByAuthorId(ctx, authorId, options)
+	})
+}
+
+func (self *MetricsDatabaseClient) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	return observe(self, "GetAuthors", func() (models.Page[models.Author], error) {
+		return self.databaseClient.GetAuthors(ctx, options)
+	})
+}
+
+func (self *MetricsDatabaseClient) UpdateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	return observe(self, "UpdateAuthor", func() (models.Author, error) {
+		return self.databaseClient.UpdateAuthor(ctx, author)
+	})
+}
+
+func (self *MetricsDatabaseClient) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	return observe(self, "DeleteAuthor", func() (int64, error) {
+		return self.databaseClient.DeleteAuthor(ctx, authorId, version)
+	})
+}
+
+func (self *MetricsDatabaseClient) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	return observe(self, "GetBooks", func() (models.Page[models.Book], error) {
+		return self.databaseClient.GetBooks(ctx, options)
+	})
+}
+
+func (self *MetricsDatabaseClient) UpdateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	return observe(self, "UpdateBook", func() (models.Book, error) {
+		return self.databaseClient.UpdateBook(ctx, book)
+	})
+}
+
+func (self *MetricsDatabaseClient) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	return observeError(self, "DeleteBook", func() error {
+		return self.databaseClient.DeleteBook(ctx, bookId, version)
+	})
+}
+
+func (self *MetricsDatabaseClient) Search(
+	ctx context.Context,
+	query models.SearchQuery,
+	options models.ListOptions,
+) (models.Page[models.SearchResult], error) {
+	return observe(self, "Search", func() (models.Page[models.SearchResult], error) {
+		return self.databaseClient.Search(ctx, query, options)
+	})
+}
+
+func (self *MetricsDatabaseClient) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	return observe(self, "RestoreBook", func() (models.Book, error) {
+		return self.databaseClient.RestoreBook(ctx, bookId)
+	})
+}
+
+func (self *MetricsDatabaseClient) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	return observe(self, "RestoreAuthor", func() (models.Author, error) {
+		return self.databaseClient.RestoreAuthor(ctx, authorId)
+	})
+}
+
+func (self *MetricsDatabaseClient) GetTrash(
+	ctx context.Context,
+	query models.TrashQuery,
+	options models.ListOptions,
+) (models.Page[models.TrashItem], error) {
+	return observe(self, "GetTrash", func() (models.Page[models.TrashItem], error) {
+		return self.databaseClient.GetTrash(ctx, query, options)
+	})
+}
+
+func (self *MetricsDatabaseClient) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return observe(self, "PurgeTrash", func() (int64, error) {
+		return self.databaseClient.PurgeTrash(ctx, deletedBefore)
+	})
+}
+
+// WithTx observes the whole transaction, the calls of the unit of work are observed by their methods.
+func (self *MetricsDatabaseClient) WithTx(ctx context.Context, unitOfWork func(tx DatabaseClient) error) error {
+	return observeError(self, "WithTx", func() error {
+		return self.databaseClient.WithTx(ctx, func(tx DatabaseClient) error {
+			return unitOfWork(&MetricsDatabaseClient{databaseClient: tx, calls: self.calls, duration: self.duration})
+		})
+	})
+}
@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/app/services"
+	"github.com/egormizerov/books/app/services/mocks"
+	"github.com/egormizerov/books/pkg/metrics"
+)
+
+type MetricsDatabaseClientTests struct {
+	suite.Suite
+	client             *services.MetricsDatabaseClient
+	mockDatabaseClient *mocks.DatabaseClient
+	registry           *metrics.Registry
+	ctx                context.Context
+}
+
+func TestMetricsDatabaseClient(t *testing.T) {
+	suite.Run(t, new(MetricsDatabaseClientTests))
+}
+
+func (self *MetricsDatabaseClientTests) SetupTest() {
+	self.mockDatabaseClient = mocks.NewDatabaseClient(self.T())
+	self.registry = metrics.NewRegistry()
+	self.client = services.NewMetricsDatabaseClient(self.mockDatabaseClient, self.registry)
+	self.ctx = context.Background()
+}
+
+func (self *MetricsDatabaseClientTests) metrics() string {
+	var buffer bytes.Buffer
+	self.Require().NoError(self.registry.Write(&buffer))
+	return buffer.String()
+}
+
+func (self *MetricsDatabaseClientTests) TestGetBookById() {
+	book := models.Book{ID: uuid.New()}
+	self.mockDatabaseClient.On("GetBookById", self.ctx, book.ID).Return(book, nil).Once()
+	self.mockDatabaseClient.On("GetBookById", self.ctx, book.ID).
+		Return(models.Book{}, fmt.Errorf("failed to get book: %w", models.ErrNotFound)).Once()
+
+	result, err := self.client.GetBookById(self.ctx, book.ID)
+	self.NoError(err)
+	self.Equal(book, result)
+	_, err = self.client.GetBookById(self.ctx, book.ID)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Contains(self.metrics(), `books_database_calls_total{method="GetBookById",result="not_found"} 1`)
+	self.Contains(self.metrics(), `books_database_calls_total{method="GetBookById",result="success"} 1`)
+	self.Contains(self.metrics(), `books_database_call_duration_seconds_count{method="GetBookById"} 2`)
+}
+
+func (self *MetricsDatabaseClientTests) TestDeleteBookErrorIfDatabaseClientFailed() {
+	bookId := uuid.New()
+	testError := errors.New("test_error")
+	self.mockDatabaseClient.On("DeleteBook", self.ctx, bookId, models.AnyVersion).Return(testError)
+
+	err := self.client.DeleteBook(self.ctx, bookId, models.AnyVersion)
+
+	self.ErrorIs(err, testError)
+	self.Contains(self.metrics(), `books_database_calls_total{method="DeleteBook",result="error"} 1`)
+}
+
+func (self *MetricsDatabaseClientTests) TestWithTxObservesUnitOfWorkCalls() {
+	bookId := uuid.New()
+	mockTx := mocks.NewDatabaseClient(self.T())
+	mockTx.On("DeleteBook", self.ctx, bookId, models.AnyVersion).Return(nil)
+	self.mockDatabaseClient.On("WithTx", self.ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			self.NoError(args.Get(1).(func(tx services.DatabaseClient) error)(mockTx))
+		}).
+		Return(nil)
+
+	err := self.client.WithTx(self.ctx, func(tx services.DatabaseClient) error {
+		return tx.DeleteBook(self.ctx, bookId, models.AnyVersion)
+	})
+
+	self.NoError(err)
+	self.Contains(self.metrics(), `books_database_calls_total{method="DeleteBook",result="success"} 1`)
+	self.Contains(self.metrics(), `books_database_calls_total{method="WithTx",result="success"} 1`)
+}
@@ -8,6 +8,10 @@ import (
 	models "github.com/egormizerov/books/app/models"
 	mock "github.com/stretchr/testify/mock"
 
+	services "github.com/egormizerov/books/app/services"
+
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -17,26 +21,98 @@ type DatabaseClient struct {
 }
 
 // CreateAuthor provides a mock function with given fields: ctx, author
-func (_m *DatabaseClient) CreateAuthor(ctx context.Context, author models.Author) error {
+func (_m *DatabaseClient) CreateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
 	ret := _m.Called(ctx, author)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.Author) error); ok {
+	var r0 models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, models.Author) models.Author); ok {
 		r0 = rf(ctx, author)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(models.Author)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.Author) error); ok {
+		r1 = rf(ctx, author)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // CreateBook provides a mock function with given fields: ctx, book
-func (_m *DatabaseClient) CreateBook(ctx context.Context, book models.Book) error {
+func (_m *DatabaseClient) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
 	ret := _m.Called(ctx, book)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.Book) error); ok {
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, models.Book) models.Book); ok {
 		r0 = rf(ctx, book)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.Book) error); ok {
+		r1 = rf(ctx, book)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateBooks provides a mock function with given fields: ctx, books
+func (_m *DatabaseClient) CreateBooks(ctx context.Context, books []models.Book) ([]models.Book, error) {
+	ret := _m.Called(ctx, books)
+
+	var r0 []models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, []models.Book) []models.Book); ok {
+		r0 = rf(ctx, books)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Book)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []models.Book) error); ok {
+		r1 = rf(ctx, books)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAuthor provides a mock function with given fields: ctx, authorId, version
+func (_m *DatabaseClient) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	ret := _m.Called(ctx, authorId, version)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) int64); ok {
+		r0 = rf(ctx, authorId, version)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, int64) error); ok {
+		r1 = rf(ctx, authorId, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteBook provides a mock function with given fields: ctx, bookId, version
+func (_m *DatabaseClient) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	ret := _m.Called(ctx, bookId, version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) error); ok {
+		r0 = rf(ctx, bookId, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -65,6 +141,48 @@ func (_m *DatabaseClient) GetAuthorById(ctx context.Context, authorId uuid.UUID)
 	return r0, r1
 }
 
+// GetAuthors provides a mock function with given fields: ctx, options
+func (_m *DatabaseClient) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	ret := _m.Called(ctx, options)
+
+	var r0 models.Page[models.Author]
+	if rf, ok := ret.Get(0).(func(context.Context, models.ListOptions) models.Page[models.Author]); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.Author])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.ListOptions) error); ok {
+		r1 = rf(ctx, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBookByISBN provides a mock function with given fields: ctx, isbn
+func (_m *DatabaseClient) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	ret := _m.Called(ctx, isbn)
+
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Book); ok {
+		r0 = rf(ctx, isbn)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, isbn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBookById provides a mock function with given fields: ctx, bookId
 func (_m *DatabaseClient) GetBookById(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
 	ret := _m.Called(ctx, bookId)
@@ -86,17 +204,99 @@ func (_m *DatabaseClient) GetBookById(ctx context.Context, bookId uuid.UUID) (mo
 	return r0, r1
 }
 
-// GetBooksByAuthorId provides a mock function with given fields: ctx, authorId
-func (_m *DatabaseClient) GetBooksByAuthorId(ctx context.Context, authorId uuid.UUID) ([]models.Book, error) {
+// GetBooks provides a mock function with given fields: ctx, options
+func (_m *DatabaseClient) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	ret := _m.Called(ctx, options)
+
+	var r0 models.Page[models.Book]
+	if rf, ok := ret.Get(0).(func(context.Context, models.ListOptions) models.Page[models.Book]); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.Book])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.ListOptions) error); ok {
+		r1 = rf(ctx, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBooksByAuthorId provides a mock function with given fields: ctx, authorId, options
+func (_m *DatabaseClient) GetBooksByAuthorId(ctx context.Context, authorId uuid.UUID, options models.ListOptions) (models.Page[models.Book], error) {
+	ret := _m.Called(ctx, authorId, options)
+
+	var r0 models.Page[models.Book]
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, models.ListOptions) models.Page[models.Book]); ok {
+		r0 = rf(ctx, authorId, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.Book])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, models.ListOptions) error); ok {
+		r1 = rf(ctx, authorId, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrash provides a mock function with given fields: ctx, query, options
+func (_m *DatabaseClient) GetTrash(ctx context.Context, query models.TrashQuery, options models.ListOptions) (models.Page[models.TrashItem], error) {
+	ret := _m.Called(ctx, query, options)
+
+	var r0 models.Page[models.TrashItem]
+	if rf, ok := ret.Get(0).(func(context.Context, models.TrashQuery, models.ListOptions) models.Page[models.TrashItem]); ok {
+		r0 = rf(ctx, query, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.TrashItem])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.TrashQuery, models.ListOptions) error); ok {
+		r1 = rf(ctx, query, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeTrash provides a mock function with given fields: ctx, deletedBefore
+func (_m *DatabaseClient) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ret := _m.Called(ctx, deletedBefore)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, deletedBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, deletedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreAuthor provides a mock function with given fields: ctx, authorId
+func (_m *DatabaseClient) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
 	ret := _m.Called(ctx, authorId)
 
-	var r0 []models.Book
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.Book); ok {
+	var r0 models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) models.Author); ok {
 		r0 = rf(ctx, authorId)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Book)
-		}
+		r0 = ret.Get(0).(models.Author)
 	}
 
 	var r1 error
@@ -109,6 +309,104 @@ func (_m *DatabaseClient) GetBooksByAuthorId(ctx context.Context, authorId uuid.
 	return r0, r1
 }
 
+// RestoreBook provides a mock function with given fields: ctx, bookId
+func (_m *DatabaseClient) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	ret := _m.Called(ctx, bookId)
+
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) models.Book); ok {
+		r0 = rf(ctx, bookId)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, bookId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, query, options
+func (_m *DatabaseClient) Search(ctx context.Context, query models.SearchQuery, options models.ListOptions) (models.Page[models.SearchResult], error) {
+	ret := _m.Called(ctx, query, options)
+
+	var r0 models.Page[models.SearchResult]
+	if rf, ok := ret.Get(0).(func(context.Context, models.SearchQuery, models.ListOptions) models.Page[models.SearchResult]); ok {
+		r0 = rf(ctx, query, options)
+	} else {
+		r0 = ret.Get(0).(models.Page[models.SearchResult])
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.SearchQuery, models.ListOptions) error); ok {
+		r1 = rf(ctx, query, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAuthor provides a mock function with given fields: ctx, author
+func (_m *DatabaseClient) UpdateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	ret := _m.Called(ctx, author)
+
+	var r0 models.Author
+	if rf, ok := ret.Get(0).(func(context.Context, models.Author) models.Author); ok {
+		r0 = rf(ctx, author)
+	} else {
+		r0 = ret.Get(0).(models.Author)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.Author) error); ok {
+		r1 = rf(ctx, author)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateBook provides a mock function with given fields: ctx, book
+func (_m *DatabaseClient) UpdateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	ret := _m.Called(ctx, book)
+
+	var r0 models.Book
+	if rf, ok := ret.Get(0).(func(context.Context, models.Book) models.Book); ok {
+		r0 = rf(ctx, book)
+	} else {
+		r0 = ret.Get(0).(models.Book)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.Book) error); ok {
+		r1 = rf(ctx, book)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WithTx provides a mock function with given fields: ctx, unitOfWork
+func (_m *DatabaseClient) WithTx(ctx context.Context, unitOfWork func(services.DatabaseClient) error) error {
+	ret := _m.Called(ctx, unitOfWork)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(services.DatabaseClient) error) error); ok {
+		r0 = rf(ctx, unitOfWork)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewDatabaseClient interface {
 	mock.TestingT
 	Cleanup(func())
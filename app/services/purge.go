@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PurgeOptions configure the purge of the trash.
+type PurgeOptions struct {
+	// Retention is the time the deleted books and authors are kept in the trash.
+	Retention time.Duration
+	// Interval is the time between the purges.
+	Interval time.Duration
+}
+
+// TrashPurger periodically deletes the books and the authors kept in the trash longer than the retention.
+// It runs as the component of the process lifecycle: Run purges the trash until Stop is called.
+type TrashPurger struct {
+	databaseClient DatabaseClient
+	logger         logrus.FieldLogger
+	options        PurgeOptions
+	now            func() time.Time
+	// ctx is canceled by Stop, it interrupts the purge in progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// done is closed when Run returns.
+	done chan struct{}
+}
+
+func NewTrashPurger(databaseClient DatabaseClient, logger logrus.FieldLogger, options PurgeOptions) *TrashPurger {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TrashPurger{
+		databaseClient: databaseClient,
+		logger:         logger,
+		options:        options,
+		now:            time.Now,
+		ctx:            ctx,
+		cancel:         cancel,
+		done:           make(chan struct{}),
+	}
+}
+
+// Run purges the trash on start and then every interval until Stop is called. The failed purges
+// are logged and retried on the next interval, so Run only returns after Stop.
+func (self *TrashPurger) Run() error {
+	defer close(self.done)
+	ticker := time.NewTicker(self.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		self.Purge(self.ctx)
+		select {
+		case <-self.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop interrupts the purge in progress and waits for Run to return.
+func (self *TrashPurger) Stop(ctx context.Context) error {
+	self.cancel()
+	select {
+	case <-self.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Purge deletes the books and the authors deleted before the retention and returns their number.
+func (self *TrashPurger) Purge(ctx context.Context) int64 {
+	deletedBefore := self.now().Add(-self.options.Retention)
+	purged, err := self.databaseClient.PurgeTrash(ctx, deletedBefore)
+	if err != nil {
+		if ctx.Err() == nil {
+			self.logger.
+				WithField("deleted_before", deletedBefore).
+				WithError(err).
+				Error("failed to purge trash")
+		}
+		return 0
+	}
+	if purged > 0 {
+		self.logger.
+			WithField("purged", purged).
+			WithField("deleted_before", deletedBefore).
+			Info("trash purged")
+	}
+
+	return purged
+}
@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/app/services"
+	"github.com/egormizerov/books/app/services/mocks"
+)
+
+type TrashPurgerTests struct {
+	suite.Suite
+	purger             *services.TrashPurger
+	mockDatabaseClient *mocks.DatabaseClient
+	loggerHook         *logrustest.Hook
+
+	now           time.Time
+	deletedBefore time.Time
+	testError     error
+}
+
+func TestTrashPurger(t *testing.T) {
+	suite.Run(t, new(TrashPurgerTests))
+}
+
+func (self *TrashPurgerTests) SetupTest() {
+	self.mockDatabaseClient = mocks.NewDatabaseClient(self.T())
+	logger, loggerHook := logrustest.NewNullLogger()
+	self.loggerHook = loggerHook
+	self.purger = services.NewTrashPurger(
+		self.mockDatabaseClient,
+		logger,
+		services.PurgeOptions{Retention: 24 * time.Hour, Interval: time.Hour},
+	)
+	self.now = time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+	self.purger.SetNow(func() time.Time { return self.now })
+	self.deletedBefore = time.Date(2022, 10, 2, 12, 0, 0, 0, time.UTC)
+	self.testError = errors.New("test error")
+}
+
+func (self *TrashPurgerTests) TestPurge() {
+	self.mockDatabaseClient.On("PurgeTrash", context.Background(), self.deletedBefore).Return(int64(3), nil)
+
+	purged := self.purger.Purge(context.Background())
+
+	self.Equal(int64(3), purged)
+	self.Equal(logrus.InfoLevel, self.loggerHook.LastEntry().Level)
+	self.Equal("trash purged", self.loggerHook.LastEntry().Message)
+	self.Equal(
+		logrus.Fields{"purged": int64(3), "deleted_before": self.deletedBefore},
+		self.loggerHook.LastEntry().Data,
+	)
+}
+
+func (self *TrashPurgerTests) TestPurgeLogsNothingIfTrashIsEmpty() {
+	self.mockDatabaseClient.On("PurgeTrash", context.Background(), self.deletedBefore).Return(int64(0), nil)
+
+	purged := self.purger.Purge(context.Background())
+
+	self.Equal(int64(0), purged)
+	self.Empty(self.loggerHook.AllEntries())
+}
+
+func (self *TrashPurgerTests) TestPurgeLogsError() {
+	self.mockDatabaseClient.On("PurgeTrash", context.Background(), self.deletedBefore).Return(int64(0), self.testError)
+
+	purged := self.purger.Purge(context.Background())
+
+	self.Equal(int64(0), purged)
+	self.Equal(logrus.ErrorLevel, self.loggerHook.LastEntry().Level)
+	self.Equal("failed to purge trash", self.loggerHook.LastEntry().Message)
+	self.Equal(
+		logrus.Fields{"deleted_before": self.deletedBefore, logrus.ErrorKey: self.testError},
+		self.loggerHook.LastEntry().Data,
+	)
+}
+
+func (self *TrashPurgerTests) TestRunPurgesUntilStop() {
+	purged := make(chan struct{})
+	self.mockDatabaseClient.
+		On("PurgeTrash", mock.Anything, self.deletedBefore).
+		Run(func(mock.Arguments) { close(purged) }).
+		Return(int64(0), nil).
+		Once()
+	errs := make(chan error)
+	go func() {
+		errs <- self.purger.Run()
+	}()
+
+	<-purged
+	self.NoError(self.purger.Stop(context.Background()))
+
+	self.NoError(<-errs)
+}
+
+func (self *TrashPurgerTests) TestStopErrorIfContextIsDone() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := self.purger.Stop(ctx)
+
+	self.ErrorIs(err, context.Canceled)
+}
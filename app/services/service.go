@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -11,13 +13,36 @@ import (
 	"github.com/egormizerov/books/pkg/wrappers"
 )
 
+// DatabaseClient stores the books and the authors. The writes of the existing entity are conditional
+// on its version, the entity's Version or the version argument, unless it is models.AnyVersion.
+// They fail with models.VersionMismatchError if the entity has another version.
+// The deletes move the entities to the trash, the reads and the writes ignore them until they are restored.
+// The ISBNs and the names are unique among the entities not in the trash, so the restores fail with
+// models.ErrConflict if they were reused.
+//
 //go:generate mockery --name=DatabaseClient
 type DatabaseClient interface {
-	CreateAuthor(ctx context.Context, author models.Author) error
-	CreateBook(ctx context.Context, book models.Book) error
+	CreateAuthor(ctx context.Context, author models.Author) (models.Author, error)
+	CreateBook(ctx context.Context, book models.Book) (models.Book, error)
+	CreateBooks(ctx context.Context, books []models.Book) ([]models.Book, error)
 	GetBookById(ctx context.Context, bookId uuid.UUID) (models.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string) (models.Book, error)
 	GetAuthorById(ctx context.Context, authorId uuid.UUID) (models.Author, error)
-	GetBooksByAuthorId(ctx context.Context, authorId uuid.UUID) ([]models.Book, error)
+	GetBooksByAuthorId(ctx context.Context, authorId uuid.UUID, options models.ListOptions) (models.Page[models.Book], error)
+	GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error)
+	UpdateAuthor(ctx context.Context, author models.Author) (models.Author, error)
+	DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error)
+	GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error)
+	UpdateBook(ctx context.Context, book models.Book) (models.Book, error)
+	DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error
+	Search(ctx context.Context, query models.SearchQuery, options models.ListOptions) (models.Page[models.SearchResult], error)
+	RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error)
+	RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error)
+	GetTrash(ctx context.Context, query models.TrashQuery, options models.ListOptions) (models.Page[models.TrashItem], error)
+	// PurgeTrash deletes the entities moved to the trash before the time and returns their number.
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// WithTx runs the unit of work atomically, the unit of work must use the passed client.
+	WithTx(ctx context.Context, unitOfWork func(tx DatabaseClient) error) error
 }
 
 type Service struct {
@@ -32,48 +57,134 @@ func NewService(databaseClient DatabaseClient, uuid wrappers.UUIDWrapper) *Servi
 	}
 }
 
-func (self *Service) CreateBook(ctx context.Context, bookTitle string, authorId uuid.UUID) error {
-	book, err := models.NewBook(bookTitle, self.uuid.New(), models.Author{ID: authorId})
+func (self *Service) CreateBook(
+	ctx context.Context,
+	bookTitle string,
+	contributors []models.Contributor,
+	details models.BookDetails,
+) (models.Book, error) {
+	book, err := models.NewBook(bookTitle, self.uuid.New(), contributors, details)
 	if err != nil {
 		logcontext.FromContext(ctx).
-			WithField("author_id", authorId.String()).
+			WithField("book_title", bookTitle).
 			WithError(err).
 			Error("failed to init book")
-		return fmt.Errorf("failed to init book: %s", err)
+		return models.Book{}, fmt.Errorf("failed to init book: %w", err)
 	}
 
-	err = self.DatabaseClient.CreateBook(ctx, book)
+	book, err = self.DatabaseClient.CreateBook(ctx, book)
 	if err != nil {
 		logcontext.FromContext(ctx).
-			WithField("author_id", authorId.String()).
 			WithField("book_title", bookTitle).
 			WithError(err).
 			Error("failed to create book")
-		return fmt.Errorf("failed to create book: %s", err)
+		return models.Book{}, fmt.Errorf("failed to create book: %w", err)
 	}
 
-	return nil
+	return book, nil
+}
+
+// CreateBooks creates the batch of books and returns their results ordered as the inputs. In the atomic
+// mode either all the books are created or none of them, the failed books are reported with their errors
+// and the others with models.ErrBatchAborted. Otherwise the valid books are created.
+func (self *Service) CreateBooks(ctx context.Context, inputs []models.BookInput, atomic bool) ([]models.BookResult, error) {
+	results := make([]models.BookResult, len(inputs))
+	books := make([]models.Book, 0, len(inputs))
+	indexes := make([]int, 0, len(inputs))
+	for index, input := range inputs {
+		book, err := models.NewBook(input.Title, self.uuid.New(), input.Contributors, input.Details)
+		if err != nil {
+			results[index].Err = err
+			continue
+		}
+		books = append(books, book)
+		indexes = append(indexes, index)
+	}
+	if len(books) == 0 {
+		return results, nil
+	}
+	if atomic && len(books) < len(inputs) {
+		for _, index := range indexes {
+			results[index].Err = models.ErrBatchAborted
+		}
+		return results, nil
+	}
+
+	created, err := self.DatabaseClient.CreateBooks(ctx, books)
+	switch {
+	case err == nil:
+		for position, book := range created {
+			results[indexes[position]].Book = book
+		}
+	case errors.Is(err, models.ErrConflict) || errors.Is(err, models.ErrValidation):
+		// The bulk insert does not tell which book failed, so the books are created one by one.
+		// The atomic batch creates them in the single transaction, so it still creates all of them or none.
+		if !atomic {
+			for position, book := range books {
+				book, err = self.DatabaseClient.CreateBook(ctx, book)
+				results[indexes[position]] = models.BookResult{Book: book, Err: err}
+			}
+			break
+		}
+		failed := -1
+		err = self.DatabaseClient.WithTx(ctx, func(tx DatabaseClient) error {
+			for position, book := range books {
+				book, err := tx.CreateBook(ctx, book)
+				if err != nil {
+					failed = position
+					return err
+				}
+				results[indexes[position]].Book = book
+			}
+			return nil
+		})
+		switch {
+		case err == nil:
+		case failed >= 0 && (errors.Is(err, models.ErrConflict) || errors.Is(err, models.ErrValidation)):
+			// The failed book is reported with its error and the others are aborted, as none of them was created.
+			for position, index := range indexes {
+				results[index] = models.BookResult{Err: models.ErrBatchAborted}
+				if position == failed {
+					results[index].Err = err
+				}
+			}
+		default:
+			logcontext.FromContext(ctx).
+				WithField("books", len(books)).
+				WithError(err).
+				Error("failed to create books")
+			return nil, fmt.Errorf("failed to create books: %w", err)
+		}
+	default:
+		logcontext.FromContext(ctx).
+			WithField("books", len(books)).
+			WithError(err).
+			Error("failed to create books")
+		return nil, fmt.Errorf("failed to create books: %w", err)
+	}
+
+	return results, nil
 }
 
-func (self *Service) CreateAuthor(ctx context.Context, authorName string) error {
+func (self *Service) CreateAuthor(ctx context.Context, authorName string) (models.Author, error) {
 	author, err := models.NewAuthor(authorName, self.uuid.New())
 	if err != nil {
 		logcontext.FromContext(ctx).
 			WithError(err).
 			Error("failed to init author")
-		return fmt.Errorf("failed to init author: %s", err)
+		return models.Author{}, fmt.Errorf("failed to init author: %w", err)
 	}
 
-	err = self.DatabaseClient.CreateAuthor(ctx, author)
+	author, err = self.DatabaseClient.CreateAuthor(ctx, author)
 	if err != nil {
 		logcontext.FromContext(ctx).
 			WithField("author_name", authorName).
 			WithError(err).
 			Error("failed to create author")
-		return fmt.Errorf("failed to create author: %s", err)
+		return models.Author{}, fmt.Errorf("failed to create author: %w", err)
 	}
 
-	return nil
+	return author, nil
 }
 
 func (self *Service) GetBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
@@ -83,30 +194,250 @@ func (self *Service) GetBook(ctx context.Context, bookId uuid.UUID) (models.Book
 			WithField("book_id", bookId.String()).
 			WithError(err).
 			Error("failed to get book")
-		return models.Book{}, fmt.Errorf("failed to get book by id: %s", err)
+		return models.Book{}, fmt.Errorf("failed to get book by id: %w", err)
+	}
+
+	return book, nil
+}
+
+func (self *Service) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	normalizedISBN, err := models.NormalizeISBN(isbn)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("isbn", isbn).
+			WithError(err).
+			Error("failed to normalize isbn")
+		return models.Book{}, fmt.Errorf("failed to normalize isbn: %w", err)
 	}
-	bookAuthor, err := self.DatabaseClient.GetAuthorById(ctx, book.Author.ID)
+
+	book, err := self.DatabaseClient.GetBookByISBN(ctx, normalizedISBN)
 	if err != nil {
 		logcontext.FromContext(ctx).
-			WithField("book_id", bookId.String()).
+			WithField("isbn", normalizedISBN).
 			WithError(err).
-			Error("failed to get author")
-		return models.Book{}, fmt.Errorf("failed to get author by id: %s", err)
+			Error("failed to get book")
+		return models.Book{}, fmt.Errorf("failed to get book by isbn: %w", err)
 	}
-	book.Author = bookAuthor
 
 	return book, nil
 }
 
-func (self *Service) GetAuthorsBooks(ctx context.Context, authorId uuid.UUID) ([]models.Book, error) {
-	books, err := self.DatabaseClient.GetBooksByAuthorId(ctx, authorId)
+func (self *Service) GetAuthorsBooks(
+	ctx context.Context,
+	authorId uuid.UUID,
+	options models.ListOptions,
+) (models.Page[models.Book], error) {
+	books, err := self.DatabaseClient.GetBooksByAuthorId(ctx, authorId, options)
 	if err != nil {
 		logcontext.FromContext(ctx).
 			WithField("author_id", authorId.String()).
 			WithError(err).
 			Error("failed to get books by author id")
-		return nil, fmt.Errorf("failed to get books by author id: %s", err)
+		return models.Page[models.Book]{}, fmt.Errorf("failed to get books by author id: %w", err)
 	}
 
 	return books, nil
 }
+
+func (self *Service) GetAuthors(ctx context.Context, options models.ListOptions) (models.Page[models.Author], error) {
+	authors, err := self.DatabaseClient.GetAuthors(ctx, options)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithError(err).
+			Error("failed to get authors")
+		return models.Page[models.Author]{}, fmt.Errorf("failed to get authors: %w", err)
+	}
+
+	return authors, nil
+}
+
+func (self *Service) GetAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	author, err := self.DatabaseClient.GetAuthorById(ctx, authorId)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("author_id", authorId.String()).
+			WithError(err).
+			Error("failed to get author")
+		return models.Author{}, fmt.Errorf("failed to get author by id: %w", err)
+	}
+
+	return author, nil
+}
+
+// UpdateAuthor updates the author unless its version differs from the expected one, models.AnyVersion matches any version.
+func (self *Service) UpdateAuthor(ctx context.Context, authorId uuid.UUID, authorName string, version int64) (models.Author, error) {
+	author, err := models.NewAuthor(authorName, authorId)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("author_id", authorId.String()).
+			WithError(err).
+			Error("failed to init author")
+		return models.Author{}, fmt.Errorf("failed to init author: %w", err)
+	}
+
+	author.Version = version
+	author, err = self.DatabaseClient.UpdateAuthor(ctx, author)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("author_id", authorId.String()).
+			WithField("author_name", authorName).
+			WithError(err).
+			Error("failed to update author")
+		return models.Author{}, fmt.Errorf("failed to update author: %w", err)
+	}
+
+	return author, nil
+}
+
+// DeleteAuthor deletes the author of the expected version and returns the number of books left without an author.
+func (self *Service) DeleteAuthor(ctx context.Context, authorId uuid.UUID, version int64) (int64, error) {
+	orphanedBooks, err := self.DatabaseClient.DeleteAuthor(ctx, authorId, version)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("author_id", authorId.String()).
+			WithError(err).
+			Error("failed to delete author")
+		return 0, fmt.Errorf("failed to delete author: %w", err)
+	}
+
+	return orphanedBooks, nil
+}
+
+func (self *Service) GetBooks(ctx context.Context, options models.ListOptions) (models.Page[models.Book], error) {
+	books, err := self.DatabaseClient.GetBooks(ctx, options)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithError(err).
+			Error("failed to get books")
+		return models.Page[models.Book]{}, fmt.Errorf("failed to get books: %w", err)
+	}
+
+	return books, nil
+}
+
+// UpdateBook updates the book unless its version differs from the expected one, models.AnyVersion matches any version.
+func (self *Service) UpdateBook(
+	ctx context.Context,
+	bookId uuid.UUID,
+	bookTitle string,
+	contributors []models.Contributor,
+	details models.BookDetails,
+	version int64,
+) (models.Book, error) {
+	book, err := models.NewBook(bookTitle, bookId, contributors, details)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("book_id", bookId.String()).
+			WithError(err).
+			Error("failed to init book")
+		return models.Book{}, fmt.Errorf("failed to init book: %w", err)
+	}
+
+	book.Version = version
+	book, err = self.DatabaseClient.UpdateBook(ctx, book)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("book_id", bookId.String()).
+			WithField("book_title", bookTitle).
+			WithError(err).
+			Error("failed to update book")
+		return models.Book{}, fmt.Errorf("failed to update book: %w", err)
+	}
+
+	return book, nil
+}
+
+// DeleteBook deletes the book of the expected version.
+func (self *Service) DeleteBook(ctx context.Context, bookId uuid.UUID, version int64) error {
+	err := self.DatabaseClient.DeleteBook(ctx, bookId, version)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("book_id", bookId.String()).
+			WithError(err).
+			Error("failed to delete book")
+		return fmt.Errorf("failed to delete book: %w", err)
+	}
+
+	return nil
+}
+
+func (self *Service) Search(
+	ctx context.Context,
+	text string,
+	resultType string,
+	options models.ListOptions,
+) (models.Page[models.SearchResult], error) {
+	query, err := models.NewSearchQuery(text, resultType)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithError(err).
+			Error("failed to init search query")
+		return models.Page[models.SearchResult]{}, fmt.Errorf("failed to init search query: %w", err)
+	}
+
+	results, err := self.DatabaseClient.Search(ctx, query, options)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("search_query", text).
+			WithField("search_type", resultType).
+			WithError(err).
+			Error("failed to search")
+		return models.Page[models.SearchResult]{}, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return results, nil
+}
+
+// RestoreBook restores the book from the trash, it fails with models.NotFoundError unless the book is in the trash.
+func (self *Service) RestoreBook(ctx context.Context, bookId uuid.UUID) (models.Book, error) {
+	book, err := self.DatabaseClient.RestoreBook(ctx, bookId)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("book_id", bookId.String()).
+			WithError(err).
+			Error("failed to restore book")
+		return models.Book{}, fmt.Errorf("failed to restore book: %w", err)
+	}
+
+	return book, nil
+}
+
+// RestoreAuthor restores the author from the trash, it fails with models.NotFoundError unless the author is in the trash.
+func (self *Service) RestoreAuthor(ctx context.Context, authorId uuid.UUID) (models.Author, error) {
+	author, err := self.DatabaseClient.RestoreAuthor(ctx, authorId)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("author_id", authorId.String()).
+			WithError(err).
+			Error("failed to restore author")
+		return models.Author{}, fmt.Errorf("failed to restore author: %w", err)
+	}
+
+	return author, nil
+}
+
+// GetTrash returns the deleted books and authors, itemType limits the items to books or authors.
+func (self *Service) GetTrash(
+	ctx context.Context,
+	itemType string,
+	options models.ListOptions,
+) (models.Page[models.TrashItem], error) {
+	query, err := models.NewTrashQuery(itemType)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithError(err).
+			Error("failed to init trash query")
+		return models.Page[models.TrashItem]{}, fmt.Errorf("failed to init trash query: %w", err)
+	}
+
+	items, err := self.DatabaseClient.GetTrash(ctx, query, options)
+	if err != nil {
+		logcontext.FromContext(ctx).
+			WithField("trash_type", itemType).
+			WithError(err).
+			Error("failed to get trash")
+		return models.Page[models.TrashItem]{}, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	return items, nil
+}
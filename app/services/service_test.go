@@ -1,16 +1,20 @@
-package services
+package services_test
 
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/egormizerov/books/app/models"
+	"github.com/egormizerov/books/app/services"
 	"github.com/egormizerov/books/app/services/mocks"
 	logcontext "github.com/egormizerov/books/pkg/log/context"
 	wrappersmocks "github.com/egormizerov/books/pkg/wrappers/mocks"
@@ -18,7 +22,7 @@ import (
 
 type ServiceTests struct {
 	suite.Suite
-	service            Service
+	service            *services.Service
 	mockDatabaseClient *mocks.DatabaseClient
 	uuidMock           *wrappersmocks.UUIDWrapper
 	logger             *logrus.Logger
@@ -27,7 +31,10 @@ type ServiceTests struct {
 	testError         error
 	contextWithLogger context.Context
 	book              models.Book
+	storedBook        models.Book
 	author            models.Author
+	storedAuthor      models.Author
+	listOptions       models.ListOptions
 }
 
 func TestService(t *testing.T) {
@@ -38,43 +45,49 @@ func (self *ServiceTests) SetupTest() {
 	self.logger, self.loggerHook = logrustest.NewNullLogger()
 	self.mockDatabaseClient = mocks.NewDatabaseClient(self.T())
 	self.uuidMock = wrappersmocks.NewUUIDWrapper(self.T())
-	self.service = Service{
-		DatabaseClient: self.mockDatabaseClient,
-		uuid:           self.uuidMock,
-	}
+	self.service = services.NewService(self.mockDatabaseClient, self.uuidMock)
 
 	self.contextWithLogger = logcontext.WithLogger(context.Background(), logrus.NewEntry(self.logger))
 	self.author = models.Author{
 		ID:   uuid.New(),
 		Name: "test_author",
 	}
+	self.storedAuthor = self.author
+	self.storedAuthor.Version = models.FirstVersion
 	self.book = models.Book{
-		ID:     uuid.New(),
-		Title:  "test_title",
-		Author: models.Author{ID: self.author.ID},
+		ID:    uuid.New(),
+		Title: "test_title",
+		Contributors: []models.Contributor{
+			{Author: self.author, Role: models.ContributorRoleAuthor},
+		},
 	}
+	self.storedBook = self.book
+	self.storedBook.CreatedAt = time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	self.storedBook.UpdatedAt = self.storedBook.CreatedAt
+	self.storedBook.Version = models.FirstVersion
+	self.listOptions = models.ListOptions{Limit: 10, Sort: "title"}
 	self.testError = errors.New("test_error")
 }
 
 func (self *ServiceTests) TestNewService() {
-	result := NewService(self.mockDatabaseClient, self.uuidMock)
+	result := services.NewService(self.mockDatabaseClient, self.uuidMock)
 
-	self.Equal(&Service{
-		DatabaseClient: self.mockDatabaseClient,
-		uuid:           self.uuidMock,
-	}, result)
+	self.Same(self.mockDatabaseClient, result.DatabaseClient)
+	self.Same(self.uuidMock, result.UUID())
 }
 
 func (self *ServiceTests) TestCreateBookErrorIfModelsNewBookFailed() {
 	self.uuidMock.On("New").Return(self.book.ID)
 
-	err := self.service.CreateBook(self.contextWithLogger, "", self.author.ID)
+	result, err := self.service.CreateBook(self.contextWithLogger, "", self.book.Contributors, models.BookDetails{})
 
 	self.ErrorContains(err, "failed to init book")
+	self.ErrorIs(err, models.ErrValidation)
+	self.Equal(models.Book{}, result)
 	self.matchLogWithError(
 		self.loggerHook.LastEntry(),
 		logrus.Fields{
-			"author_id": self.author.ID.String(),
+			"book_title": "",
 		},
 		"book title must not be empty",
 		"failed to init book",
@@ -84,17 +97,17 @@ func (self *ServiceTests) TestCreateBookErrorIfModelsNewBookFailed() {
 func (self *ServiceTests) TestCreateBookErrorIfCreateBookFailed() {
 	self.mockDatabaseClient.
 		On("CreateBook", self.contextWithLogger, self.book).
-		Return(self.testError)
+		Return(models.Book{}, self.testError)
 	self.uuidMock.On("New").Return(self.book.ID)
 
-	err := self.service.CreateBook(self.contextWithLogger, self.book.Title, self.author.ID)
+	result, err := self.service.CreateBook(self.contextWithLogger, self.book.Title, self.book.Contributors, models.BookDetails{})
 
 	self.ErrorContains(err, self.testError.Error())
 	self.ErrorContains(err, "failed to create book")
+	self.Equal(models.Book{}, result)
 	self.matchLogWithError(
 		self.loggerHook.LastEntry(),
 		logrus.Fields{
-			"author_id":  self.author.ID.String(),
 			"book_title": self.book.Title,
 		},
 		self.testError.Error(),
@@ -105,20 +118,212 @@ func (self *ServiceTests) TestCreateBookErrorIfCreateBookFailed() {
 func (self *ServiceTests) TestCreateBook() {
 	self.mockDatabaseClient.
 		On("CreateBook", self.contextWithLogger, self.book).
-		Return(nil)
+		Return(self.storedBook, nil)
+	self.uuidMock.On("New").Return(self.book.ID)
+
+	result, err := self.service.CreateBook(self.contextWithLogger, self.book.Title, self.book.Contributors, models.BookDetails{})
+
+	self.NoError(err)
+	self.Equal(self.storedBook, result)
+}
+
+// bookInput returns the input of the batch creating the book.
+func (self *ServiceTests) bookInput(book models.Book) models.BookInput {
+	return models.BookInput{Title: book.Title, Contributors: book.Contributors}
+}
+
+func (self *ServiceTests) TestCreateBooks() {
+	otherBook := self.book
+	otherBook.ID = uuid.New()
+	otherBook.Title = "test_title_2"
+	storedOtherBook := otherBook
+	storedOtherBook.CreatedAt = self.storedBook.CreatedAt
+	self.uuidMock.On("New").Return(self.book.ID).Once()
+	self.uuidMock.On("New").Return(otherBook.ID).Once()
+	self.mockDatabaseClient.
+		On("CreateBooks", self.contextWithLogger, []models.Book{self.book, otherBook}).
+		Return([]models.Book{self.storedBook, storedOtherBook}, nil)
+
+	result, err := self.service.CreateBooks(
+		self.contextWithLogger,
+		[]models.BookInput{self.bookInput(self.book), self.bookInput(otherBook)},
+		true,
+	)
+
+	self.NoError(err)
+	self.Equal([]models.BookResult{{Book: self.storedBook}, {Book: storedOtherBook}}, result)
+}
+
+func (self *ServiceTests) TestCreateBooksAtomicAbortedIfBookInvalid() {
+	self.uuidMock.On("New").Return(self.book.ID)
+
+	result, err := self.service.CreateBooks(
+		self.contextWithLogger,
+		[]models.BookInput{self.bookInput(self.book), {Contributors: self.book.Contributors}},
+		true,
+	)
+
+	self.NoError(err)
+	self.Require().Len(result, 2)
+	self.ErrorIs(result[0].Err, models.ErrBatchAborted)
+	self.ErrorIs(result[1].Err, models.ErrValidation)
+	self.mockDatabaseClient.AssertNotCalled(self.T(), "CreateBooks", mock.Anything, mock.Anything)
+}
+
+func (self *ServiceTests) TestCreateBooksPartialSkipsInvalidBook() {
 	self.uuidMock.On("New").Return(self.book.ID)
+	self.mockDatabaseClient.
+		On("CreateBooks", self.contextWithLogger, []models.Book{self.book}).
+		Return([]models.Book{self.storedBook}, nil)
 
-	err := self.service.CreateBook(self.contextWithLogger, self.book.Title, self.author.ID)
+	result, err := self.service.CreateBooks(
+		self.contextWithLogger,
+		[]models.BookInput{{Contributors: self.book.Contributors}, self.bookInput(self.book)},
+		false,
+	)
 
 	self.NoError(err)
+	self.Require().Len(result, 2)
+	self.ErrorIs(result[0].Err, models.ErrValidation)
+	self.Equal(models.BookResult{Book: self.storedBook}, result[1])
+}
+
+func (self *ServiceTests) TestCreateBooksPartialCreatesBooksOneByOneIfBulkInsertConflicted() {
+	otherBook := self.book
+	otherBook.ID = uuid.New()
+	conflictError := fmt.Errorf("%w: duplicate isbn", models.ErrConflict)
+	self.uuidMock.On("New").Return(self.book.ID).Once()
+	self.uuidMock.On("New").Return(otherBook.ID).Once()
+	self.mockDatabaseClient.
+		On("CreateBooks", self.contextWithLogger, []models.Book{self.book, otherBook}).
+		Return(nil, conflictError)
+	self.mockDatabaseClient.
+		On("CreateBook", self.contextWithLogger, self.book).
+		Return(self.storedBook, nil)
+	self.mockDatabaseClient.
+		On("CreateBook", self.contextWithLogger, otherBook).
+		Return(models.Book{}, conflictError)
+
+	result, err := self.service.CreateBooks(
+		self.contextWithLogger,
+		[]models.BookInput{self.bookInput(self.book), self.bookInput(otherBook)},
+		false,
+	)
+
+	self.NoError(err)
+	self.Equal([]models.BookResult{{Book: self.storedBook}, {Err: conflictError}}, result)
+}
+
+func (self *ServiceTests) TestCreateBooksAtomicCreatesBooksOneByOneInTransactionIfBulkInsertConflicted() {
+	otherBook := self.book
+	otherBook.ID = uuid.New()
+	storedOtherBook := otherBook
+	storedOtherBook.CreatedAt = self.storedBook.CreatedAt
+	conflictError := fmt.Errorf("%w: duplicate isbn", models.ErrConflict)
+	tx := mocks.NewDatabaseClient(self.T())
+	self.uuidMock.On("New").Return(self.book.ID).Once()
+	self.uuidMock.On("New").Return(otherBook.ID).Once()
+	self.mockDatabaseClient.
+		On("CreateBooks", self.contextWithLogger, []models.Book{self.book, otherBook}).
+		Return(nil, conflictError)
+	self.mockDatabaseClient.
+		On("WithTx", self.contextWithLogger, mock.Anything).
+		Return(func(ctx context.Context, unitOfWork func(tx services.DatabaseClient) error) error {
+			return unitOfWork(tx)
+		})
+	tx.On("CreateBook", self.contextWithLogger, self.book).Return(self.storedBook, nil)
+	tx.On("CreateBook", self.contextWithLogger, otherBook).Return(storedOtherBook, nil)
+
+	result, err := self.service.CreateBooks(
+		self.contextWithLogger,
+		[]models.BookInput{self.bookInput(self.book), self.bookInput(otherBook)},
+		true,
+	)
+
+	self.NoError(err)
+	self.Equal([]models.BookResult{{Book: self.storedBook}, {Book: storedOtherBook}}, result)
+}
+
+func (self *ServiceTests) TestCreateBooksAtomicReportsBookFailedInTransaction() {
+	otherBook := self.book
+	otherBook.ID = uuid.New()
+	conflictError := fmt.Errorf("%w: duplicate isbn", models.ErrConflict)
+	tx := mocks.NewDatabaseClient(self.T())
+	self.uuidMock.On("New").Return(self.book.ID).Once()
+	self.uuidMock.On("New").Return(otherBook.ID).Once()
+	self.mockDatabaseClient.
+		On("CreateBooks", self.contextWithLogger, []models.Book{self.book, otherBook}).
+		Return(nil, conflictError)
+	self.mockDatabaseClient.
+		On("WithTx", self.contextWithLogger, mock.Anything).
+		Return(func(ctx context.Context, unitOfWork func(tx services.DatabaseClient) error) error {
+			return unitOfWork(tx)
+		})
+	tx.On("CreateBook", self.contextWithLogger, self.book).Return(self.storedBook, nil)
+	tx.On("CreateBook", self.contextWithLogger, otherBook).Return(models.Book{}, conflictError)
+
+	result, err := self.service.CreateBooks(
+		self.contextWithLogger,
+		[]models.BookInput{self.bookInput(self.book), self.bookInput(otherBook)},
+		true,
+	)
+
+	self.NoError(err)
+	self.Equal([]models.BookResult{{Err: models.ErrBatchAborted}, {Err: conflictError}}, result)
+}
+
+func (self *ServiceTests) TestCreateBooksAtomicErrorIfTransactionFailed() {
+	self.uuidMock.On("New").Return(self.book.ID)
+	self.mockDatabaseClient.
+		On("CreateBooks", self.contextWithLogger, []models.Book{self.book}).
+		Return(nil, fmt.Errorf("%w: duplicate isbn", models.ErrConflict))
+	self.mockDatabaseClient.
+		On("WithTx", self.contextWithLogger, mock.Anything).
+		Return(self.testError)
+
+	result, err := self.service.CreateBooks(self.contextWithLogger, []models.BookInput{self.bookInput(self.book)}, true)
+
+	self.ErrorIs(err, self.testError)
+	self.ErrorContains(err, "failed to create books")
+	self.Nil(result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"books": 1,
+		},
+		self.testError.Error(),
+		"failed to create books",
+	)
+}
+
+func (self *ServiceTests) TestCreateBooksErrorIfCreateBooksFailed() {
+	self.uuidMock.On("New").Return(self.book.ID)
+	self.mockDatabaseClient.
+		On("CreateBooks", self.contextWithLogger, []models.Book{self.book}).
+		Return(nil, self.testError)
+
+	result, err := self.service.CreateBooks(self.contextWithLogger, []models.BookInput{self.bookInput(self.book)}, false)
+
+	self.ErrorIs(err, self.testError)
+	self.ErrorContains(err, "failed to create books")
+	self.Nil(result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"books": 1,
+		},
+		self.testError.Error(),
+		"failed to create books",
+	)
 }
 
 func (self *ServiceTests) TestCreateAuthorErrorIfModelsNewAuthorFailed() {
 	self.uuidMock.On("New").Return(self.author.ID)
 
-	err := self.service.CreateAuthor(self.contextWithLogger, "")
+	result, err := self.service.CreateAuthor(self.contextWithLogger, "")
 
 	self.ErrorContains(err, "failed to init author")
+	self.Equal(models.Author{}, result)
 	self.matchLogWithError(
 		self.loggerHook.LastEntry(),
 		logrus.Fields{},
@@ -130,13 +335,14 @@ func (self *ServiceTests) TestCreateAuthorErrorIfModelsNewAuthorFailed() {
 func (self *ServiceTests) TestCreateAuthorErrorIfCreateAuthorFailed() {
 	self.mockDatabaseClient.
 		On("CreateAuthor", self.contextWithLogger, self.author).
-		Return(self.testError)
+		Return(models.Author{}, self.testError)
 	self.uuidMock.On("New").Return(self.author.ID)
 
-	err := self.service.CreateAuthor(self.contextWithLogger, self.author.Name)
+	result, err := self.service.CreateAuthor(self.contextWithLogger, self.author.Name)
 
 	self.ErrorContains(err, self.testError.Error())
 	self.ErrorContains(err, "failed to create author")
+	self.Equal(models.Author{}, result)
 	self.matchLogWithError(
 		self.loggerHook.LastEntry(),
 		logrus.Fields{
@@ -150,12 +356,13 @@ func (self *ServiceTests) TestCreateAuthorErrorIfCreateAuthorFailed() {
 func (self *ServiceTests) TestCreateAuthor() {
 	self.mockDatabaseClient.
 		On("CreateAuthor", self.contextWithLogger, self.author).
-		Return(nil)
+		Return(self.storedAuthor, nil)
 	self.uuidMock.On("New").Return(self.author.ID)
 
-	err := self.service.CreateAuthor(self.contextWithLogger, self.author.Name)
+	result, err := self.service.CreateAuthor(self.contextWithLogger, self.author.Name)
 
 	self.NoError(err)
+	self.Equal(self.storedAuthor, result)
 }
 
 func (self *ServiceTests) TestGetBookErrorIfGetBookByIdFailed() {
@@ -177,68 +384,543 @@ func (self *ServiceTests) TestGetBookErrorIfGetBookByIdFailed() {
 	)
 }
 
-func (self *ServiceTests) TestGetBookErrorIfGetAuthorByIdFailed() {
+func (self *ServiceTests) TestGetBookErrorIfBookNotFound() {
+	self.mockDatabaseClient.
+		On("GetBookById", self.contextWithLogger, self.book.ID).
+		Return(models.Book{}, models.ErrNotFound)
+
+	result, err := self.service.GetBook(self.contextWithLogger, self.book.ID)
+
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Equal(models.Book{}, result)
+}
+
+func (self *ServiceTests) TestGetBook() {
 	self.mockDatabaseClient.
 		On("GetBookById", self.contextWithLogger, self.book.ID).
 		Return(self.book, nil)
+
+	result, err := self.service.GetBook(self.contextWithLogger, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(self.book, result)
+}
+
+func (self *ServiceTests) TestGetBookWithoutContributors() {
+	book := models.Book{
+		ID:    self.book.ID,
+		Title: self.book.Title,
+	}
+	self.mockDatabaseClient.
+		On("GetBookById", self.contextWithLogger, self.book.ID).
+		Return(book, nil)
+
+	result, err := self.service.GetBook(self.contextWithLogger, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(book, result)
+}
+
+func (self *ServiceTests) TestCreateBookNormalizesDetails() {
+	book := self.book
+	book.ISBN = "9780306406157"
+	book.Language = "en"
+	self.mockDatabaseClient.
+		On("CreateBook", self.contextWithLogger, book).
+		Return(book, nil)
+	self.uuidMock.On("New").Return(self.book.ID)
+
+	result, err := self.service.CreateBook(self.contextWithLogger, self.book.Title, self.book.Contributors, models.BookDetails{
+		ISBN:     "0-306-40615-2",
+		Language: "EN",
+	})
+
+	self.NoError(err)
+	self.Equal(book, result)
+}
+
+func (self *ServiceTests) TestGetBookByISBNErrorIfInvalidISBN() {
+	result, err := self.service.GetBookByISBN(self.contextWithLogger, "123")
+
+	self.ErrorContains(err, "failed to normalize isbn")
+	self.ErrorIs(err, models.ErrValidation)
+	self.Equal(models.Book{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"isbn": "123",
+		},
+		"book ISBN must be a valid ISBN-10 or ISBN-13",
+		"failed to normalize isbn",
+	)
+}
+
+func (self *ServiceTests) TestGetBookByISBNErrorIfGetBookByISBNFailed() {
+	self.mockDatabaseClient.
+		On("GetBookByISBN", self.contextWithLogger, "9780306406157").
+		Return(models.Book{}, self.testError)
+
+	result, err := self.service.GetBookByISBN(self.contextWithLogger, "0-306-40615-2")
+
+	self.ErrorContains(err, "failed to get book by isbn")
+	self.Equal(models.Book{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"isbn": "9780306406157",
+		},
+		self.testError.Error(),
+		"failed to get book",
+	)
+}
+
+func (self *ServiceTests) TestGetBookByISBN() {
+	self.mockDatabaseClient.
+		On("GetBookByISBN", self.contextWithLogger, "9780306406157").
+		Return(self.book, nil)
+
+	result, err := self.service.GetBookByISBN(self.contextWithLogger, "978-0-306-40615-7")
+
+	self.NoError(err)
+	self.Equal(self.book, result)
+}
+
+func (self *ServiceTests) TestGetAuthorsBooksErrorIfGetBooksByAuthorIdFailed() {
+	self.mockDatabaseClient.
+		On("GetBooksByAuthorId", self.contextWithLogger, self.author.ID, self.listOptions).
+		Return(models.Page[models.Book]{}, self.testError)
+
+	result, err := self.service.GetAuthorsBooks(self.contextWithLogger, self.author.ID, self.listOptions)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to get books by author id")
+	self.Equal(models.Page[models.Book]{}, result)
+}
+
+func (self *ServiceTests) TestGetAuthorsBooks() {
+	self.mockDatabaseClient.
+		On("GetBooksByAuthorId", self.contextWithLogger, self.author.ID, self.listOptions).
+		Return(models.Page[models.Book]{Items: []models.Book{self.book}}, nil)
+
+	result, err := self.service.GetAuthorsBooks(self.contextWithLogger, self.author.ID, self.listOptions)
+
+	self.NoError(err)
+	self.Equal(models.Page[models.Book]{Items: []models.Book{self.book}}, result)
+}
+
+func (self *ServiceTests) TestGetAuthorsErrorIfGetAuthorsFailed() {
+	self.mockDatabaseClient.
+		On("GetAuthors", self.contextWithLogger, self.listOptions).
+		Return(models.Page[models.Author]{}, self.testError)
+
+	result, err := self.service.GetAuthors(self.contextWithLogger, self.listOptions)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to get authors")
+	self.Equal(models.Page[models.Author]{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{},
+		self.testError.Error(),
+		"failed to get authors",
+	)
+}
+
+func (self *ServiceTests) TestGetAuthors() {
+	self.mockDatabaseClient.
+		On("GetAuthors", self.contextWithLogger, self.listOptions).
+		Return(models.Page[models.Author]{Items: []models.Author{self.author}}, nil)
+
+	result, err := self.service.GetAuthors(self.contextWithLogger, self.listOptions)
+
+	self.NoError(err)
+	self.Equal(models.Page[models.Author]{Items: []models.Author{self.author}}, result)
+}
+
+func (self *ServiceTests) TestGetAuthorErrorIfGetAuthorByIdFailed() {
 	self.mockDatabaseClient.
 		On("GetAuthorById", self.contextWithLogger, self.author.ID).
 		Return(models.Author{}, self.testError)
 
-	result, err := self.service.GetBook(self.contextWithLogger, self.book.ID)
+	result, err := self.service.GetAuthor(self.contextWithLogger, self.author.ID)
 
 	self.ErrorContains(err, self.testError.Error())
 	self.ErrorContains(err, "failed to get author by id")
-	self.Equal(models.Book{}, result)
+	self.Equal(models.Author{}, result)
 	self.matchLogWithError(
 		self.loggerHook.LastEntry(),
 		logrus.Fields{
-			"book_id": self.book.ID.String(),
+			"author_id": self.author.ID.String(),
 		},
 		self.testError.Error(),
 		"failed to get author",
 	)
 }
 
-func (self *ServiceTests) TestGetBook() {
-	self.mockDatabaseClient.
-		On("GetBookById", self.contextWithLogger, self.book.ID).
-		Return(self.book, nil)
+func (self *ServiceTests) TestGetAuthor() {
 	self.mockDatabaseClient.
 		On("GetAuthorById", self.contextWithLogger, self.author.ID).
 		Return(self.author, nil)
 
-	result, err := self.service.GetBook(self.contextWithLogger, self.book.ID)
+	result, err := self.service.GetAuthor(self.contextWithLogger, self.author.ID)
 
 	self.NoError(err)
-	self.Equal(models.Book{
-		ID:     self.book.ID,
-		Title:  self.book.Title,
-		Author: self.author,
-	}, result)
+	self.Equal(self.author, result)
 }
 
-func (self *ServiceTests) TestGetAuthorsBooksErrorIfGetBooksByAuthorIdFailed() {
+func (self *ServiceTests) TestUpdateAuthorErrorIfModelsNewAuthorFailed() {
+	result, err := self.service.UpdateAuthor(self.contextWithLogger, self.author.ID, "", models.AnyVersion)
+
+	self.ErrorContains(err, "failed to init author")
+	self.Equal(models.Author{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"author_id": self.author.ID.String(),
+		},
+		"author name must not be empty",
+		"failed to init author",
+	)
+}
+
+func (self *ServiceTests) TestUpdateAuthorErrorIfUpdateAuthorFailed() {
 	self.mockDatabaseClient.
-		On("GetBooksByAuthorId", self.contextWithLogger, self.author.ID).
-		Return(nil, self.testError)
+		On("UpdateAuthor", self.contextWithLogger, self.author).
+		Return(models.Author{}, self.testError)
 
-	result, err := self.service.GetAuthorsBooks(self.contextWithLogger, self.author.ID)
+	result, err := self.service.UpdateAuthor(self.contextWithLogger, self.author.ID, self.author.Name, models.AnyVersion)
 
 	self.ErrorContains(err, self.testError.Error())
-	self.ErrorContains(err, "failed to get books by author id")
-	self.Nil(result)
+	self.ErrorContains(err, "failed to update author")
+	self.Equal(models.Author{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"author_id":   self.author.ID.String(),
+			"author_name": self.author.Name,
+		},
+		self.testError.Error(),
+		"failed to update author",
+	)
 }
 
-func (self *ServiceTests) TestGetAuthorsBooks() {
+func (self *ServiceTests) TestUpdateAuthor() {
+	self.mockDatabaseClient.
+		On("UpdateAuthor", self.contextWithLogger, self.storedAuthor).
+		Return(models.Author{ID: self.author.ID, Name: self.author.Name, Version: 2}, nil)
+
+	result, err := self.service.UpdateAuthor(self.contextWithLogger, self.author.ID, self.author.Name, models.FirstVersion)
+
+	self.NoError(err)
+	self.Equal(models.Author{ID: self.author.ID, Name: self.author.Name, Version: 2}, result)
+}
+
+func (self *ServiceTests) TestDeleteAuthorErrorIfDeleteAuthorFailed() {
+	self.mockDatabaseClient.
+		On("DeleteAuthor", self.contextWithLogger, self.author.ID, models.FirstVersion).
+		Return(int64(0), self.testError)
+
+	result, err := self.service.DeleteAuthor(self.contextWithLogger, self.author.ID, models.FirstVersion)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to delete author")
+	self.Zero(result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"author_id": self.author.ID.String(),
+		},
+		self.testError.Error(),
+		"failed to delete author",
+	)
+}
+
+func (self *ServiceTests) TestDeleteAuthor() {
+	self.mockDatabaseClient.
+		On("DeleteAuthor", self.contextWithLogger, self.author.ID, models.FirstVersion).
+		Return(int64(3), nil)
+
+	result, err := self.service.DeleteAuthor(self.contextWithLogger, self.author.ID, models.FirstVersion)
+
+	self.NoError(err)
+	self.Equal(int64(3), result)
+}
+
+func (self *ServiceTests) TestGetBooksErrorIfGetBooksFailed() {
+	self.mockDatabaseClient.
+		On("GetBooks", self.contextWithLogger, self.listOptions).
+		Return(models.Page[models.Book]{}, self.testError)
+
+	result, err := self.service.GetBooks(self.contextWithLogger, self.listOptions)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to get books")
+	self.Equal(models.Page[models.Book]{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{},
+		self.testError.Error(),
+		"failed to get books",
+	)
+}
+
+func (self *ServiceTests) TestGetBooks() {
+	self.mockDatabaseClient.
+		On("GetBooks", self.contextWithLogger, self.listOptions).
+		Return(models.Page[models.Book]{Items: []models.Book{self.book}}, nil)
+
+	result, err := self.service.GetBooks(self.contextWithLogger, self.listOptions)
+
+	self.NoError(err)
+	self.Equal(models.Page[models.Book]{Items: []models.Book{self.book}}, result)
+}
+
+func (self *ServiceTests) TestUpdateBookErrorIfModelsNewBookFailed() {
+	result, err := self.service.UpdateBook(self.contextWithLogger, self.book.ID, "", self.book.Contributors, models.BookDetails{}, models.AnyVersion)
+
+	self.ErrorContains(err, "failed to init book")
+	self.Equal(models.Book{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"book_id": self.book.ID.String(),
+		},
+		"book title must not be empty",
+		"failed to init book",
+	)
+}
+
+func (self *ServiceTests) TestUpdateBookErrorIfUpdateBookFailed() {
+	self.mockDatabaseClient.
+		On("UpdateBook", self.contextWithLogger, self.book).
+		Return(models.Book{}, self.testError)
+
+	result, err := self.service.UpdateBook(self.contextWithLogger, self.book.ID, self.book.Title, self.book.Contributors, models.BookDetails{}, models.AnyVersion)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to update book")
+	self.Equal(models.Book{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"book_id":    self.book.ID.String(),
+			"book_title": self.book.Title,
+		},
+		self.testError.Error(),
+		"failed to update book",
+	)
+}
+
+func (self *ServiceTests) TestUpdateBook() {
+	self.mockDatabaseClient.
+		On("UpdateBook", self.contextWithLogger, models.Book{
+			ID:           self.book.ID,
+			Title:        self.book.Title,
+			Contributors: self.book.Contributors,
+			Version:      models.FirstVersion,
+		}).
+		Return(self.storedBook, nil)
+
+	result, err := self.service.UpdateBook(self.contextWithLogger, self.book.ID, self.book.Title, self.book.Contributors, models.BookDetails{}, models.FirstVersion)
+
+	self.NoError(err)
+	self.Equal(self.storedBook, result)
+}
+
+func (self *ServiceTests) TestDeleteBookErrorIfDeleteBookFailed() {
+	self.mockDatabaseClient.
+		On("DeleteBook", self.contextWithLogger, self.book.ID, models.FirstVersion).
+		Return(self.testError)
+
+	err := self.service.DeleteBook(self.contextWithLogger, self.book.ID, models.FirstVersion)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to delete book")
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"book_id": self.book.ID.String(),
+		},
+		self.testError.Error(),
+		"failed to delete book",
+	)
+}
+
+func (self *ServiceTests) TestDeleteBook() {
+	self.mockDatabaseClient.
+		On("DeleteBook", self.contextWithLogger, self.book.ID, models.FirstVersion).
+		Return(nil)
+
+	err := self.service.DeleteBook(self.contextWithLogger, self.book.ID, models.FirstVersion)
+
+	self.NoError(err)
+}
+
+func (self *ServiceTests) TestSearchErrorIfModelsNewSearchQueryFailed() {
+	result, err := self.service.Search(self.contextWithLogger, "", "", self.listOptions)
+
+	self.ErrorContains(err, "failed to init search query")
+	self.ErrorIs(err, models.ErrValidation)
+	self.Equal(models.Page[models.SearchResult]{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{},
+		"search query must not be empty",
+		"failed to init search query",
+	)
+}
+
+func (self *ServiceTests) TestSearchErrorIfSearchFailed() {
+	query := models.SearchQuery{Text: "test_query", Type: models.SearchResultTypeAuthor}
+	self.mockDatabaseClient.
+		On("Search", self.contextWithLogger, query, self.listOptions).
+		Return(models.Page[models.SearchResult]{}, self.testError)
+
+	result, err := self.service.Search(self.contextWithLogger, query.Text, query.Type, self.listOptions)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to search")
+	self.Equal(models.Page[models.SearchResult]{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"search_query": query.Text,
+			"search_type":  query.Type,
+		},
+		self.testError.Error(),
+		"failed to search",
+	)
+}
+
+func (self *ServiceTests) TestSearch() {
+	query := models.SearchQuery{Text: "test_query"}
+	results := models.Page[models.SearchResult]{Items: []models.SearchResult{{
+		Type:  models.SearchResultTypeAuthor,
+		ID:    self.author.ID,
+		Title: self.author.Name,
+	}}}
+	self.mockDatabaseClient.
+		On("Search", self.contextWithLogger, query, self.listOptions).
+		Return(results, nil)
+
+	result, err := self.service.Search(self.contextWithLogger, query.Text, query.Type, self.listOptions)
+
+	self.NoError(err)
+	self.Equal(results, result)
+}
+
+func (self *ServiceTests) TestRestoreBookErrorIfRestoreBookFailed() {
+	self.mockDatabaseClient.
+		On("RestoreBook", self.contextWithLogger, self.book.ID).
+		Return(models.Book{}, models.NotFoundError{Entity: "book"})
+
+	result, err := self.service.RestoreBook(self.contextWithLogger, self.book.ID)
+
+	self.ErrorContains(err, "failed to restore book")
+	self.ErrorIs(err, models.ErrNotFound)
+	self.Equal(models.Book{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"book_id": self.book.ID.String(),
+		},
+		"book not found",
+		"failed to restore book",
+	)
+}
+
+func (self *ServiceTests) TestRestoreBook() {
+	self.mockDatabaseClient.
+		On("RestoreBook", self.contextWithLogger, self.book.ID).
+		Return(self.storedBook, nil)
+
+	result, err := self.service.RestoreBook(self.contextWithLogger, self.book.ID)
+
+	self.NoError(err)
+	self.Equal(self.storedBook, result)
+}
+
+func (self *ServiceTests) TestRestoreAuthorErrorIfRestoreAuthorFailed() {
+	self.mockDatabaseClient.
+		On("RestoreAuthor", self.contextWithLogger, self.author.ID).
+		Return(models.Author{}, self.testError)
+
+	result, err := self.service.RestoreAuthor(self.contextWithLogger, self.author.ID)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to restore author")
+	self.Equal(models.Author{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"author_id": self.author.ID.String(),
+		},
+		self.testError.Error(),
+		"failed to restore author",
+	)
+}
+
+func (self *ServiceTests) TestRestoreAuthor() {
+	self.mockDatabaseClient.
+		On("RestoreAuthor", self.contextWithLogger, self.author.ID).
+		Return(self.storedAuthor, nil)
+
+	result, err := self.service.RestoreAuthor(self.contextWithLogger, self.author.ID)
+
+	self.NoError(err)
+	self.Equal(self.storedAuthor, result)
+}
+
+func (self *ServiceTests) TestGetTrashErrorIfModelsNewTrashQueryFailed() {
+	result, err := self.service.GetTrash(self.contextWithLogger, "test_type", self.listOptions)
+
+	self.ErrorContains(err, "failed to init trash query")
+	self.ErrorIs(err, models.ErrValidation)
+	self.Equal(models.Page[models.TrashItem]{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{},
+		"trash item type must be book or author",
+		"failed to init trash query",
+	)
+}
+
+func (self *ServiceTests) TestGetTrashErrorIfGetTrashFailed() {
+	query := models.TrashQuery{Type: models.TrashItemTypeBook}
+	self.mockDatabaseClient.
+		On("GetTrash", self.contextWithLogger, query, self.listOptions).
+		Return(models.Page[models.TrashItem]{}, self.testError)
+
+	result, err := self.service.GetTrash(self.contextWithLogger, query.Type, self.listOptions)
+
+	self.ErrorContains(err, self.testError.Error())
+	self.ErrorContains(err, "failed to get trash")
+	self.Equal(models.Page[models.TrashItem]{}, result)
+	self.matchLogWithError(
+		self.loggerHook.LastEntry(),
+		logrus.Fields{
+			"trash_type": query.Type,
+		},
+		self.testError.Error(),
+		"failed to get trash",
+	)
+}
+
+func (self *ServiceTests) TestGetTrash() {
+	items := models.Page[models.TrashItem]{Items: []models.TrashItem{{
+		Type:      models.TrashItemTypeBook,
+		ID:        self.book.ID,
+		Title:     self.book.Title,
+		DeletedAt: time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC),
+	}}}
 	self.mockDatabaseClient.
-		On("GetBooksByAuthorId", self.contextWithLogger, self.author.ID).
-		Return([]models.Book{self.book}, nil)
+		On("GetTrash", self.contextWithLogger, models.TrashQuery{}, self.listOptions).
+		Return(items, nil)
 
-	result, err := self.service.GetAuthorsBooks(self.contextWithLogger, self.author.ID)
+	result, err := self.service.GetTrash(self.contextWithLogger, "", self.listOptions)
 
 	self.NoError(err)
-	self.Equal([]models.Book{self.book}, result)
+	self.Equal(items, result)
 }
 
 func (self *ServiceTests) matchLogWithError(
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+
+	"github.com/egormizerov/books/app/config"
+	"github.com/egormizerov/books/app/database"
+	"github.com/egormizerov/books/app/database/client"
+	"github.com/egormizerov/books/app/database/memory"
+	"github.com/egormizerov/books/app/database/sqlite"
+	"github.com/egormizerov/books/app/services"
+	"github.com/egormizerov/books/database/migrations"
+	"github.com/egormizerov/books/pkg/health"
+	"github.com/egormizerov/books/pkg/metrics"
+	"github.com/egormizerov/books/pkg/migrate"
+	"github.com/egormizerov/books/pkg/wrappers"
+)
+
+// Storage backends selected by config.AppConfig.StorageBackend.
+const (
+	storageBackendDatabase = "database"
+	storageBackendMemory   = "memory"
+)
+
+// storage is the database client of the storage backend and the resources it holds.
+type storage struct {
+	databaseClient services.DatabaseClient
+	// readinessCheck checks the storage is available, it is nil if the storage is always available.
+	readinessCheck health.Check
+	// close releases the resources of the storage, it is nil if there are none.
+	close func() error
+}
+
+// newStorage returns the storage of the configured backend, the failures are fatal.
+func newStorage(logger *logrus.Logger, appConfig config.AppConfig, registry *metrics.Registry) storage {
+	switch appConfig.StorageBackend {
+	case storageBackendDatabase:
+		return newDatabaseStorage(logger, appConfig, registry)
+	case storageBackendMemory:
+		logger.Warn("books are stored in memory and are lost when the server stops")
+		return storage{databaseClient: memory.NewDatabaseClient()}
+	default:
+		logger.
+			WithField("backend", appConfig.StorageBackend).
+			Fatal("unsupported storage backend")
+		return storage{}
+	}
+}
+
+// newDatabaseStorage returns the storage of the configured database driver.
+func newDatabaseStorage(logger *logrus.Logger, appConfig config.AppConfig, registry *metrics.Registry) storage {
+	databaseConnection := connectToDatabase(logger, appConfig)
+	if appConfig.DatabaseMigrateOnStartup {
+		if err := migrateUp(context.Background(), logger, newMigrator(logger, appConfig, databaseConnection)); err != nil {
+			logger.
+				WithError(err).
+				Fatal("failed to migrate database")
+		}
+	}
+
+	registry.Register(metrics.NewDBStatsCollector("books_database_pool", databaseConnection))
+	return storage{
+		databaseClient: newDatabaseClient(logger, appConfig, databaseConnection),
+		readinessCheck: databaseConnection.PingContext,
+		close:          databaseConnection.Close,
+	}
+}
+
+func newDatabaseClient(logger *logrus.Logger, appConfig config.AppConfig, databaseConnection *sqlx.DB) services.DatabaseClient {
+	if appConfig.DatabaseDriver == database.DriverSQLite {
+		return sqlite.NewDatabaseClient(databaseConnection)
+	}
+
+	txIsolationLevel, err := client.ParseIsolationLevel(appConfig.DatabaseTxIsolationLevel)
+	if err != nil {
+		logger.
+			WithError(err).
+			Fatal("failed to parse transaction isolation level")
+	}
+
+	return client.NewDatabaseClient(databaseConnection, client.TxOptions{
+		Isolation:   txIsolationLevel,
+		MaxAttempts: appConfig.DatabaseTxMaxAttempts,
+	})
+}
+
+func connectToDatabase(logger *logrus.Logger, appConfig config.AppConfig) *sqlx.DB {
+	databaseConnection, err := database.ConnectToDatabase(logger, &wrappers.SimpleSqlxWrapper{}, database.ConnectConfig{
+		Driver:     appConfig.DatabaseDriver,
+		URL:        appConfig.DatabaseURL,
+		SQLitePath: appConfig.DatabaseSQLitePath,
+
+		User:     appConfig.DatabaseUser,
+		Password: appConfig.DatabasePassword,
+		Host:     appConfig.DatabaseHost,
+		Port:     appConfig.DatabasePort,
+		Database: appConfig.DatabaseDatabase,
+
+		SSLMode:          appConfig.DatabaseSSLMode,
+		SSLRootCert:      appConfig.DatabaseSSLRootCert,
+		ApplicationName:  appConfig.DatabaseApplicationName,
+		SearchPath:       appConfig.DatabaseSearchPath,
+		StatementTimeout: appConfig.DatabaseStatementTimeout,
+
+		MaxOpenConnections:    appConfig.DatabaseMaxOpenConnections,
+		MaxIdleConnections:    appConfig.DatabaseMaxIdleConnections,
+		ConnectionMaxLifetime: appConfig.DatabaseConnectionMaxLifetime,
+		ConnectionMaxIdleTime: appConfig.DatabaseConnectionMaxIdleTime,
+
+		ConnectTimeout:              appConfig.DatabaseConnectTimeout,
+		ConnectRetryInitialInterval: appConfig.DatabaseConnectRetryInitialInterval,
+		ConnectRetryMaxInterval:     appConfig.DatabaseConnectRetryMaxInterval,
+	})
+	if err != nil {
+		logger.
+			WithError(err).
+			Fatal("failed to get database connection")
+	}
+
+	return databaseConnection
+}
+
+// newMigrator returns the migrator of the schema of the configured database driver.
+func newMigrator(logger *logrus.Logger, appConfig config.AppConfig, databaseConnection *sqlx.DB) *migrate.Migrator {
+	files := migrations.Postgres()
+	if appConfig.DatabaseDriver == database.DriverSQLite {
+		files = migrations.SQLite()
+	}
+	migrator, err := migrate.NewMigrator(databaseConnection, files)
+	if err != nil {
+		logger.
+			WithError(err).
+			Fatal("failed to read migrations")
+	}
+
+	return migrator
+}
@@ -0,0 +1,25 @@
+// Package migrations embeds the SQL migrations of the database schema.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql
+var postgresFiles embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// Postgres returns migrations of the PostgreSQL schema.
+func Postgres() fs.FS {
+	files, _ := fs.Sub(postgresFiles, "postgres")
+	return files
+}
+
+// SQLite returns migrations of the SQLite schema.
+func SQLite() fs.FS {
+	files, _ := fs.Sub(sqliteFiles, "sqlite")
+	return files
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/egormizerov/books/pkg/migrate"
+)
+
+type MigrationsTests struct {
+	suite.Suite
+}
+
+func TestMigrations(t *testing.T) {
+	suite.Run(t, new(MigrationsTests))
+}
+
+func (self *MigrationsTests) TestPostgresMigrationsAreValid() {
+	_, err := migrate.NewMigrator(nil, Postgres())
+
+	self.NoError(err)
+}
+
+func (self *MigrationsTests) TestSQLiteMigrationsAreValid() {
+	_, err := migrate.NewMigrator(nil, SQLite())
+
+	self.NoError(err)
+}
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS authors (
+    id uuid NOT NULL,
+    name varchar(255) NOT NULL UNIQUE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', name)) STORED,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS authors_created_at_id_idx ON authors (created_at, id);
+CREATE INDEX IF NOT EXISTS authors_search_vector_idx ON authors USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS books (
+    id uuid NOT NULL,
+    title varchar(255) NOT NULL,
+    author_id uuid,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', title)) STORED,
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS books_title_id_idx ON books (title, id);
+CREATE INDEX IF NOT EXISTS books_created_at_id_idx ON books (created_at, id);
+CREATE INDEX IF NOT EXISTS books_author_id_title_id_idx ON books (author_id, title, id);
+CREATE INDEX IF NOT EXISTS books_search_vector_idx ON books USING GIN (search_vector);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS books_isbn_idx;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS isbn,
+    DROP COLUMN IF EXISTS publication_date,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS language,
+    DROP COLUMN IF EXISTS page_count,
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,11 @@
+ALTER TABLE books
+    ADD COLUMN isbn char(13),
+    ADD COLUMN publication_date date,
+    ADD COLUMN description text NOT NULL DEFAULT '',
+    ADD COLUMN language varchar(2) NOT NULL DEFAULT '',
+    ADD COLUMN page_count integer NOT NULL DEFAULT 0 CHECK (page_count >= 0),
+    ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
+
+UPDATE books SET updated_at=created_at;
+
+CREATE UNIQUE INDEX books_isbn_idx ON books (isbn);
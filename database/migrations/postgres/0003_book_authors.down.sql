@@ -0,0 +1,11 @@
+ALTER TABLE books ADD COLUMN author_id uuid REFERENCES authors(id) ON DELETE SET NULL;
+
+UPDATE books SET author_id=first_author.author_id
+FROM (
+    SELECT DISTINCT ON (book_id) book_id, author_id FROM book_authors ORDER BY book_id, position
+) AS first_author
+WHERE first_author.book_id=books.id;
+
+CREATE INDEX books_author_id_title_id_idx ON books (author_id, title, id);
+
+DROP TABLE book_authors;
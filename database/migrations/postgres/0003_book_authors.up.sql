@@ -0,0 +1,19 @@
+CREATE TABLE book_authors (
+    book_id uuid NOT NULL,
+    author_id uuid NOT NULL,
+    role varchar(16) NOT NULL CHECK (role IN ('author', 'editor', 'translator', 'illustrator')),
+    position integer NOT NULL,
+
+    PRIMARY KEY (book_id, author_id, role),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_authors_author_id_book_id_idx ON book_authors (author_id, book_id);
+
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT id, author_id, 'author', 0 FROM books WHERE author_id IS NOT NULL;
+
+DROP INDEX books_author_id_title_id_idx;
+
+ALTER TABLE books DROP COLUMN author_id;
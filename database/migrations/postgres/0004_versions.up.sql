@@ -0,0 +1,2 @@
+ALTER TABLE authors ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE books ADD COLUMN version bigint NOT NULL DEFAULT 1;
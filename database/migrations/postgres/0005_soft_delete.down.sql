@@ -0,0 +1,14 @@
+-- The rows in the trash are deleted first, as they would become live again and could break the restored
+-- unique constraints of the names and the ISBNs.
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+DELETE FROM authors WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS books_isbn_idx;
+CREATE UNIQUE INDEX books_isbn_idx ON books (isbn);
+DROP INDEX IF EXISTS authors_name_idx;
+ALTER TABLE authors ADD CONSTRAINT authors_name_key UNIQUE (name);
+
+DROP INDEX IF EXISTS books_deleted_at_id_idx;
+DROP INDEX IF EXISTS authors_deleted_at_id_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE authors DROP COLUMN IF EXISTS deleted_at;
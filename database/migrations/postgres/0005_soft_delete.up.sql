@@ -0,0 +1,13 @@
+ALTER TABLE authors ADD COLUMN deleted_at timestamptz;
+ALTER TABLE books ADD COLUMN deleted_at timestamptz;
+
+-- The trash is listed and purged by the deletion time, the indexes only cover the deleted rows.
+CREATE INDEX authors_deleted_at_id_idx ON authors (deleted_at, id) WHERE deleted_at IS NOT NULL;
+CREATE INDEX books_deleted_at_id_idx ON books (deleted_at, id) WHERE deleted_at IS NOT NULL;
+
+-- The names and the ISBNs are unique among the rows not in the trash, so they can be reused
+-- by the new rows and the rows are restored unless they were reused.
+ALTER TABLE authors DROP CONSTRAINT authors_name_key;
+CREATE UNIQUE INDEX authors_name_idx ON authors (name) WHERE deleted_at IS NULL;
+DROP INDEX books_isbn_idx;
+CREATE UNIQUE INDEX books_isbn_idx ON books (isbn) WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+UPDATE book_authors SET position = position - 1;
@@ -0,0 +1,3 @@
+-- The authors of the existing books were backfilled at position 0 while the contributors are numbered from 1,
+-- shifting all the positions keeps the order of the contributors of every book.
+UPDATE book_authors SET position = position + 1;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS authors_search;
+DROP TABLE IF EXISTS books_search;
+DROP TABLE IF EXISTS book_authors;
+DROP TABLE IF EXISTS books;
+DROP TABLE IF EXISTS authors;
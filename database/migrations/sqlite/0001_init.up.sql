@@ -0,0 +1,63 @@
+CREATE TABLE authors (
+    id TEXT NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX authors_created_at_id_idx ON authors (created_at, id);
+
+CREATE TABLE books (
+    id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    isbn TEXT UNIQUE,
+    publication_date DATE,
+    description TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT '',
+    page_count INTEGER NOT NULL DEFAULT 0 CHECK (page_count >= 0),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX books_title_id_idx ON books (title, id);
+CREATE INDEX books_created_at_id_idx ON books (created_at, id);
+
+CREATE TABLE book_authors (
+    book_id TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('author', 'editor', 'translator', 'illustrator')),
+    position INTEGER NOT NULL,
+
+    PRIMARY KEY (book_id, author_id, role),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_authors_author_id_book_id_idx ON book_authors (author_id, book_id);
+
+-- The full-text indexes are kept in sync with the titles and the names by the triggers.
+CREATE VIRTUAL TABLE books_search USING fts5(title, id UNINDEXED);
+CREATE VIRTUAL TABLE authors_search USING fts5(name, id UNINDEXED);
+
+CREATE TRIGGER books_search_insert AFTER INSERT ON books BEGIN
+    INSERT INTO books_search (title, id) VALUES (new.title, new.id);
+END;
+CREATE TRIGGER books_search_update AFTER UPDATE OF title ON books BEGIN
+    UPDATE books_search SET title=new.title WHERE id=new.id;
+END;
+CREATE TRIGGER books_search_delete AFTER DELETE ON books BEGIN
+    DELETE FROM books_search WHERE id=old.id;
+END;
+
+CREATE TRIGGER authors_search_insert AFTER INSERT ON authors BEGIN
+    INSERT INTO authors_search (name, id) VALUES (new.name, new.id);
+END;
+CREATE TRIGGER authors_search_update AFTER UPDATE OF name ON authors BEGIN
+    UPDATE authors_search SET name=new.name WHERE id=new.id;
+END;
+CREATE TRIGGER authors_search_delete AFTER DELETE ON authors BEGIN
+    DELETE FROM authors_search WHERE id=old.id;
+END;
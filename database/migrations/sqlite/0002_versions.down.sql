@@ -0,0 +1,2 @@
+ALTER TABLE books DROP COLUMN version;
+ALTER TABLE authors DROP COLUMN version;
@@ -0,0 +1,79 @@
+-- The rows in the trash are deleted, as they would become live again and could break the restored
+-- unique constraints of the names and the ISBNs. The tables are rebuilt with the constraints,
+-- the book authors are kept aside, as the dropped tables would cascade to them.
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+DELETE FROM authors WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE book_authors_copy AS SELECT book_id, author_id, role, position FROM book_authors;
+DROP TABLE book_authors;
+
+CREATE TABLE authors_new (
+    id TEXT NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+
+    PRIMARY KEY (id)
+);
+INSERT INTO authors_new (id, name, created_at, version)
+SELECT id, name, created_at, version FROM authors;
+DROP TABLE authors;
+ALTER TABLE authors_new RENAME TO authors;
+
+CREATE TABLE books_new (
+    id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    isbn TEXT UNIQUE,
+    publication_date DATE,
+    description TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT '',
+    page_count INTEGER NOT NULL DEFAULT 0 CHECK (page_count >= 0),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+
+    PRIMARY KEY (id)
+);
+INSERT INTO books_new (id, title, isbn, publication_date, description, language, page_count, created_at, updated_at, version)
+SELECT id, title, isbn, publication_date, description, language, page_count, created_at, updated_at, version FROM books;
+DROP TABLE books;
+ALTER TABLE books_new RENAME TO books;
+
+CREATE TABLE book_authors (
+    book_id TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('author', 'editor', 'translator', 'illustrator')),
+    position INTEGER NOT NULL,
+
+    PRIMARY KEY (book_id, author_id, role),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE CASCADE
+);
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT book_id, author_id, role, position FROM book_authors_copy;
+DROP TABLE book_authors_copy;
+
+CREATE INDEX authors_created_at_id_idx ON authors (created_at, id);
+CREATE INDEX books_title_id_idx ON books (title, id);
+CREATE INDEX books_created_at_id_idx ON books (created_at, id);
+CREATE INDEX book_authors_author_id_book_id_idx ON book_authors (author_id, book_id);
+
+CREATE TRIGGER books_search_insert AFTER INSERT ON books BEGIN
+    INSERT INTO books_search (title, id) VALUES (new.title, new.id);
+END;
+CREATE TRIGGER books_search_update AFTER UPDATE OF title ON books BEGIN
+    UPDATE books_search SET title=new.title WHERE id=new.id;
+END;
+CREATE TRIGGER books_search_delete AFTER DELETE ON books BEGIN
+    DELETE FROM books_search WHERE id=old.id;
+END;
+
+CREATE TRIGGER authors_search_insert AFTER INSERT ON authors BEGIN
+    INSERT INTO authors_search (name, id) VALUES (new.name, new.id);
+END;
+CREATE TRIGGER authors_search_update AFTER UPDATE OF name ON authors BEGIN
+    UPDATE authors_search SET name=new.name WHERE id=new.id;
+END;
+CREATE TRIGGER authors_search_delete AFTER DELETE ON authors BEGIN
+    DELETE FROM authors_search WHERE id=old.id;
+END;
@@ -0,0 +1,258 @@
+// Package cache implements the in-process LRU cache of the expiring values.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// errLoadPanicked is returned to the callers waiting for the load which panicked.
+var errLoadPanicked = errors.New("cache load panicked")
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// call is the load of the missing value shared by the concurrent callers.
+type call[K comparable, V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// invalidations match the loaded value invalidated while it was loaded, such value is not stored.
+	invalidations []func(key K, value V) bool
+}
+
+func (self *call[K, V]) invalidated(key K) bool {
+	for _, invalidation := range self.invalidations {
+		if invalidation(key, self.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAll[K comparable, V any](K, V) bool {
+	return true
+}
+
+// DefaultLoadTimeout limits the loads of the cache created without the load timeout.
+const DefaultLoadTimeout = 10 * time.Second
+
+// detachedContext keeps the values of the context but not its deadline and cancellation,
+// so the load shared by the callers is not interrupted when the caller which started it leaves.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Cache keeps up to the capacity of the recently used values for the TTL. The concurrent loads
+// of the same missing key are collapsed into one, and the value invalidated while it was loaded
+// is not stored, so the invalidation never loses to the load started before it.
+type Cache[K comparable, V any] struct {
+	mutex    sync.Mutex
+	capacity int
+	ttl      time.Duration
+	// loadTimeout limits the load instead of the deadlines of the callers, so the hung load doesn't hold the key forever.
+	loadTimeout time.Duration
+	now         func() time.Time
+	entries     map[K]*list.Element
+	// order holds the entries from the most to the least recently used.
+	order *list.List
+	// calls are the running loads the new callers of the key join, the invalidated loads are removed,
+	// so the callers coming after the invalidation start the new load.
+	calls map[K]*call[K, V]
+}
+
+// New returns the cache of the capacity, the values never expire if the TTL is zero
+// and the loads are limited by DefaultLoadTimeout if the load timeout is zero.
+func New[K comparable, V any](capacity int, ttl time.Duration, loadTimeout time.Duration) *Cache[K, V] {
+	if loadTimeout <= 0 {
+		loadTimeout = DefaultLoadTimeout
+	}
+	return &Cache[K, V]{
+		capacity:    capacity,
+		ttl:         ttl,
+		loadTimeout: loadTimeout,
+		now:         time.Now,
+		entries:     map[K]*list.Element{},
+		order:       list.New(),
+		calls:       map[K]*call[K, V]{},
+	}
+}
+
+// Get returns the value of the key unless it is missing or expired.
+func (self *Cache[K, V]) Get(key K) (V, bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.get(key)
+}
+
+func (self *Cache[K, V]) get(key K) (V, bool) {
+	element, ok := self.entries[key]
+	if !ok {
+		var missing V
+		return missing, false
+	}
+	cached := element.Value.(*entry[K, V])
+	if self.ttl > 0 && !self.now().Before(cached.expiresAt) {
+		self.remove(element)
+		var missing V
+		return missing, false
+	}
+	self.order.MoveToFront(element)
+
+	return cached.value, true
+}
+
+// Set stores the value of the key, the least recently used value is evicted if the cache is full.
+func (self *Cache[K, V]) Set(key K, value V) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.set(key, value)
+}
+
+func (self *Cache[K, V]) set(key K, value V) {
+	if self.capacity <= 0 {
+		return
+	}
+	expiresAt := self.now().Add(self.ttl)
+	if element, ok := self.entries[key]; ok {
+		cached := element.Value.(*entry[K, V])
+		cached.value, cached.expiresAt = value, expiresAt
+		self.order.MoveToFront(element)
+		return
+	}
+
+	self.entries[key] = self.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if self.order.Len() > self.capacity {
+		self.remove(self.order.Back())
+	}
+}
+
+func (self *Cache[K, V]) remove(element *list.Element) {
+	self.order.Remove(element)
+	delete(self.entries, element.Value.(*entry[K, V]).key)
+}
+
+// Delete removes the value of the key.
+func (self *Cache[K, V]) Delete(key K) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	if running, ok := self.calls[key]; ok {
+		running.invalidations = append(running.invalidations, matchAll[K, V])
+		delete(self.calls, key)
+	}
+	if element, ok := self.entries[key]; ok {
+		self.remove(element)
+	}
+}
+
+// DeleteFunc removes the values matched by the function. The running loads can't be matched
+// before they end, so their values are matched when loaded.
+func (self *Cache[K, V]) DeleteFunc(match func(key K, value V) bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	self.invalidateCalls(match)
+	for element := self.order.Front(); element != nil; {
+		next := element.Next()
+		if cached := element.Value.(*entry[K, V]); match(cached.key, cached.value) {
+			self.remove(element)
+		}
+		element = next
+	}
+}
+
+// Purge removes all the values.
+func (self *Cache[K, V]) Purge() {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	self.invalidateCalls(matchAll[K, V])
+	self.entries = map[K]*list.Element{}
+	self.order.Init()
+}
+
+func (self *Cache[K, V]) invalidateCalls(match func(key K, value V) bool) {
+	for _, running := range self.calls {
+		running.invalidations = append(running.invalidations, match)
+	}
+	self.calls = map[K]*call[K, V]{}
+}
+
+// Len returns the number of the stored values including the expired ones.
+func (self *Cache[K, V]) Len() int {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	return self.order.Len()
+}
+
+// GetOrLoad returns the cached value of the key or loads and stores it, hit reports whether the value was cached.
+// The concurrent callers missing the same key wait for the single load and share its result,
+// the failed load is not stored. The load runs on the context detached from the cancellation of the callers
+// and limited by the load timeout, and each caller stops waiting for it when its own context is done.
+func (self *Cache[K, V]) GetOrLoad(
+	ctx context.Context,
+	key K,
+	load func(ctx context.Context) (V, error),
+) (value V, hit bool, err error) {
+	self.mutex.Lock()
+	if value, ok := self.get(key); ok {
+		self.mutex.Unlock()
+		return value, true, nil
+	}
+	running, ok := self.calls[key]
+	if !ok {
+		running = &call[K, V]{done: make(chan struct{})}
+		self.calls[key] = running
+		go self.load(detachedContext{ctx}, key, running, load)
+	}
+	self.mutex.Unlock()
+
+	select {
+	case <-running.done:
+		return running.value, false, running.err
+	case <-ctx.Done():
+		var missing V
+		return missing, false, ctx.Err()
+	}
+}
+
+func (self *Cache[K, V]) load(ctx context.Context, key K, running *call[K, V], load func(ctx context.Context) (V, error)) {
+	ctx, cancel := context.WithTimeout(ctx, self.loadTimeout)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			running.err = fmt.Errorf("%w: %v", errLoadPanicked, recovered)
+		}
+
+		self.mutex.Lock()
+		if self.calls[key] == running {
+			delete(self.calls, key)
+		}
+		if running.err == nil && !running.invalidated(key) {
+			self.set(key, running.value)
+		}
+		self.mutex.Unlock()
+		close(running.done)
+	}()
+	running.value, running.err = load(ctx)
+}
@@ -0,0 +1,318 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheTests struct {
+	suite.Suite
+	cache *Cache[string, int]
+	now   time.Time
+	ctx   context.Context
+
+	testError error
+}
+
+func TestCache(t *testing.T) {
+	suite.Run(t, new(CacheTests))
+}
+
+func (self *CacheTests) SetupTest() {
+	self.cache = New[string, int](2, time.Minute, time.Second)
+	self.now = time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	self.cache.now = func() time.Time { return self.now }
+	self.ctx = context.Background()
+	self.testError = errors.New("test_error")
+}
+
+func (self *CacheTests) TestGet() {
+	self.cache.Set("a", 1)
+
+	value, ok := self.cache.Get("a")
+
+	self.True(ok)
+	self.Equal(1, value)
+}
+
+func (self *CacheTests) TestGetMissing() {
+	_, ok := self.cache.Get("a")
+
+	self.False(ok)
+}
+
+func (self *CacheTests) TestGetExpired() {
+	self.cache.Set("a", 1)
+	self.now = self.now.Add(time.Minute)
+
+	_, ok := self.cache.Get("a")
+
+	self.False(ok)
+	self.Equal(0, self.cache.Len())
+}
+
+func (self *CacheTests) TestGetNeverExpiresWithoutTTL() {
+	self.cache.ttl = 0
+	self.cache.Set("a", 1)
+	self.now = self.now.Add(time.Hour)
+
+	_, ok := self.cache.Get("a")
+
+	self.True(ok)
+}
+
+func (self *CacheTests) TestSetEvictsLeastRecentlyUsed() {
+	self.cache.Set("a", 1)
+	self.cache.Set("b", 2)
+	self.cache.Get("a")
+
+	self.cache.Set("c", 3)
+
+	_, ok := self.cache.Get("b")
+	self.False(ok)
+	_, ok = self.cache.Get("a")
+	self.True(ok)
+	_, ok = self.cache.Get("c")
+	self.True(ok)
+}
+
+func (self *CacheTests) TestSetReplacesValue() {
+	self.cache.Set("a", 1)
+
+	self.cache.Set("a", 2)
+
+	value, _ := self.cache.Get("a")
+	self.Equal(2, value)
+	self.Equal(1, self.cache.Len())
+}
+
+func (self *CacheTests) TestSetWithoutCapacity() {
+	self.cache.capacity = 0
+
+	self.cache.Set("a", 1)
+
+	self.Equal(0, self.cache.Len())
+}
+
+func (self *CacheTests) TestDeleteAndPurge() {
+	self.cache.Set("a", 1)
+	self.cache.Set("b", 2)
+
+	self.cache.Delete("a")
+
+	_, ok := self.cache.Get("a")
+	self.False(ok)
+	self.cache.Purge()
+	self.Equal(0, self.cache.Len())
+}
+
+func (self *CacheTests) TestDeleteFunc() {
+	self.cache.Set("a", 1)
+	self.cache.Set("b", 2)
+
+	self.cache.DeleteFunc(func(key string, value int) bool { return value == 1 })
+
+	_, ok := self.cache.Get("a")
+	self.False(ok)
+	_, ok = self.cache.Get("b")
+	self.True(ok)
+}
+
+func (self *CacheTests) TestGetOrLoad() {
+	value, hit, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) { return 1, nil })
+
+	self.NoError(err)
+	self.False(hit)
+	self.Equal(1, value)
+
+	value, hit, err = self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) {
+		self.Fail("value must be cached")
+		return 0, nil
+	})
+
+	self.NoError(err)
+	self.True(hit)
+	self.Equal(1, value)
+}
+
+func (self *CacheTests) TestGetOrLoadDoesNotStoreError() {
+	_, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) { return 0, self.testError })
+
+	self.ErrorIs(err, self.testError)
+	self.Equal(0, self.cache.Len())
+}
+
+func (self *CacheTests) TestGetOrLoadDoesNotStoreValueDeletedDuringLoad() {
+	_, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) {
+		self.cache.Delete("a")
+		return 1, nil
+	})
+
+	self.NoError(err)
+	self.Equal(0, self.cache.Len())
+}
+
+func (self *CacheTests) TestGetOrLoadStoresValueIfOtherKeyDeletedDuringLoad() {
+	_, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) {
+		self.cache.Delete("b")
+		return 1, nil
+	})
+
+	self.NoError(err)
+	_, ok := self.cache.Get("a")
+	self.True(ok)
+}
+
+func (self *CacheTests) TestGetOrLoadDoesNotStoreValueMatchedDuringLoad() {
+	_, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) {
+		self.cache.DeleteFunc(func(key string, value int) bool { return value == 1 })
+		return 1, nil
+	})
+	self.NoError(err)
+	_, _, err = self.cache.GetOrLoad(self.ctx, "b", func(context.Context) (int, error) {
+		self.cache.DeleteFunc(func(key string, value int) bool { return value == 1 })
+		return 2, nil
+	})
+	self.NoError(err)
+
+	_, ok := self.cache.Get("a")
+	self.False(ok)
+	_, ok = self.cache.Get("b")
+	self.True(ok)
+}
+
+func (self *CacheTests) TestGetOrLoadDoesNotJoinLoadDeletedBeforeCall() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _, _ = self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+	self.cache.Delete("a")
+
+	value, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) { return 2, nil })
+	close(release)
+
+	self.NoError(err)
+	self.Equal(2, value)
+}
+
+func (self *CacheTests) TestGetOrLoadCollapsesConcurrentLoads() {
+	loads := 0
+	release := make(chan struct{})
+	var wait sync.WaitGroup
+	for index := 0; index < 10; index++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			value, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) {
+				loads++
+				<-release
+				return 1, nil
+			})
+			self.NoError(err)
+			self.Equal(1, value)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wait.Wait()
+
+	self.Equal(1, loads)
+}
+
+func (self *CacheTests) TestGetOrLoadStopsWaitingIfContextIsDone() {
+	ctx, cancel := context.WithCancel(self.ctx)
+	release := make(chan struct{})
+	loaded := make(chan struct{})
+	go func() {
+		<-release
+		cancel()
+	}()
+
+	_, _, err := self.cache.GetOrLoad(ctx, "a", func(ctx context.Context) (int, error) {
+		defer close(loaded)
+		close(release)
+		<-time.After(10 * time.Millisecond)
+		return 1, ctx.Err()
+	})
+
+	self.ErrorIs(err, context.Canceled)
+	<-loaded
+	self.Eventually(func() bool {
+		_, ok := self.cache.Get("a")
+		return ok
+	}, time.Second, time.Millisecond)
+}
+
+func (self *CacheTests) TestGetOrLoadKeepsContextValuesWithLoadTimeout() {
+	type contextKey struct{}
+	ctx, cancel := context.WithTimeout(context.WithValue(self.ctx, contextKey{}, "test_value"), time.Minute)
+	defer cancel()
+
+	_, _, err := self.cache.GetOrLoad(ctx, "a", func(ctx context.Context) (int, error) {
+		deadline, ok := ctx.Deadline()
+		self.True(ok)
+		self.WithinDuration(time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+		self.Equal("test_value", ctx.Value(contextKey{}))
+		return 1, nil
+	})
+
+	self.NoError(err)
+}
+
+func (self *CacheTests) TestGetOrLoadErrorIfLoadTimedOut() {
+	self.cache.loadTimeout = 10 * time.Millisecond
+	_, _, err := self.cache.GetOrLoad(self.ctx, "a", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	self.ErrorIs(err, context.DeadlineExceeded)
+
+	value, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) { return 1, nil })
+
+	self.NoError(err)
+	self.Equal(1, value)
+}
+
+func (self *CacheTests) TestNewWithDefaultLoadTimeout() {
+	cache := New[string, int](2, time.Minute, 0)
+
+	self.Equal(DefaultLoadTimeout, cache.loadTimeout)
+}
+
+func (self *CacheTests) TestGetOrLoadErrorIfLoadPanicked() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	first := make(chan error)
+	go func() {
+		_, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) {
+			close(started)
+			<-release
+			panic("test_panic")
+		})
+		first <- err
+	}()
+	<-started
+
+	second := make(chan error)
+	go func() {
+		_, _, err := self.cache.GetOrLoad(self.ctx, "a", func(context.Context) (int, error) { return 1, nil })
+		second <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	self.ErrorIs(<-first, errLoadPanicked)
+	self.ErrorIs(<-second, errLoadPanicked)
+	self.Equal(0, self.cache.Len())
+}
@@ -3,6 +3,7 @@ package env
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 func GetString(key string, defaultValue string) string {
@@ -30,3 +31,12 @@ func GetBool(key string, defaultVal bool) bool {
 
 	return defaultVal
 }
+
+func GetDuration(key string, defaultVal time.Duration) time.Duration {
+	valStr := GetString(key, "")
+	if val, err := time.ParseDuration(valStr); err == nil {
+		return val
+	}
+
+	return defaultVal
+}
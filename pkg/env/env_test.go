@@ -3,6 +3,7 @@ package env
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -79,3 +80,22 @@ func (self *EnvTest) TestGetBoolDefault() {
 
 	self.Equal(defaultValue, result)
 }
+
+func (self *EnvTest) TestGetDuration() {
+	envValue := "1m30s"
+	envValueDuration := 90 * time.Second
+	err := os.Setenv(self.envKey, envValue)
+	self.NoError(err)
+
+	result := GetDuration(self.envKey, 0)
+
+	self.Equal(envValueDuration, result)
+}
+
+func (self *EnvTest) TestGetDurationDefault() {
+	defaultValue := time.Second
+
+	result := GetDuration(self.envKey, defaultValue)
+
+	self.Equal(defaultValue, result)
+}
@@ -0,0 +1,147 @@
+// Package health reports the liveness and the readiness of the process to the orchestrator.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of the process and its dependencies.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+)
+
+// Check returns the error if the dependency is not available.
+type Check func(ctx context.Context) error
+
+// Report is the response body of the health endpoints.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckReport `json:"checks,omitempty"`
+}
+
+// CheckReport is the result of the check of the dependency.
+type CheckReport struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health runs the checks of the dependencies, the process is ready if all of them succeed.
+type Health struct {
+	timeout  time.Duration
+	mutex    sync.Mutex
+	checks   []namedCheck
+	draining int32
+}
+
+// New returns the health with the timeout of every readiness check.
+func New(timeout time.Duration) *Health {
+	return &Health{timeout: timeout}
+}
+
+// Register adds the check of the dependency with the name.
+func (self *Health) Register(name string, check Check) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.checks = append(self.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes the process not ready, so the load balancer stops sending new requests to it.
+func (self *Health) Drain() {
+	atomic.StoreInt32(&self.draining, 1)
+}
+
+func (self *Health) isDraining() bool {
+	return atomic.LoadInt32(&self.draining) == 1
+}
+
+// Live responds with 200 while the process is able to handle requests at all.
+func (self *Health) Live(response http.ResponseWriter, _ *http.Request) {
+	writeReport(response, http.StatusOK, Report{Status: StatusOK})
+}
+
+// Ready responds with 200 if all the checks succeed, and with 503 if any of them fails or the process is draining.
+func (self *Health) Ready(response http.ResponseWriter, request *http.Request) {
+	if self.isDraining() {
+		writeReport(response, http.StatusServiceUnavailable, Report{Status: StatusDraining})
+		return
+	}
+
+	report := self.Check(request.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(response, status, report)
+}
+
+// Check runs all the checks concurrently and returns their results.
+func (self *Health) Check(ctx context.Context) Report {
+	self.mutex.Lock()
+	checks := append([]namedCheck{}, self.checks...)
+	self.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, self.timeout)
+	defer cancel()
+
+	reports := make([]CheckReport, len(checks))
+	var group sync.WaitGroup
+	for index, check := range checks {
+		group.Add(1)
+		go func(index int, check Check) {
+			defer group.Done()
+			reports[index] = runCheck(ctx, check)
+		}(index, check.check)
+	}
+	group.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckReport, len(checks))}
+	for index, check := range checks {
+		report.Checks[check.name] = reports[index]
+		if reports[index].Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) CheckReport {
+	start := time.Now()
+	err := check(ctx)
+	report := CheckReport{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		report.Status = StatusUnavailable
+		report.Error = err.Error()
+	}
+
+	return report
+}
+
+func writeReport(response http.ResponseWriter, status int, report Report) {
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		http.Error(response, report.Status, status)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("Cache-Control", "no-store")
+	response.WriteHeader(status)
+	_, _ = response.Write(reportJson)
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthTests struct {
+	suite.Suite
+	health *Health
+}
+
+func TestHealth(t *testing.T) {
+	suite.Run(t, new(HealthTests))
+}
+
+func (self *HealthTests) SetupTest() {
+	self.health = New(50 * time.Millisecond)
+}
+
+func (self *HealthTests) serve(handler http.HandlerFunc) (*httptest.ResponseRecorder, Report) {
+	response := httptest.NewRecorder()
+	handler(response, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var report Report
+	self.Require().NoError(json.Unmarshal(response.Body.Bytes(), &report))
+	return response, report
+}
+
+func (self *HealthTests) TestLive() {
+	self.health.Register("database", func(context.Context) error { return errors.New("test_error") })
+
+	response, report := self.serve(self.health.Live)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal("application/json", response.Header().Get("Content-Type"))
+	self.Equal(Report{Status: StatusOK}, report)
+}
+
+func (self *HealthTests) TestReady() {
+	self.health.Register("database", func(context.Context) error { return nil })
+
+	response, report := self.serve(self.health.Ready)
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(StatusOK, report.Status)
+	self.Equal(StatusOK, report.Checks["database"].Status)
+	self.Empty(report.Checks["database"].Error)
+}
+
+func (self *HealthTests) TestReadyErrorIfCheckFailed() {
+	self.health.Register("database", func(context.Context) error { return nil })
+	self.health.Register("cache", func(context.Context) error { return errors.New("test_error") })
+
+	response, report := self.serve(self.health.Ready)
+
+	self.Equal(http.StatusServiceUnavailable, response.Code)
+	self.Equal(StatusUnavailable, report.Status)
+	self.Equal(StatusOK, report.Checks["database"].Status)
+	self.Equal(CheckReport{Status: StatusUnavailable, LatencyMs: report.Checks["cache"].LatencyMs, Error: "test_error"}, report.Checks["cache"])
+}
+
+func (self *HealthTests) TestReadyErrorIfCheckTimedOut() {
+	self.health.Register("database", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	response, report := self.serve(self.health.Ready)
+
+	self.Equal(http.StatusServiceUnavailable, response.Code)
+	self.Equal(context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+	self.GreaterOrEqual(report.Checks["database"].LatencyMs, float64(50))
+}
+
+func (self *HealthTests) TestReadyIfDraining() {
+	self.health.Register("database", func(context.Context) error {
+		self.Fail("check must not run while draining")
+		return nil
+	})
+
+	self.health.Drain()
+	response, report := self.serve(self.health.Ready)
+
+	self.Equal(http.StatusServiceUnavailable, response.Code)
+	self.Equal(Report{Status: StatusDraining}, report)
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"database/sql"
+)
+
+// DBStatsSource is the connection pool reporting its statistics, such as *sql.DB.
+type DBStatsSource interface {
+	Stats() sql.DBStats
+}
+
+// DBStatsCollector exposes the statistics of the connection pool, prefix is prepended to the metric names.
+type DBStatsCollector struct {
+	prefix string
+	source DBStatsSource
+}
+
+func NewDBStatsCollector(prefix string, source DBStatsSource) *DBStatsCollector {
+	return &DBStatsCollector{prefix: prefix, source: source}
+}
+
+func (self *DBStatsCollector) Collect() []Family {
+	stats := self.source.Stats()
+	family := func(name string, help string, familyType string, value float64) Family {
+		return Family{Name: self.prefix + name, Help: help, Type: familyType, Samples: []Sample{{Value: value}}}
+	}
+
+	return []Family{
+		family("_max_open_connections", "Maximum number of open connections to the database.", TypeGauge, float64(stats.MaxOpenConnections)),
+		family("_open_connections", "Number of established connections both in use and idle.", TypeGauge, float64(stats.OpenConnections)),
+		family("_in_use_connections", "Number of connections currently in use.", TypeGauge, float64(stats.InUse)),
+		family("_idle_connections", "Number of idle connections.", TypeGauge, float64(stats.Idle)),
+		family("_wait_count_total", "Total number of connections waited for.", TypeCounter, float64(stats.WaitCount)),
+		family("_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", TypeCounter, stats.WaitDuration.Seconds()),
+		family("_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.", TypeCounter, float64(stats.MaxIdleClosed)),
+		family("_max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime.", TypeCounter, float64(stats.MaxIdleTimeClosed)),
+		family("_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.", TypeCounter, float64(stats.MaxLifetimeClosed)),
+	}
+}
@@ -0,0 +1,324 @@
+// Package metrics collects counters, histograms and gauges and writes them in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Types of the metric families.
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// DefaultBuckets are the upper bounds of the histogram buckets suited for latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Family is the group of samples sharing the metric name.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Sample is the value of the metric, Suffix is appended to the name of the family such as _bucket.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Collector returns the current values of its metrics.
+type Collector interface {
+	Collect() []Family
+}
+
+// Registry is the set of collectors exposed together.
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (self *Registry) Register(collectors ...Collector) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.collectors = append(self.collectors, collectors...)
+}
+
+// Gather returns the families of all the collectors sorted by name.
+func (self *Registry) Gather() []Family {
+	self.mutex.Lock()
+	collectors := append([]Collector{}, self.collectors...)
+	self.mutex.Unlock()
+
+	var families []Family
+	for _, collector := range collectors {
+		families = append(families, collector.Collect()...)
+	}
+	sort.SliceStable(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+
+	return families
+}
+
+// Write writes the families of all the collectors in the text exposition format.
+func (self *Registry) Write(writer io.Writer) error {
+	buffered := bufio.NewWriter(writer)
+	for _, family := range self.Gather() {
+		if family.Help != "" {
+			_, _ = fmt.Fprintf(buffered, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		}
+		_, _ = fmt.Fprintf(buffered, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			_, _ = buffered.WriteString(family.Name + sample.Suffix)
+			writeLabels(buffered, sample.Labels)
+			_, _ = buffered.WriteString(" " + formatFloat(sample.Value) + "\n")
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// ServeHTTP responds with the metrics of the registry.
+func (self *Registry) ServeHTTP(response http.ResponseWriter, _ *http.Request) {
+	response.Header().Set("Content-Type", ContentType)
+	_ = self.Write(response)
+}
+
+func writeLabels(writer *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	_ = writer.WriteByte('{')
+	for index, label := range labels {
+		if index > 0 {
+			_ = writer.WriteByte(',')
+		}
+		_, _ = writer.WriteString(label.Name + `="` + escapeLabelValue(label.Value) + `"`)
+	}
+	_ = writer.WriteByte('}')
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// vector keeps the values of the metric for every combination of the label values.
+type vector[T any] struct {
+	labels []string
+	mutex  sync.Mutex
+	values map[string]*labelled[T]
+	create func() T
+}
+
+type labelled[T any] struct {
+	labels []Label
+	value  T
+}
+
+func newVector[T any](labels []string, create func() T) vector[T] {
+	return vector[T]{labels: labels, values: map[string]*labelled[T]{}, create: create}
+}
+
+// with calls update with the value of the label values while the vector is locked.
+func (self *vector[T]) with(labelValues []string, update func(value T)) {
+	if len(labelValues) != len(self.labels) {
+		panic(fmt.Sprintf("metrics: %d label values are passed for %d labels", len(labelValues), len(self.labels)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	value, ok := self.values[key]
+	if !ok {
+		labels := make([]Label, 0, len(labelValues))
+		for index, labelValue := range labelValues {
+			labels = append(labels, Label{Name: self.labels[index], Value: labelValue})
+		}
+		value = &labelled[T]{labels: labels, value: self.create()}
+		self.values[key] = value
+	}
+	update(value.value)
+}
+
+// each calls collect with the values sorted by the label values while the vector is locked.
+func (self *vector[T]) each(collect func(labels []Label, value T)) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	keys := make([]string, 0, len(self.values))
+	for key := range self.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		collect(self.values[key].labels, self.values[key].value)
+	}
+}
+
+// CounterVec is the counter partitioned by the label values.
+type CounterVec struct {
+	name   string
+	help   string
+	vector vector[*float64]
+}
+
+func NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		name:   name,
+		help:   help,
+		vector: newVector(labels, func() *float64 { return new(float64) }),
+	}
+}
+
+func (self *CounterVec) Inc(labelValues ...string) {
+	self.Add(1, labelValues...)
+}
+
+// Add increases the counter of the label values, value must not be negative.
+func (self *CounterVec) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		panic("metrics: counter can not decrease")
+	}
+	self.vector.with(labelValues, func(counter *float64) {
+		*counter += value
+	})
+}
+
+func (self *CounterVec) Collect() []Family {
+	family := Family{Name: self.name, Help: self.help, Type: TypeCounter}
+	self.vector.each(func(labels []Label, counter *float64) {
+		family.Samples = append(family.Samples, Sample{Labels: labels, Value: *counter})
+	})
+
+	return []Family{family}
+}
+
+// HistogramVec is the histogram partitioned by the label values.
+type HistogramVec struct {
+	name    string
+	help    string
+	buckets []float64
+	vector  vector[*histogram]
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec returns the histogram with the buckets of the upper bounds, the +Inf bucket is added implicitly.
+func NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		vector: newVector(labels, func() *histogram {
+			return &histogram{counts: make([]uint64, len(buckets))}
+		}),
+	}
+}
+
+func (self *HistogramVec) Observe(value float64, labelValues ...string) {
+	self.vector.with(labelValues, func(histogram *histogram) {
+		for index, bucket := range self.buckets {
+			if value <= bucket {
+				histogram.counts[index]++
+			}
+		}
+		histogram.count++
+		histogram.sum += value
+	})
+}
+
+func (self *HistogramVec) Collect() []Family {
+	family := Family{Name: self.name, Help: self.help, Type: TypeHistogram}
+	self.vector.each(func(labels []Label, histogram *histogram) {
+		for index, bucket := range self.buckets {
+			family.Samples = append(family.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: withLabel(labels, "le", formatFloat(bucket)),
+				Value:  float64(histogram.counts[index]),
+			})
+		}
+		family.Samples = append(family.Samples,
+			Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", "+Inf"), Value: float64(histogram.count)},
+			Sample{Suffix: "_sum", Labels: labels, Value: histogram.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(histogram.count)},
+		)
+	})
+
+	return []Family{family}
+}
+
+func withLabel(labels []Label, name string, value string) []Label {
+	return append(labels[:len(labels):len(labels)], Label{Name: name, Value: value})
+}
+
+// GaugeFunc is the gauge reading its value when it is collected.
+type GaugeFunc struct {
+	name  string
+	help  string
+	value func() float64
+}
+
+func NewGaugeFunc(name string, help string, value func() float64) *GaugeFunc {
+	return &GaugeFunc{name: name, help: help, value: value}
+}
+
+func (self *GaugeFunc) Collect() []Family {
+	return []Family{{
+		Name:    self.name,
+		Help:    self.help,
+		Type:    TypeGauge,
+		Samples: []Sample{{Value: self.value()}},
+	}}
+}
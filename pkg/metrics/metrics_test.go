@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"bytes"
+	"database/sql"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTests struct {
+	suite.Suite
+	registry *Registry
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, new(MetricsTests))
+}
+
+func (self *MetricsTests) SetupTest() {
+	self.registry = NewRegistry()
+}
+
+func (self *MetricsTests) write() string {
+	var buffer bytes.Buffer
+	self.Require().NoError(self.registry.Write(&buffer))
+	return buffer.String()
+}
+
+func (self *MetricsTests) TestCounterVec() {
+	counter := NewCounterVec("test_requests_total", "Total number of requests.", "method", "status")
+	self.registry.Register(counter)
+
+	counter.Inc("GET", "200")
+	counter.Add(2, "GET", "200")
+	counter.Inc("DELETE", "500")
+
+	self.Equal(`# HELP test_requests_total Total number of requests.
+# TYPE test_requests_total counter
+test_requests_total{method="DELETE",status="500"} 1
+test_requests_total{method="GET",status="200"} 3
+`, self.write())
+}
+
+func (self *MetricsTests) TestCounterVecPanicsIfLabelValuesMismatch() {
+	counter := NewCounterVec("test_requests_total", "", "method")
+
+	self.Panics(func() { counter.Inc("GET", "200") })
+	self.Panics(func() { counter.Add(-1, "GET") })
+}
+
+func (self *MetricsTests) TestHistogramVec() {
+	histogram := NewHistogramVec("test_duration_seconds", "Duration.", []float64{1, 0.5}, "route")
+	self.registry.Register(histogram)
+
+	histogram.Observe(0.25, "/api/books")
+	histogram.Observe(0.75, "/api/books")
+	histogram.Observe(2, "/api/books")
+
+	self.Equal(`# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/api/books",le="0.5"} 1
+test_duration_seconds_bucket{route="/api/books",le="1"} 2
+test_duration_seconds_bucket{route="/api/books",le="+Inf"} 3
+test_duration_seconds_sum{route="/api/books"} 3
+test_duration_seconds_count{route="/api/books"} 3
+`, self.write())
+}
+
+func (self *MetricsTests) TestGaugeFunc() {
+	self.registry.Register(NewGaugeFunc("test_value", "", func() float64 { return math.Inf(1) }))
+
+	self.Equal("# TYPE test_value gauge\ntest_value +Inf\n", self.write())
+}
+
+func (self *MetricsTests) TestWriteEscapesAndSortsFamilies() {
+	counter := NewCounterVec("test_b_total", "Line\nwith \\ backslash.", "path")
+	self.registry.Register(counter, NewGaugeFunc("test_a", "", func() float64 { return 1.5 }))
+
+	counter.Inc("say \"hi\"\n")
+
+	self.Equal(`# TYPE test_a gauge
+test_a 1.5
+# HELP test_b_total Line\nwith \\ backslash.
+# TYPE test_b_total counter
+test_b_total{path="say \"hi\"\n"} 1
+`, self.write())
+}
+
+type dbStatsSource sql.DBStats
+
+func (self dbStatsSource) Stats() sql.DBStats {
+	return sql.DBStats(self)
+}
+
+func (self *MetricsTests) TestDBStatsCollector() {
+	self.registry.Register(NewDBStatsCollector("test_db", dbStatsSource{
+		MaxOpenConnections: 10,
+		OpenConnections:    3,
+		InUse:              2,
+		Idle:               1,
+		WaitCount:          4,
+		WaitDuration:       1500 * time.Millisecond,
+	}))
+
+	result := self.write()
+
+	self.Contains(result, "# TYPE test_db_max_open_connections gauge\ntest_db_max_open_connections 10\n")
+	self.Contains(result, "test_db_open_connections 3\n")
+	self.Contains(result, "test_db_in_use_connections 2\n")
+	self.Contains(result, "test_db_idle_connections 1\n")
+	self.Contains(result, "# TYPE test_db_wait_count_total counter\ntest_db_wait_count_total 4\n")
+	self.Contains(result, "test_db_wait_duration_seconds_total 1.5\n")
+}
+
+func (self *MetricsTests) TestServeHTTP() {
+	self.registry.Register(NewGaugeFunc("test_value", "", func() float64 { return 1 }))
+	response := httptest.NewRecorder()
+
+	self.registry.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal(ContentType, response.Header().Get("Content-Type"))
+	self.Equal("# TYPE test_value gauge\ntest_value 1\n", response.Body.String())
+}
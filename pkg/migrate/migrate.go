@@ -0,0 +1,356 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Key of the advisory lock held while migrations run, so concurrent runs wait for each other.
+const lockKey = 7_281_430_115
+
+// Query template to create table of applied migrations.
+var createMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint NOT NULL,
+    name varchar(255) NOT NULL,
+    checksum char(64) NOT NULL,
+    applied_at timestamptz NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (version)
+)`
+
+// Query template to create table of applied migrations in SQLite, the time of the migration
+// is declared as TIMESTAMP to be scanned into time.Time.
+var createSQLiteMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (version)
+)`
+
+// Query template to acquire the migrations lock.
+var lockQuery = `SELECT pg_advisory_lock(?)`
+
+// Query template to release the migrations lock.
+var unlockQuery = `SELECT pg_advisory_unlock(?)`
+
+// Query template to get applied migrations.
+var getAppliedMigrationsQuery = `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`
+
+// Query template to record applied migration.
+var insertMigrationQuery = `INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)`
+
+// Query template to remove reverted migration.
+var deleteMigrationQuery = `DELETE FROM schema_migrations WHERE version=?`
+
+// Migration files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+var migrationFileMatcher = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var (
+	ErrNothingToRevert  = errors.New("no applied migrations to revert")
+	ErrChecksumMismatch = errors.New("checksum of applied migration does not match its file")
+	ErrUnknownMigration = errors.New("applied migration has no file")
+)
+
+// dialect holds the statements differing between the databases.
+type dialect struct {
+	createMigrationsTableQuery string
+	// lockQuery and unlockQuery are empty if the database has no advisory locks.
+	lockQuery   string
+	unlockQuery string
+}
+
+var postgresDialect = dialect{
+	createMigrationsTableQuery: createMigrationsTableQuery,
+	lockQuery:                  lockQuery,
+	unlockQuery:                unlockQuery,
+}
+
+// SQLite has no advisory locks, the concurrent runs are serialized by the lock of the database file
+// held by the transaction of every migration.
+var sqliteDialect = dialect{
+	createMigrationsTableQuery: createSQLiteMigrationsTableQuery,
+}
+
+// Dialects by the name of the driver, PostgreSQL is assumed for the others.
+var dialects = map[string]dialect{
+	"sqlite": sqliteDialect,
+}
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of the up script, it is verified for every applied migration.
+	Checksum string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+// NewMigrator reads migrations from the root of files ordered by version,
+// the dialect of the statements is chosen by the driver of the database.
+func NewMigrator(db *sqlx.DB, files fs.FS) (*Migrator, error) {
+	migrations, err := readMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+	dialect := postgresDialect
+	if db != nil {
+		if driverDialect, ok := dialects[db.DriverName()]; ok {
+			dialect = driverDialect
+		}
+	}
+
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies all pending migrations and returns them.
+func (self *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := self.withLock(ctx, func(conn *sqlx.Conn) error {
+		appliedMigrations, err := self.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range self.migrations {
+			if _, ok := appliedMigrations[migration.Version]; ok {
+				continue
+			}
+			err = self.inTransaction(ctx, conn, func(tx *sqlx.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, self.db.Rebind(insertMigrationQuery),
+					migration.Version, migration.Name, migration.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last applied migration and returns it.
+func (self *Migrator) Down(ctx context.Context) (Migration, error) {
+	var reverted Migration
+	err := self.withLock(ctx, func(conn *sqlx.Conn) error {
+		appliedMigrations, err := self.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for index := len(self.migrations) - 1; index >= 0; index-- {
+			migration := self.migrations[index]
+			if _, ok := appliedMigrations[migration.Version]; !ok {
+				continue
+			}
+			err = self.inTransaction(ctx, conn, func(tx *sqlx.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, self.db.Rebind(deleteMigrationQuery), migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = migration
+			return nil
+		}
+		return ErrNothingToRevert
+	})
+
+	return reverted, err
+}
+
+// Status returns all known migrations and whether they are applied.
+func (self *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := self.withLock(ctx, func(conn *sqlx.Conn) error {
+		appliedMigrations, err := self.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range self.migrations {
+			applied, ok := appliedMigrations[migration.Version]
+			statuses = append(statuses, Status{
+				Migration: migration,
+				Applied:   ok,
+				AppliedAt: applied.AppliedAt,
+			})
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock runs task on the single connection holding the advisory lock if the database supports it.
+func (self *Migrator) withLock(ctx context.Context, task func(conn *sqlx.Conn) error) (err error) {
+	conn, err := self.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if self.dialect.lockQuery != "" {
+		if _, err = conn.ExecContext(ctx, self.db.Rebind(self.dialect.lockQuery), lockKey); err != nil {
+			return fmt.Errorf("failed to acquire migrations lock: %w", err)
+		}
+		defer func() {
+			// The lock is released with the session anyway, so the context cancellation is not propagated.
+			_, unlockErr := conn.ExecContext(context.Background(), self.db.Rebind(self.dialect.unlockQuery), lockKey)
+			if unlockErr != nil && err == nil {
+				err = fmt.Errorf("failed to release migrations lock: %w", unlockErr)
+			}
+		}()
+	}
+
+	if _, err = conn.ExecContext(ctx, self.dialect.createMigrationsTableQuery); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	return task(conn)
+}
+
+// verify returns the applied migrations and checks they match the migration files.
+func (self *Migrator) verify(ctx context.Context, conn *sqlx.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryxContext(ctx, getAppliedMigrationsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	appliedMigrations := map[int64]appliedMigration{}
+	for rows.Next() {
+		var applied appliedMigration
+		if err = rows.StructScan(&applied); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		appliedMigrations[applied.Version] = applied
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	known := map[int64]Migration{}
+	for _, migration := range self.migrations {
+		known[migration.Version] = migration
+	}
+	for version, applied := range appliedMigrations {
+		migration, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d_%s", ErrUnknownMigration, version, applied.Name)
+		}
+		if migration.Checksum != applied.Checksum {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, applied.Name)
+		}
+	}
+
+	return appliedMigrations, nil
+}
+
+func (self *Migrator) inTransaction(ctx context.Context, conn *sqlx.Conn, task func(tx *sqlx.Tx) error) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = task(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func readMigrations(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	migrationsByVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		matches := migrationFileMatcher.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := migrationsByVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			migrationsByVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			checksum := sha256.Sum256(content)
+			migration.Up = string(content)
+			migration.Checksum = hex.EncodeToString(checksum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(migrationsByVersion))
+	for _, migration := range migrationsByVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
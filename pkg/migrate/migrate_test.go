@@ -0,0 +1,222 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	lockQueryMatcher                  = regexp.QuoteMeta(`SELECT pg_advisory_lock(?)`)
+	unlockQueryMatcher                = regexp.QuoteMeta(`SELECT pg_advisory_unlock(?)`)
+	createMigrationsTableQueryMatcher = regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)
+	getAppliedMigrationsQueryMatcher  = regexp.QuoteMeta(`SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	insertMigrationQueryMatcher       = regexp.QuoteMeta(`INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)`)
+	deleteMigrationQueryMatcher       = regexp.QuoteMeta(`DELETE FROM schema_migrations WHERE version=?`)
+)
+
+type MigratorTests struct {
+	suite.Suite
+	migrator *Migrator
+	context  context.Context
+	sqlMock  sqlmock.Sqlmock
+
+	testError error
+	files     fstest.MapFS
+	appliedAt time.Time
+}
+
+func TestMigrator(t *testing.T) {
+	suite.Run(t, new(MigratorTests))
+}
+
+func (self *MigratorTests) SetupTest() {
+	mockDatabaseConnection, sqlMock, err := sqlmock.New()
+	self.Require().Nil(err)
+
+	self.files = fstest.MapFS{
+		"0002_books.down.sql":   {Data: []byte("DROP TABLE books")},
+		"0002_books.up.sql":     {Data: []byte("CREATE TABLE books ()")},
+		"0001_authors.up.sql":   {Data: []byte("CREATE TABLE authors ()")},
+		"0001_authors.down.sql": {Data: []byte("DROP TABLE authors")},
+		"README.md":             {Data: []byte("not a migration")},
+	}
+	self.migrator, err = NewMigrator(sqlx.NewDb(mockDatabaseConnection, "sqlmock"), self.files)
+	self.Require().Nil(err)
+	self.sqlMock = sqlMock
+	self.context = context.Background()
+	self.testError = errors.New("test_error")
+	self.appliedAt = time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (self *MigratorTests) TearDownTest() {
+	self.NoError(self.sqlMock.ExpectationsWereMet())
+}
+
+func (self *MigratorTests) checksum(content string) string {
+	checksum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(checksum[:])
+}
+
+func (self *MigratorTests) expectLockAndApplied(rows *sqlmock.Rows) {
+	self.sqlMock.ExpectExec(lockQueryMatcher).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	self.sqlMock.ExpectExec(createMigrationsTableQueryMatcher).WillReturnResult(sqlmock.NewResult(0, 0))
+	self.sqlMock.ExpectQuery(getAppliedMigrationsQueryMatcher).WillReturnRows(rows)
+}
+
+func (self *MigratorTests) expectUnlock() {
+	self.sqlMock.ExpectExec(unlockQueryMatcher).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (self *MigratorTests) appliedRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"})
+}
+
+func (self *MigratorTests) TestNewMigratorOrdersMigrations() {
+	self.Require().Len(self.migrator.migrations, 2)
+	self.Equal(Migration{
+		Version:  1,
+		Name:     "authors",
+		Up:       "CREATE TABLE authors ()",
+		Down:     "DROP TABLE authors",
+		Checksum: self.checksum("CREATE TABLE authors ()"),
+	}, self.migrator.migrations[0])
+	self.Equal(int64(2), self.migrator.migrations[1].Version)
+}
+
+func (self *MigratorTests) TestNewMigratorMissingDownFile() {
+	delete(self.files, "0002_books.down.sql")
+
+	_, err := NewMigrator(nil, self.files)
+
+	self.EqualError(err, "migration 2_books must have both up and down files")
+}
+
+func (self *MigratorTests) TestNewMigratorDuplicateVersion() {
+	self.files["0002_other.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1")}
+
+	_, err := NewMigrator(nil, self.files)
+
+	self.Error(err)
+}
+
+func (self *MigratorTests) TestUp() {
+	self.expectLockAndApplied(self.appliedRows().
+		AddRow(1, "authors", self.checksum("CREATE TABLE authors ()"), self.appliedAt))
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.ExpectExec(regexp.QuoteMeta("CREATE TABLE books ()")).WillReturnResult(sqlmock.NewResult(0, 0))
+	self.sqlMock.ExpectExec(insertMigrationQueryMatcher).
+		WithArgs(int64(2), "books", self.checksum("CREATE TABLE books ()")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	self.sqlMock.ExpectCommit()
+	self.expectUnlock()
+
+	result, err := self.migrator.Up(self.context)
+
+	self.NoError(err)
+	self.Require().Len(result, 1)
+	self.Equal(int64(2), result[0].Version)
+}
+
+func (self *MigratorTests) TestUpWithoutLockInSQLite() {
+	migrator, err := NewMigrator(sqlx.NewDb(self.migrator.db.DB, "sqlite"), self.files)
+	self.Require().NoError(err)
+	self.sqlMock.ExpectExec(regexp.QuoteMeta(createSQLiteMigrationsTableQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+	self.sqlMock.ExpectQuery(getAppliedMigrationsQueryMatcher).WillReturnRows(self.appliedRows().
+		AddRow(1, "authors", self.checksum("CREATE TABLE authors ()"), self.appliedAt).
+		AddRow(2, "books", self.checksum("CREATE TABLE books ()"), self.appliedAt))
+
+	result, err := migrator.Up(self.context)
+
+	self.NoError(err)
+	self.Empty(result)
+}
+
+func (self *MigratorTests) TestUpRollsBackFailedMigration() {
+	self.expectLockAndApplied(self.appliedRows())
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.ExpectExec(regexp.QuoteMeta("CREATE TABLE authors ()")).WillReturnError(self.testError)
+	self.sqlMock.ExpectRollback()
+	self.expectUnlock()
+
+	result, err := self.migrator.Up(self.context)
+
+	self.ErrorIs(err, self.testError)
+	self.Empty(result)
+}
+
+func (self *MigratorTests) TestUpChecksumMismatch() {
+	self.expectLockAndApplied(self.appliedRows().
+		AddRow(1, "authors", self.checksum("CREATE TABLE changed ()"), self.appliedAt))
+	self.expectUnlock()
+
+	_, err := self.migrator.Up(self.context)
+
+	self.ErrorIs(err, ErrChecksumMismatch)
+}
+
+func (self *MigratorTests) TestUpUnknownMigration() {
+	self.expectLockAndApplied(self.appliedRows().
+		AddRow(3, "removed", self.checksum("SELECT 1"), self.appliedAt))
+	self.expectUnlock()
+
+	_, err := self.migrator.Up(self.context)
+
+	self.ErrorIs(err, ErrUnknownMigration)
+}
+
+func (self *MigratorTests) TestUpLockError() {
+	self.sqlMock.ExpectExec(lockQueryMatcher).WithArgs(lockKey).WillReturnError(self.testError)
+
+	_, err := self.migrator.Up(self.context)
+
+	self.ErrorIs(err, self.testError)
+}
+
+func (self *MigratorTests) TestDown() {
+	self.expectLockAndApplied(self.appliedRows().
+		AddRow(1, "authors", self.checksum("CREATE TABLE authors ()"), self.appliedAt).
+		AddRow(2, "books", self.checksum("CREATE TABLE books ()"), self.appliedAt))
+	self.sqlMock.ExpectBegin()
+	self.sqlMock.ExpectExec(regexp.QuoteMeta("DROP TABLE books")).WillReturnResult(sqlmock.NewResult(0, 0))
+	self.sqlMock.ExpectExec(deleteMigrationQueryMatcher).WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	self.sqlMock.ExpectCommit()
+	self.expectUnlock()
+
+	result, err := self.migrator.Down(self.context)
+
+	self.NoError(err)
+	self.Equal(int64(2), result.Version)
+}
+
+func (self *MigratorTests) TestDownNothingToRevert() {
+	self.expectLockAndApplied(self.appliedRows())
+	self.expectUnlock()
+
+	_, err := self.migrator.Down(self.context)
+
+	self.ErrorIs(err, ErrNothingToRevert)
+}
+
+func (self *MigratorTests) TestStatus() {
+	self.expectLockAndApplied(self.appliedRows().
+		AddRow(1, "authors", self.checksum("CREATE TABLE authors ()"), self.appliedAt))
+	self.expectUnlock()
+
+	result, err := self.migrator.Status(self.context)
+
+	self.NoError(err)
+	self.Require().Len(result, 2)
+	self.True(result[0].Applied)
+	self.Equal(self.appliedAt, result[0].AppliedAt)
+	self.False(result[1].Applied)
+}
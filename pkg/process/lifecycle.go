@@ -0,0 +1,139 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Exit codes of the process.
+const (
+	ExitCodeOK = 0
+	// ExitCodeFailure is returned if a component failed to run or to stop.
+	ExitCodeFailure = 1
+	// ExitCodeShutdownTimeout is returned if the components did not stop within the shutdown timeout.
+	ExitCodeShutdownTimeout = 3
+)
+
+// ErrShutdownTimeout is returned by Lifecycle.Run if the components did not stop within the shutdown timeout.
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// Component is the part of the application run and stopped by the lifecycle.
+type Component struct {
+	Name string
+	// Run blocks until the component is stopped, it is optional for the components which only need to be stopped.
+	// Run returning before the shutdown stops the whole application.
+	Run func() error
+	// Stop stops the component, it must return once the context is done.
+	Stop func(ctx context.Context) error
+}
+
+// Lifecycle runs the components until the termination and stops them in the reverse order.
+type Lifecycle struct {
+	logger          logrus.FieldLogger
+	shutdownTimeout time.Duration
+	components      []Component
+}
+
+func NewLifecycle(logger logrus.FieldLogger, shutdownTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{
+		logger:          logger,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+// Add adds the component, it is stopped before the components added earlier.
+func (self *Lifecycle) Add(component Component) {
+	self.components = append(self.components, component)
+}
+
+// Run runs the components until the context is done or any of them stops, then stops all of them
+// within the shutdown timeout.
+func (self *Lifecycle) Run(ctx context.Context) error {
+	stopped := make(chan error, len(self.components))
+	for _, component := range self.components {
+		if component.Run == nil {
+			continue
+		}
+		go func(component Component) {
+			if err := component.Run(); err != nil {
+				stopped <- fmt.Errorf("%s failed: %w", component.Name, err)
+				return
+			}
+			stopped <- fmt.Errorf("%s stopped unexpectedly", component.Name)
+		}(component)
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		self.logger.Info("shutting down")
+	case runErr = <-stopped:
+		self.logger.
+			WithError(runErr).
+			Error("component stopped, shutting down")
+	}
+
+	if err := self.stop(); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// stop stops the components in the reverse order, the components left when the timeout expires
+// are stopped with the done context.
+func (self *Lifecycle) stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), self.shutdownTimeout)
+	defer cancel()
+
+	var stopErr error
+	for index := len(self.components) - 1; index >= 0; index-- {
+		component := self.components[index]
+		if component.Stop == nil {
+			continue
+		}
+
+		if err := component.Stop(ctx); err != nil {
+			self.logger.
+				WithField("component", component.Name).
+				WithError(err).
+				Error("failed to stop component")
+			if stopErr == nil {
+				stopErr = fmt.Errorf("failed to stop %s: %w", component.Name, err)
+			}
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrShutdownTimeout, self.shutdownTimeout)
+	}
+	return stopErr
+}
+
+// ExitCode returns the exit code of the process for the error returned by Lifecycle.Run.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitCodeOK
+	case errors.Is(err, ErrShutdownTimeout):
+		return ExitCodeShutdownTimeout
+	default:
+		return ExitCodeFailure
+	}
+}
+
+// Sleep waits for the duration or until the context is done.
+func Sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,123 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type LifecycleTests struct {
+	suite.Suite
+	lifecycle *Lifecycle
+	testError error
+	calls     []string
+}
+
+func TestLifecycle(t *testing.T) {
+	suite.Run(t, new(LifecycleTests))
+}
+
+func (self *LifecycleTests) SetupTest() {
+	logger, _ := logrustest.NewNullLogger()
+	self.lifecycle = NewLifecycle(logger, 50*time.Millisecond)
+	self.testError = errors.New("test_error")
+	self.calls = nil
+}
+
+// component returns the component recording its stop and running until it is stopped.
+func (self *LifecycleTests) component(name string) Component {
+	done := make(chan struct{})
+	return Component{
+		Name: name,
+		Run: func() error {
+			<-done
+			return nil
+		},
+		Stop: func(context.Context) error {
+			self.calls = append(self.calls, name)
+			close(done)
+			return nil
+		},
+	}
+}
+
+func (self *LifecycleTests) TestRunStopsComponentsInReverseOrder() {
+	ctx, cancel := context.WithCancel(context.Background())
+	self.lifecycle.Add(self.component("database"))
+	self.lifecycle.Add(Component{Name: "closer", Stop: func(context.Context) error {
+		self.calls = append(self.calls, "closer")
+		return nil
+	}})
+	self.lifecycle.Add(self.component("server"))
+	cancel()
+
+	err := self.lifecycle.Run(ctx)
+
+	self.NoError(err)
+	self.Equal([]string{"server", "closer", "database"}, self.calls)
+}
+
+func (self *LifecycleTests) TestRunErrorIfComponentFailed() {
+	self.lifecycle.Add(self.component("database"))
+	self.lifecycle.Add(Component{Name: "server", Run: func() error { return self.testError }})
+
+	err := self.lifecycle.Run(context.Background())
+
+	self.ErrorIs(err, self.testError)
+	self.Equal(ExitCodeFailure, ExitCode(err))
+	self.Equal([]string{"database"}, self.calls)
+}
+
+func (self *LifecycleTests) TestRunErrorIfComponentStoppedUnexpectedly() {
+	self.lifecycle.Add(Component{Name: "server", Run: func() error { return nil }})
+
+	err := self.lifecycle.Run(context.Background())
+
+	self.EqualError(err, "server stopped unexpectedly")
+}
+
+func (self *LifecycleTests) TestRunErrorIfStopFailed() {
+	ctx, cancel := context.WithCancel(context.Background())
+	self.lifecycle.Add(self.component("database"))
+	self.lifecycle.Add(Component{Name: "server", Stop: func(context.Context) error { return self.testError }})
+	cancel()
+
+	err := self.lifecycle.Run(ctx)
+
+	self.ErrorIs(err, self.testError)
+	self.Equal([]string{"database"}, self.calls)
+}
+
+func (self *LifecycleTests) TestRunErrorIfShutdownTimedOut() {
+	ctx, cancel := context.WithCancel(context.Background())
+	self.lifecycle.Add(self.component("database"))
+	self.lifecycle.Add(Component{Name: "server", Stop: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	cancel()
+
+	err := self.lifecycle.Run(ctx)
+
+	self.ErrorIs(err, ErrShutdownTimeout)
+	self.Equal(ExitCodeShutdownTimeout, ExitCode(err))
+	self.Equal([]string{"database"}, self.calls)
+}
+
+func (self *LifecycleTests) TestExitCode() {
+	self.Equal(ExitCodeOK, ExitCode(nil))
+	self.Equal(ExitCodeFailure, ExitCode(self.testError))
+	self.Equal(ExitCodeShutdownTimeout, ExitCode(ErrShutdownTimeout))
+}
+
+func (self *LifecycleTests) TestSleep() {
+	self.NoError(Sleep(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	self.ErrorIs(Sleep(ctx, time.Hour), context.Canceled)
+}
@@ -1,13 +1,18 @@
 package process
 
 import (
-	"os"
+	"context"
 	"os/signal"
 	"syscall"
 )
 
 func WaitForTermination() {
-	stopSignal := make(chan os.Signal, 1)
-	signal.Notify(stopSignal, syscall.SIGTERM, syscall.SIGINT)
-	<-stopSignal
+	ctx, stop := TerminationContext(context.Background())
+	defer stop()
+	<-ctx.Done()
+}
+
+// TerminationContext returns the context done when the process receives SIGTERM or SIGINT.
+func TerminationContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, syscall.SIGTERM, syscall.SIGINT)
 }
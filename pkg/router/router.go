@@ -0,0 +1,308 @@
+// Package router dispatches requests by method and path pattern.
+//
+// Patterns are made of slash separated segments. A segment is either static, such as `books` or
+// `books:batch`, or a named parameter, such as `{id}`, optionally followed by a static suffix,
+// such as `{id}:restore`. Static segments take priority over parameters.
+package router
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Middleware wraps the handler.
+type Middleware func(next http.Handler) http.Handler
+
+// RouteMiddleware returns the middleware of the route registered with the pattern, the pattern is empty
+// for requests not matching any route.
+type RouteMiddleware func(pattern string) Middleware
+
+// Param is the value of the named parameter of the matched pattern.
+type Param struct {
+	Name  string
+	Value string
+}
+
+type Params []Param
+
+// Get returns the value of the parameter, it is empty if there is no parameter with the name.
+func (self Params) Get(name string) string {
+	for _, param := range self {
+		if param.Name == name {
+			return param.Value
+		}
+	}
+	return ""
+}
+
+type paramsKey struct{}
+
+// WithParams returns the request with the parameters, it is used by the router and lets the handlers
+// be called directly.
+func WithParams(request *http.Request, params Params) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), paramsKey{}, params))
+}
+
+// PathValue returns the value of the named parameter of the pattern matched by the request.
+func PathValue(request *http.Request, name string) string {
+	params, _ := request.Context().Value(paramsKey{}).(Params)
+	return params.Get(name)
+}
+
+type Router struct {
+	root             *node
+	middlewares      []Middleware
+	routeMiddlewares []RouteMiddleware
+
+	// NotFound handles requests not matching any pattern.
+	NotFound http.Handler
+	// MethodNotAllowed handles requests matching the pattern registered for other methods,
+	// the Allow header is already set.
+	MethodNotAllowed http.Handler
+}
+
+func New() *Router {
+	return &Router{
+		root:             newNode(),
+		NotFound:         http.NotFoundHandler(),
+		MethodNotAllowed: http.HandlerFunc(methodNotAllowed),
+	}
+}
+
+// Use adds the middlewares run for every request including the not found and not allowed ones.
+func (self *Router) Use(middlewares ...Middleware) {
+	self.middlewares = append(self.middlewares, middlewares...)
+}
+
+// UseRoute adds the middlewares run after the request is matched to the route, they run for the not found
+// and not allowed requests as well.
+func (self *Router) UseRoute(middlewares ...RouteMiddleware) {
+	self.routeMiddlewares = append(self.routeMiddlewares, middlewares...)
+}
+
+// Group returns the group of routes sharing the path prefix and the middlewares.
+func (self *Router) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{router: self, prefix: normalize(prefix), middlewares: middlewares}
+}
+
+func (self *Router) Handle(method string, pattern string, handler http.Handler) {
+	path := normalize(pattern)
+	if path == "" {
+		pattern = "/"
+	} else {
+		pattern = path
+	}
+	self.root.insert(segments(path), pattern, method, handler)
+}
+
+func (self *Router) HandleFunc(method string, pattern string, handler http.HandlerFunc) {
+	self.Handle(method, pattern, handler)
+}
+
+func (self *Router) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	var handler http.Handler = http.HandlerFunc(self.dispatch)
+	for index := len(self.middlewares) - 1; index >= 0; index-- {
+		handler = self.middlewares[index](handler)
+	}
+	handler.ServeHTTP(response, request)
+}
+
+func (self *Router) dispatch(response http.ResponseWriter, request *http.Request) {
+	var handler http.Handler
+	var pattern string
+	node, params := self.root.match(segments(normalize(request.URL.Path)), nil)
+	if node == nil || len(node.handlers) == 0 {
+		handler = self.NotFound
+	} else {
+		pattern = node.pattern
+		handler = node.handler(request.Method)
+		if handler == nil {
+			handler = self.notAllowed(node)
+		}
+	}
+
+	for index := len(self.routeMiddlewares) - 1; index >= 0; index-- {
+		handler = self.routeMiddlewares[index](pattern)(handler)
+	}
+	handler.ServeHTTP(response, WithParams(request, params))
+}
+
+// notAllowed returns the handler of the requests matching the node with the method it has no handler for.
+func (self *Router) notAllowed(node *node) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.Header().Set("Allow", node.allow())
+		if request.Method == http.MethodOptions {
+			response.WriteHeader(http.StatusNoContent)
+			return
+		}
+		self.MethodNotAllowed.ServeHTTP(response, request)
+	})
+}
+
+type Group struct {
+	router      *Router
+	prefix      string
+	middlewares []Middleware
+}
+
+// Group returns the nested group, its middlewares run after the middlewares of the parent group.
+func (self *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		router:      self.router,
+		prefix:      self.prefix + normalize(prefix),
+		middlewares: append(append([]Middleware{}, self.middlewares...), middlewares...),
+	}
+}
+
+// Use adds the middlewares to the routes registered afterwards.
+func (self *Group) Use(middlewares ...Middleware) {
+	self.middlewares = append(self.middlewares, middlewares...)
+}
+
+func (self *Group) Handle(method string, pattern string, handler http.Handler) {
+	for index := len(self.middlewares) - 1; index >= 0; index-- {
+		handler = self.middlewares[index](handler)
+	}
+	self.router.Handle(method, self.prefix+normalize(pattern), handler)
+}
+
+func (self *Group) HandleFunc(method string, pattern string, handler http.HandlerFunc) {
+	self.Handle(method, pattern, handler)
+}
+
+type node struct {
+	static   map[string]*node
+	params   []*paramNode
+	handlers map[string]http.Handler
+	// pattern is the pattern of the route the handlers are registered with.
+	pattern string
+}
+
+// paramNode is the child matching any segment ending with the suffix.
+type paramNode struct {
+	name   string
+	suffix string
+	node   *node
+}
+
+func newNode() *node {
+	return &node{static: map[string]*node{}, handlers: map[string]http.Handler{}}
+}
+
+func (self *node) insert(segments []string, pattern string, method string, handler http.Handler) {
+	if len(segments) == 0 {
+		if _, ok := self.handlers[method]; ok {
+			panic("router: handler of " + method + " " + pattern + " is already registered")
+		}
+		self.handlers[method] = handler
+		self.pattern = pattern
+		return
+	}
+
+	segment := segments[0]
+	if !strings.HasPrefix(segment, "{") {
+		child, ok := self.static[segment]
+		if !ok {
+			child = newNode()
+			self.static[segment] = child
+		}
+		child.insert(segments[1:], pattern, method, handler)
+		return
+	}
+
+	end := strings.Index(segment, "}")
+	if end < 2 {
+		panic("router: invalid parameter segment " + segment)
+	}
+	name, suffix := segment[1:end], segment[end+1:]
+	for _, param := range self.params {
+		if param.suffix == suffix {
+			if param.name != name {
+				panic("router: parameter " + name + " conflicts with " + param.name)
+			}
+			param.node.insert(segments[1:], pattern, method, handler)
+			return
+		}
+	}
+	param := &paramNode{name: name, suffix: suffix, node: newNode()}
+	self.params = append(self.params, param)
+	// Longer suffixes are more specific, so they are matched first.
+	sort.SliceStable(self.params, func(i, j int) bool {
+		return len(self.params[i].suffix) > len(self.params[j].suffix)
+	})
+	param.node.insert(segments[1:], pattern, method, handler)
+}
+
+// match returns the node of the pattern matching the segments, it backtracks to the parameters
+// if the static segment leads to no route.
+func (self *node) match(segments []string, params Params) (*node, Params) {
+	if len(segments) == 0 {
+		return self, params
+	}
+
+	segment := segments[0]
+	if child, ok := self.static[segment]; ok {
+		if node, matched := child.match(segments[1:], params); node != nil && len(node.handlers) > 0 {
+			return node, matched
+		}
+	}
+	for _, param := range self.params {
+		value := strings.TrimSuffix(segment, param.suffix)
+		if !strings.HasSuffix(segment, param.suffix) || value == "" {
+			continue
+		}
+		matched := append(params[:len(params):len(params)], Param{Name: param.name, Value: value})
+		if node, matched := param.node.match(segments[1:], matched); node != nil && len(node.handlers) > 0 {
+			return node, matched
+		}
+	}
+
+	return nil, nil
+}
+
+// handler returns the handler of the method, HEAD requests are handled by GET handler if there is no HEAD one.
+func (self *node) handler(method string) http.Handler {
+	handler, ok := self.handlers[method]
+	if !ok && method == http.MethodHead {
+		handler = self.handlers[http.MethodGet]
+	}
+	return handler
+}
+
+// allow returns the value of the Allow header listing the methods of the node.
+func (self *node) allow() string {
+	methods := []string{http.MethodOptions}
+	for method := range self.handlers {
+		methods = append(methods, method)
+	}
+	if _, ok := self.handlers[http.MethodGet]; ok {
+		if _, ok = self.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+
+	return strings.Join(methods, ", ")
+}
+
+// normalize returns the path with the leading slash and without the trailing one.
+func normalize(path string) string {
+	path = "/" + strings.Trim(path, "/")
+	if path == "/" {
+		return ""
+	}
+	return path
+}
+
+func segments(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path[1:], "/")
+}
+
+func methodNotAllowed(response http.ResponseWriter, _ *http.Request) {
+	http.Error(response, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,170 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RouterTests struct {
+	suite.Suite
+	router *Router
+}
+
+func TestRouter(t *testing.T) {
+	suite.Run(t, new(RouterTests))
+}
+
+// respond returns the handler writing the name of the route and its parameters.
+func respond(name string, params ...string) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		_, _ = fmt.Fprint(response, name)
+		for _, param := range params {
+			_, _ = fmt.Fprintf(response, " %s=%s", param, PathValue(request, param))
+		}
+	}
+}
+
+func (self *RouterTests) SetupTest() {
+	self.router = New()
+	self.router.HandleFunc(http.MethodGet, "/api/books", respond("list"))
+	self.router.HandleFunc(http.MethodPost, "/api/books", respond("create"))
+	self.router.HandleFunc(http.MethodPost, "/api/books:batch", respond("batch"))
+	self.router.HandleFunc(http.MethodGet, "/api/books/isbn/{isbn}", respond("isbn", "isbn"))
+	self.router.HandleFunc(http.MethodGet, "/api/books/{id}", respond("get", "id"))
+	self.router.HandleFunc(http.MethodDelete, "/api/books/{id}", respond("delete", "id"))
+	self.router.HandleFunc(http.MethodPost, "/api/books/{id}:restore", respond("restore", "id"))
+	self.router.HandleFunc(http.MethodGet, "/api/authors/{id}/books", respond("books", "id"))
+}
+
+func (self *RouterTests) serve(method string, path string) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	self.router.ServeHTTP(response, httptest.NewRequest(method, path, nil))
+	return response
+}
+
+func (self *RouterTests) TestServeHTTPStaticRoute() {
+	self.Equal("list", self.serve(http.MethodGet, "/api/books").Body.String())
+	self.Equal("create", self.serve(http.MethodPost, "/api/books").Body.String())
+	self.Equal("batch", self.serve(http.MethodPost, "/api/books:batch").Body.String())
+}
+
+func (self *RouterTests) TestServeHTTPParams() {
+	self.Equal("get id=42", self.serve(http.MethodGet, "/api/books/42").Body.String())
+	self.Equal("books id=7", self.serve(http.MethodGet, "/api/authors/7/books").Body.String())
+	self.Equal("restore id=42", self.serve(http.MethodPost, "/api/books/42:restore").Body.String())
+}
+
+func (self *RouterTests) TestServeHTTPStaticTakesPriorityOverParam() {
+	self.Equal("isbn isbn=123", self.serve(http.MethodGet, "/api/books/isbn/123").Body.String())
+}
+
+func (self *RouterTests) TestServeHTTPBacktracksToParam() {
+	self.Equal("get id=isbn", self.serve(http.MethodGet, "/api/books/isbn").Body.String())
+}
+
+func (self *RouterTests) TestServeHTTPTrailingSlash() {
+	self.Equal("list", self.serve(http.MethodGet, "/api/books/").Body.String())
+	self.Equal("books id=7", self.serve(http.MethodGet, "/api/authors/7/books/").Body.String())
+}
+
+func (self *RouterTests) TestServeHTTPNotFound() {
+	self.Equal(http.StatusNotFound, self.serve(http.MethodGet, "/api/unknown").Code)
+	self.Equal(http.StatusNotFound, self.serve(http.MethodGet, "/api/books/42/unknown").Code)
+	self.Equal(http.StatusNotFound, self.serve(http.MethodGet, "/api/authors/7").Code)
+}
+
+func (self *RouterTests) TestServeHTTPMethodNotAllowed() {
+	response := self.serve(http.MethodPut, "/api/books/42")
+
+	self.Equal(http.StatusMethodNotAllowed, response.Code)
+	self.Equal("DELETE, GET, HEAD, OPTIONS", response.Header().Get("Allow"))
+}
+
+func (self *RouterTests) TestServeHTTPCustomMethodNotAllowed() {
+	self.router.MethodNotAllowed = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusTeapot)
+	})
+
+	response := self.serve(http.MethodPatch, "/api/books")
+
+	self.Equal(http.StatusTeapot, response.Code)
+	self.Equal("GET, HEAD, OPTIONS, POST", response.Header().Get("Allow"))
+}
+
+func (self *RouterTests) TestServeHTTPOptions() {
+	response := self.serve(http.MethodOptions, "/api/books:batch")
+
+	self.Equal(http.StatusNoContent, response.Code)
+	self.Equal("OPTIONS, POST", response.Header().Get("Allow"))
+}
+
+func (self *RouterTests) TestServeHTTPHeadUsesGet() {
+	response := self.serve(http.MethodHead, "/api/books/42")
+
+	self.Equal(http.StatusOK, response.Code)
+	self.Equal("get id=42", response.Body.String())
+}
+
+func (self *RouterTests) TestServeHTTPMiddlewares() {
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(response, request)
+			})
+		}
+	}
+	self.router.Use(middleware("router"))
+	group := self.router.Group("/api/authors", middleware("group"))
+	group.Group("/{id}", middleware("nested")).HandleFunc(http.MethodGet, "", respond("author", "id"))
+
+	response := self.serve(http.MethodGet, "/api/authors/7")
+	self.serve(http.MethodGet, "/api/books")
+
+	self.Equal("author id=7", response.Body.String())
+	self.Equal([]string{"router", "group", "nested", "router"}, calls)
+}
+
+func (self *RouterTests) TestHandlePanicsIfRouteIsRegistered() {
+	self.Panics(func() {
+		self.router.HandleFunc(http.MethodGet, "/api/books/", respond("list"))
+	})
+}
+
+func (self *RouterTests) TestHandlePanicsIfParamNameConflicts() {
+	self.Panics(func() {
+		self.router.HandleFunc(http.MethodPut, "/api/books/{bookId}", respond("update"))
+	})
+}
+
+func (self *RouterTests) TestWithParams() {
+	request := WithParams(httptest.NewRequest(http.MethodGet, "/", nil), Params{{Name: "id", Value: "42"}})
+
+	self.Equal("42", PathValue(request, "id"))
+	self.Equal("", PathValue(request, "unknown"))
+}
+
+func (self *RouterTests) TestServeHTTPRouteMiddlewares() {
+	var patterns []string
+	self.router.UseRoute(func(pattern string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+				patterns = append(patterns, pattern)
+				next.ServeHTTP(response, request)
+			})
+		}
+	})
+	self.router.HandleFunc(http.MethodGet, "/", respond("root"))
+
+	self.serve(http.MethodGet, "/api/books/42/")
+	self.serve(http.MethodPut, "/api/books/42")
+	self.serve(http.MethodGet, "/api/unknown")
+	self.serve(http.MethodGet, "/")
+
+	self.Equal([]string{"/api/books/{id}", "/api/books/{id}", "", "/"}, patterns)
+}
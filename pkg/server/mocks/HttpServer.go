@@ -13,6 +13,20 @@ type HttpServer struct {
 	mock.Mock
 }
 
+// Close provides a mock function with given fields:
+func (_m *HttpServer) Close() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ListenAndServe provides a mock function with given fields:
 func (_m *HttpServer) ListenAndServe() error {
 	ret := _m.Called()
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 )
@@ -10,6 +11,7 @@ import (
 type HttpServer interface {
 	ListenAndServe() error
 	Shutdown(ctx context.Context) error
+	Close() error
 }
 
 type Server struct {
@@ -29,10 +31,19 @@ func NewServer(addr string, handler http.Handler) *Server {
 	}
 }
 
+// Listen serves the requests until the server is shut down, it returns nil after the shutdown.
 func (s *Server) Listen() error {
-	return s.httpServer.ListenAndServe()
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
+// Shutdown waits for the active requests to finish, the connections left when the context is done are closed.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	if ctx.Err() != nil {
+		_ = s.httpServer.Close()
+	}
+	return err
 }
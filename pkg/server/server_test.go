@@ -68,6 +68,16 @@ func (self *ServerTests) TestListenReturnsError() {
 	self.EqualError(err, self.testError.Error())
 }
 
+func (self *ServerTests) TestListenIfServerClosed() {
+	self.httpServerMock.
+		On("ListenAndServe").
+		Return(http.ErrServerClosed)
+
+	err := self.server.Listen()
+
+	self.NoError(err)
+}
+
 func (self *ServerTests) TestShutdown() {
 	self.httpServerMock.
 		On("Shutdown", self.context).
@@ -87,3 +97,18 @@ func (self *ServerTests) TestShutdownReturnsError() {
 
 	self.EqualError(err, self.testError.Error())
 }
+
+func (self *ServerTests) TestShutdownClosesConnectionsIfContextDone() {
+	ctx, cancel := context.WithCancel(self.context)
+	cancel()
+	self.httpServerMock.
+		On("Shutdown", ctx).
+		Return(context.Canceled)
+	self.httpServerMock.
+		On("Close").
+		Return(nil)
+
+	err := self.server.Shutdown(ctx)
+
+	self.ErrorIs(err, context.Canceled)
+}
@@ -1,10 +1,78 @@
 package wait
 
-import "time"
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff returns the delay before the next attempt, attempt is zero after the first failure.
+type Backoff interface {
+	Delay(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same interval before every attempt.
+type ConstantBackoff time.Duration
+
+func (self ConstantBackoff) Delay(int) time.Duration {
+	return time.Duration(self)
+}
+
+// ExponentialBackoff multiplies the delay after every attempt up to the maximum.
+type ExponentialBackoff struct {
+	Initial time.Duration
+	// Max limits the delay, it is not limited if it is zero.
+	Max time.Duration
+	// Multiplier of the delay, it is 2 if it is zero.
+	Multiplier float64
+	// Jitter is the fraction of the delay randomly subtracted from it, so the clients retrying
+	// at the same time spread out.
+	Jitter float64
+
+	random func() float64
+}
+
+func (self ExponentialBackoff) Delay(attempt int) time.Duration {
+	multiplier := self.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	delay := float64(self.Initial) * math.Pow(multiplier, float64(attempt))
+	if self.Max > 0 && delay > float64(self.Max) {
+		delay = float64(self.Max)
+	}
+
+	if self.Jitter > 0 {
+		random := self.random
+		if random == nil {
+			random = rand.Float64
+		}
+		delay -= delay * math.Min(self.Jitter, 1) * random()
+	}
+
+	return time.Duration(delay)
+}
 
 func WaitUntil(task func() bool, deadline time.Time, interval time.Duration) bool {
-	for !task() && time.Now().Before(deadline) {
-		time.Sleep(interval)
+	return WaitUntilWithBackoff(task, deadline, ConstantBackoff(interval))
+}
+
+// WaitUntilWithBackoff runs the task until it succeeds or the deadline passes, the delays between
+// the attempts are returned by the backoff. The last attempt is made at the deadline.
+func WaitUntilWithBackoff(task func() bool, deadline time.Time, backoff Backoff) bool {
+	for attempt := 0; ; attempt++ {
+		if task() {
+			return true
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		delay := backoff.Delay(attempt)
+		if delay > remaining {
+			delay = remaining
+		}
+		time.Sleep(delay)
 	}
-	return task()
 }
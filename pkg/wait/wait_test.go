@@ -44,3 +44,59 @@ func verifyWaitTooksTime(t *testing.T, startTime time.Time, expectedWaitTime tim
 	require.True(t, spentTime > expectedWaitTime)
 	require.True(t, spentTime < 2*expectedWaitTime)
 }
+
+func TestWaitUntilWithBackoffUsesDelays(t *testing.T) {
+	testAttempts := 3
+	testBackoff := ExponentialBackoff{Initial: 20 * time.Millisecond, Multiplier: 3}
+	testOkAfterAttemptsFunction := func() bool {
+		testAttempts--
+		return testAttempts < 1
+	}
+	startTime := time.Now()
+	require.True(t, WaitUntilWithBackoff(testOkAfterAttemptsFunction, startTime.Add(time.Hour), testBackoff))
+	verifyWaitTooksTime(t, startTime, 80*time.Millisecond)
+}
+
+func TestWaitUntilWithBackoffMakesLastAttemptAtDeadline(t *testing.T) {
+	testCalls := 0
+	testNeverOkFunction := func() bool {
+		testCalls++
+		return false
+	}
+	testExpectedWaitTime := 50 * time.Millisecond
+	startTime := time.Now()
+	require.False(t, WaitUntilWithBackoff(testNeverOkFunction, startTime.Add(testExpectedWaitTime), ConstantBackoff(time.Hour)))
+	verifyWaitTooksTime(t, startTime, testExpectedWaitTime)
+	require.Equal(t, 2, testCalls)
+}
+
+func TestWaitUntilWithBackoffMakesSingleAttemptIfDeadlinePassed(t *testing.T) {
+	testCalls := 0
+	testNeverOkFunction := func() bool {
+		testCalls++
+		return false
+	}
+	require.False(t, WaitUntilWithBackoff(testNeverOkFunction, time.Now(), ConstantBackoff(time.Second)))
+	require.Equal(t, 1, testCalls)
+}
+
+func TestExponentialBackoffDelay(t *testing.T) {
+	testBackoff := ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second}
+	require.Equal(t, 100*time.Millisecond, testBackoff.Delay(0))
+	require.Equal(t, 200*time.Millisecond, testBackoff.Delay(1))
+	require.Equal(t, 800*time.Millisecond, testBackoff.Delay(3))
+	require.Equal(t, time.Second, testBackoff.Delay(4))
+	require.Equal(t, time.Second, testBackoff.Delay(100))
+}
+
+func TestExponentialBackoffDelayWithJitter(t *testing.T) {
+	testBackoff := ExponentialBackoff{Initial: 100 * time.Millisecond, Multiplier: 1.5, Jitter: 0.5}
+	testBackoff.random = func() float64 { return 0.5 }
+	require.Equal(t, 75*time.Millisecond, testBackoff.Delay(0))
+	require.Equal(t, 112500*time.Microsecond, testBackoff.Delay(1))
+
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := ExponentialBackoff{Initial: 100 * time.Millisecond, Jitter: 1}.Delay(attempt)
+		require.True(t, delay >= 0 && delay <= 100*time.Millisecond<<attempt)
+	}
+}